@@ -35,6 +35,20 @@ func (p Point) GoString() string {
 	return fmt.Sprintf("{X:%d, Y:%d}", p.X, p.Y)
 }
 
+// pointLess orders points by X, then Y, then TTL, then Value, for when a stable order is needed.
+func pointLess(a, b Point) bool {
+	if a.X != b.X {
+		return a.X < b.X
+	}
+	if a.Y != b.Y {
+		return a.Y < b.Y
+	}
+	if a.TTL != b.TTL {
+		return a.TTL < b.TTL
+	}
+	return a.Value < b.Value
+}
+
 type Snake struct {
 	ID               string
 	Body             []Point
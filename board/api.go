@@ -40,6 +40,13 @@ type GameFrame struct {
 	Snakes  []Snake       `json:"Snakes"`
 	Food    []rules.Point `json:"Food"`
 	Hazards []rules.Point `json:"Hazards"`
+	Zones   []Zone        `json:"Zones,omitempty"`
+}
+
+type Zone struct {
+	ID         int           `json:"ID"`
+	Cells      []rules.Point `json:"Cells"`
+	Controller string        `json:"Controller"`
 }
 
 type GameEnd struct {
@@ -63,6 +70,7 @@ type Snake struct {
 	Error         string        `json:"Error"`
 	IsBot         bool          `json:"IsBot"`
 	IsEnvironment bool          `json:"IsEnvironment"`
+	Score         int           `json:"Score,omitempty"`
 }
 
 type Death struct {
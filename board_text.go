@@ -0,0 +1,690 @@
+package rules
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The board text format is a compact, human readable description of a BoardState,
+// intended for writing test scenarios and for printing boards in logs and tools.
+//
+// A board is written as a grid with one character per square, with the top row
+// of the board (y = Height-1) first:
+//
+//	turn: 12
+//	snake a: id=one health=87
+//	snake b: id=two len=4
+//
+//	.......
+//	..Aaa..
+//	....a.*
+//	.%.....
+//	..#..bB
+//	.....b.
+//	..2....
+//
+// Grid characters:
+//
+//	.    empty square
+//	*    food
+//	#    hazard (walls are drawn as hazards, as in arcade_maze)
+//	2-9  a stack of that many hazards on the same square
+//	%    food on a single hazard
+//	a-z  body of the snake with that letter
+//	A-Z  head of the snake with that letter
+//
+// Snake bodies are traced from the head through adjacent body squares, so the
+// grid must not be ambiguous about the order of a body. Header lines of the form
+// "key: value" describe everything else:
+//
+//	size: WxH                   board size, only needed when the board has no squares
+//	turn: N                     board turn, defaults to 0
+//	snake a: id=ID health=N     snake attributes, also len=N to stack the tail (e.g. len=3 at the start of a game),
+//	                            and eliminated=CAUSE turn=N by=ID for eliminated snakes
+//	body a: X,Y X,Y ...         explicit body, overriding the grid for that snake
+//	food: X,Y ...               explicit food, overriding the grid
+//	hazards: X,Y ...            explicit hazards, overriding the grid
+//	state KEY: VALUE            an entry in GameState
+//	pointstate: X,Y=N ...       entries in PointState
+//
+// Points in explicit lists are written as X,Y or X,Y,TTL,VALUE. Values containing spaces
+// or quotes are written as Go quoted strings. Snakes are ordered by their letter, and food
+// and hazards from the grid are ordered top row first, left to right.
+//
+// FormatBoardText falls back to explicit header lines for anything the grid can't show
+// (such as a hazard under a snake, or food in a non-standard order), so that parsing
+// formatted text always reproduces the original BoardState exactly.
+
+// ParseBoardText parses a BoardState from the board text format.
+func ParseBoardText(text string) (*BoardState, error) {
+	state := NewBoardState(0, 0)
+
+	var rows [][]rune
+	var explicitFood, explicitHazards []Point
+	hasExplicitFood, hasExplicitHazards := false, false
+	explicitSize := false
+	snakeAttrs := map[rune]map[string]string{}
+	explicitBodies := map[rune][]Point{}
+
+	for lineNumber, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		lineErr := func(format string, args ...interface{}) error {
+			return fmt.Errorf("board text line %d: %s", lineNumber+1, fmt.Sprintf(format, args...))
+		}
+
+		key, value, isHeader := splitBoardTextHeader(line)
+		if !isHeader {
+			rows = append(rows, []rune(line))
+			continue
+		}
+
+		var err error
+		switch {
+		case key == "size":
+			_, err = fmt.Sscanf(value, "%dx%d", &state.Width, &state.Height)
+			explicitSize = true
+		case key == "turn":
+			state.Turn, err = strconv.Atoi(value)
+		case key == "food":
+			explicitFood, err = parseBoardTextPoints(value)
+			hasExplicitFood = true
+		case key == "hazards":
+			explicitHazards, err = parseBoardTextPoints(value)
+			hasExplicitHazards = true
+		case key == "pointstate":
+			err = parseBoardTextPointState(value, state.PointState)
+		case strings.HasPrefix(key, "snake "):
+			var letter rune
+			letter, err = parseBoardTextLetter(strings.TrimPrefix(key, "snake "))
+			if err == nil {
+				snakeAttrs[letter], err = parseBoardTextAttributes(value)
+			}
+		case strings.HasPrefix(key, "body "):
+			var letter rune
+			letter, err = parseBoardTextLetter(strings.TrimPrefix(key, "body "))
+			if err == nil {
+				explicitBodies[letter], err = parseBoardTextPoints(value)
+			}
+		case strings.HasPrefix(key, "state "):
+			var stateKey, stateValue string
+			stateKey, err = unquoteBoardTextValue(strings.TrimPrefix(key, "state "))
+			if err == nil {
+				stateValue, err = unquoteBoardTextValue(value)
+			}
+			state.GameState[stateKey] = stateValue
+		default:
+			err = fmt.Errorf("unknown header %q", key)
+		}
+		if err != nil {
+			return nil, lineErr("%v", err)
+		}
+	}
+
+	grid, err := newBoardTextGrid(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) > 0 {
+		if explicitSize && (state.Width != grid.width || state.Height != grid.height) {
+			return nil, fmt.Errorf("board text size %dx%d doesn't match the %dx%d grid", state.Width, state.Height, grid.width, grid.height)
+		}
+		state.Width, state.Height = grid.width, grid.height
+	}
+
+	food, hazards := grid.items()
+	if hasExplicitFood {
+		food = explicitFood
+	}
+	if hasExplicitHazards {
+		hazards = explicitHazards
+	}
+	state.Food = append(state.Food, food...)
+	state.Hazards = append(state.Hazards, hazards...)
+
+	// Snakes are ordered by letter, and can be declared by the grid or by header lines
+	letters := grid.snakeLetters()
+	for letter := range snakeAttrs {
+		letters[letter] = true
+	}
+	for letter := range explicitBodies {
+		letters[letter] = true
+	}
+	sortedLetters := make([]rune, 0, len(letters))
+	for letter := range letters {
+		sortedLetters = append(sortedLetters, letter)
+	}
+	sort.Slice(sortedLetters, func(i, j int) bool { return sortedLetters[i] < sortedLetters[j] })
+
+	for _, letter := range sortedLetters {
+		snake, err := buildBoardTextSnake(letter, snakeAttrs[letter], explicitBodies, grid)
+		if err != nil {
+			return nil, fmt.Errorf("board text snake %c: %w", letter, err)
+		}
+		state.Snakes = append(state.Snakes, snake)
+	}
+
+	return state, nil
+}
+
+// FormatBoardText formats a BoardState using the board text format.
+// An error is returned if the board has more snakes than can be represented by letters.
+func FormatBoardText(state *BoardState) (string, error) {
+	if len(state.Snakes) > 26 {
+		return "", fmt.Errorf("board text supports at most 26 snakes, got %d", len(state.Snakes))
+	}
+
+	grid := renderBoardTextGrid(state)
+
+	var header []string
+	if state.Width <= 0 || state.Height <= 0 {
+		header = append(header, fmt.Sprintf("size: %dx%d", state.Width, state.Height))
+	}
+	if state.Turn != 0 {
+		header = append(header, fmt.Sprintf("turn: %d", state.Turn))
+	}
+
+	var bodies []string
+	for i, snake := range state.Snakes {
+		letter := rune('a' + i)
+		attrs := []string{"id=" + quoteBoardTextValue(snake.ID)}
+		if snake.Health != SnakeMaxHealth {
+			attrs = append(attrs, fmt.Sprintf("health=%d", snake.Health))
+		}
+
+		traced, err := grid.traceBody(letter)
+		if err == nil && isBodyWithStackedTail(snake.Body, traced) {
+			if len(snake.Body) > len(traced) {
+				attrs = append(attrs, fmt.Sprintf("len=%d", len(snake.Body)))
+			}
+		} else {
+			bodies = append(bodies, fmt.Sprintf("body %c: %s", letter, formatBoardTextPoints(snake.Body)))
+		}
+
+		if snake.EliminatedCause != NotEliminated {
+			attrs = append(attrs, "eliminated="+quoteBoardTextValue(snake.EliminatedCause))
+		}
+		if snake.EliminatedOnTurn != 0 {
+			attrs = append(attrs, fmt.Sprintf("turn=%d", snake.EliminatedOnTurn))
+		}
+		if snake.EliminatedBy != "" {
+			attrs = append(attrs, "by="+quoteBoardTextValue(snake.EliminatedBy))
+		}
+		header = append(header, fmt.Sprintf("snake %c: %s", letter, strings.Join(attrs, " ")))
+	}
+	header = append(header, bodies...)
+
+	gridFood, gridHazards := grid.items()
+	if !pointsEqual(gridFood, state.Food) {
+		header = append(header, "food: "+formatBoardTextPoints(state.Food))
+	}
+	if !pointsEqual(gridHazards, state.Hazards) {
+		header = append(header, "hazards: "+formatBoardTextPoints(state.Hazards))
+	}
+
+	stateKeys := make([]string, 0, len(state.GameState))
+	for key := range state.GameState {
+		stateKeys = append(stateKeys, key)
+	}
+	sort.Strings(stateKeys)
+	for _, key := range stateKeys {
+		header = append(header, fmt.Sprintf("state %s: %s", quoteBoardTextValue(key), quoteBoardTextValue(state.GameState[key])))
+	}
+
+	if len(state.PointState) > 0 {
+		points := make([]Point, 0, len(state.PointState))
+		for p := range state.PointState {
+			points = append(points, p)
+		}
+		sortBoardTextPoints(points)
+		entries := make([]string, 0, len(points))
+		for _, p := range points {
+			entries = append(entries, fmt.Sprintf("%s=%d", formatBoardTextPoint(p), state.PointState[p]))
+		}
+		header = append(header, "pointstate: "+strings.Join(entries, " "))
+	}
+
+	var sb strings.Builder
+	for _, line := range header {
+		sb.WriteString(line)
+		sb.WriteString("\n")
+	}
+	if len(header) > 0 && grid.height > 0 {
+		sb.WriteString("\n")
+	}
+	for _, row := range grid.rows {
+		sb.WriteString(string(row))
+		sb.WriteString("\n")
+	}
+	return sb.String(), nil
+}
+
+// boardTextGrid holds the grid part of the board text format.
+// Rows are stored in reading order, so rows[0] is the top row of the board.
+type boardTextGrid struct {
+	width, height int
+	rows          [][]rune
+}
+
+func newBoardTextGrid(rows [][]rune) (*boardTextGrid, error) {
+	grid := &boardTextGrid{height: len(rows), rows: rows}
+	for i, row := range rows {
+		if i == 0 {
+			grid.width = len(row)
+		} else if len(row) != grid.width {
+			return nil, fmt.Errorf("board text grid row %d has %d squares, expected %d", i+1, len(row), grid.width)
+		}
+		for _, c := range row {
+			if !isBoardTextGridChar(c) {
+				return nil, fmt.Errorf("board text grid row %d has invalid character %q", i+1, c)
+			}
+		}
+	}
+	return grid, nil
+}
+
+func renderBoardTextGrid(state *BoardState) *boardTextGrid {
+	grid := &boardTextGrid{width: state.Width, height: state.Height}
+	if state.Width <= 0 || state.Height <= 0 {
+		grid.height = 0
+		return grid
+	}
+
+	food := map[Point]int{}
+	for _, p := range state.Food {
+		if state.isOnBoard(p) {
+			food[Point{X: p.X, Y: p.Y}]++
+		}
+	}
+	hazards := map[Point]int{}
+	for _, p := range state.Hazards {
+		if state.isOnBoard(p) {
+			hazards[Point{X: p.X, Y: p.Y}]++
+		}
+	}
+
+	grid.rows = make([][]rune, state.Height)
+	for row := range grid.rows {
+		grid.rows[row] = make([]rune, state.Width)
+		y := state.Height - 1 - row
+		for x := 0; x < state.Width; x++ {
+			p := Point{X: x, Y: y}
+			switch {
+			case food[p] > 0 && hazards[p] == 1:
+				grid.rows[row][x] = '%'
+			case food[p] > 0:
+				grid.rows[row][x] = '*'
+			case hazards[p] == 1:
+				grid.rows[row][x] = '#'
+			case hazards[p] > 1:
+				grid.rows[row][x] = rune('0' + minInt(hazards[p], 9))
+			default:
+				grid.rows[row][x] = '.'
+			}
+		}
+	}
+
+	for i, snake := range state.Snakes {
+		if snake.EliminatedCause != NotEliminated {
+			continue
+		}
+		letter := rune('a' + i)
+		// Draw from the tail so the head is always visible
+		for j := len(snake.Body) - 1; j >= 0; j-- {
+			p := snake.Body[j]
+			if !state.isOnBoard(p) {
+				continue
+			}
+			c := letter
+			if j == 0 {
+				c = letter - 'a' + 'A'
+			}
+			grid.rows[state.Height-1-p.Y][p.X] = c
+		}
+	}
+
+	return grid
+}
+
+// items returns the food and hazards drawn on the grid, in reading order.
+func (grid *boardTextGrid) items() (food []Point, hazards []Point) {
+	food, hazards = []Point{}, []Point{}
+	for row := 0; row < grid.height; row++ {
+		y := grid.height - 1 - row
+		for x := 0; x < grid.width; x++ {
+			p := Point{X: x, Y: y}
+			switch c := grid.rows[row][x]; {
+			case c == '*':
+				food = append(food, p)
+			case c == '#':
+				hazards = append(hazards, p)
+			case c == '%':
+				food = append(food, p)
+				hazards = append(hazards, p)
+			case c >= '2' && c <= '9':
+				for i := 0; i < int(c-'0'); i++ {
+					hazards = append(hazards, p)
+				}
+			}
+		}
+	}
+	return food, hazards
+}
+
+// snakeLetters returns the set of (lowercase) snake letters drawn on the grid.
+func (grid *boardTextGrid) snakeLetters() map[rune]bool {
+	letters := map[rune]bool{}
+	for _, row := range grid.rows {
+		for _, c := range row {
+			if c >= 'A' && c <= 'Z' {
+				letters[c-'A'+'a'] = true
+			} else if c >= 'a' && c <= 'z' {
+				letters[c] = true
+			}
+		}
+	}
+	return letters
+}
+
+// traceBody follows the body of a snake on the grid, starting from its head.
+func (grid *boardTextGrid) traceBody(letter rune) ([]Point, error) {
+	head := letter - 'a' + 'A'
+	var heads []Point
+	remaining := map[Point]bool{}
+	for row := 0; row < grid.height; row++ {
+		for x := 0; x < grid.width; x++ {
+			p := Point{X: x, Y: grid.height - 1 - row}
+			switch grid.rows[row][x] {
+			case head:
+				heads = append(heads, p)
+			case letter:
+				remaining[p] = true
+			}
+		}
+	}
+
+	if len(heads) == 0 {
+		if len(remaining) > 0 {
+			return nil, fmt.Errorf("body is drawn without a head")
+		}
+		return []Point{}, nil
+	}
+	if len(heads) > 1 {
+		return nil, fmt.Errorf("head is drawn %d times", len(heads))
+	}
+
+	body := []Point{heads[0]}
+	for len(remaining) > 0 {
+		current := body[len(body)-1]
+		var next []Point
+		for _, n := range []Point{
+			{X: current.X, Y: current.Y + 1},
+			{X: current.X + 1, Y: current.Y},
+			{X: current.X, Y: current.Y - 1},
+			{X: current.X - 1, Y: current.Y},
+		} {
+			if remaining[n] {
+				next = append(next, n)
+			}
+		}
+		if len(next) == 0 {
+			return nil, fmt.Errorf("body is not connected to its head")
+		}
+		if len(next) > 1 {
+			return nil, fmt.Errorf("body is ambiguous after %#v, use a body line", current)
+		}
+		body = append(body, next[0])
+		delete(remaining, next[0])
+	}
+	return body, nil
+}
+
+func buildBoardTextSnake(letter rune, attrs map[string]string, explicitBodies map[rune][]Point, grid *boardTextGrid) (Snake, error) {
+	snake := Snake{ID: string(letter), Health: SnakeMaxHealth}
+
+	length := 0
+	for key, value := range attrs {
+		var err error
+		switch key {
+		case "id":
+			snake.ID = value
+		case "health":
+			snake.Health, err = strconv.Atoi(value)
+		case "len":
+			length, err = strconv.Atoi(value)
+		case "eliminated":
+			snake.EliminatedCause = value
+		case "turn":
+			snake.EliminatedOnTurn, err = strconv.Atoi(value)
+		case "by":
+			snake.EliminatedBy = value
+		default:
+			err = fmt.Errorf("unknown attribute %q", key)
+		}
+		if err != nil {
+			return Snake{}, err
+		}
+	}
+
+	if body, ok := explicitBodies[letter]; ok {
+		snake.Body = body
+		if length != 0 && length != len(body) {
+			return Snake{}, fmt.Errorf("len=%d doesn't match body line with %d points", length, len(body))
+		}
+		return snake, nil
+	}
+
+	body, err := grid.traceBody(letter)
+	if err != nil {
+		return Snake{}, err
+	}
+	if length != 0 {
+		if length < len(body) || len(body) == 0 {
+			return Snake{}, fmt.Errorf("len=%d can't be used for a drawn body of %d squares", length, len(body))
+		}
+		for len(body) < length {
+			body = append(body, body[len(body)-1])
+		}
+	}
+	snake.Body = body
+	return snake, nil
+}
+
+// isBodyWithStackedTail reports whether body is the traced body, optionally with its tail repeated.
+func isBodyWithStackedTail(body, traced []Point) bool {
+	if len(body) < len(traced) || (len(traced) == 0 && len(body) > 0) {
+		return false
+	}
+	for i, p := range body {
+		expected := traced[minInt(i, len(traced)-1)]
+		if p != expected {
+			return false
+		}
+	}
+	return true
+}
+
+func isBoardTextGridChar(c rune) bool {
+	switch {
+	case c == '.', c == '*', c == '#', c == '%':
+		return true
+	case c >= '2' && c <= '9', c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		return true
+	}
+	return false
+}
+
+// splitBoardTextHeader splits a "key: value" header line, taking quoted keys into account.
+func splitBoardTextHeader(line string) (string, string, bool) {
+	inQuotes := false
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			if inQuotes {
+				i++
+			}
+		case '"':
+			inQuotes = !inQuotes
+		case ':':
+			if !inQuotes {
+				return strings.TrimSpace(line[:i]), strings.TrimSpace(line[i+1:]), true
+			}
+		}
+	}
+	return "", "", false
+}
+
+func parseBoardTextLetter(s string) (rune, error) {
+	if len(s) != 1 || s[0] < 'a' || s[0] > 'z' {
+		return 0, fmt.Errorf("invalid snake letter %q", s)
+	}
+	return rune(s[0]), nil
+}
+
+// parseBoardTextAttributes parses space separated key=value pairs, where values may be quoted.
+func parseBoardTextAttributes(s string) (map[string]string, error) {
+	attrs := map[string]string{}
+	for len(s) > 0 {
+		s = strings.TrimLeft(s, " ")
+		if s == "" {
+			break
+		}
+		eq := strings.IndexByte(s, '=')
+		if eq <= 0 {
+			return nil, fmt.Errorf("invalid attribute %q", s)
+		}
+		key := s[:eq]
+		s = s[eq+1:]
+
+		var raw string
+		if strings.HasPrefix(s, `"`) {
+			quoted, err := strconv.QuotedPrefix(s)
+			if err != nil {
+				return nil, fmt.Errorf("invalid quoted value for %q: %w", key, err)
+			}
+			raw = quoted
+		} else if space := strings.IndexByte(s, ' '); space >= 0 {
+			raw = s[:space]
+		} else {
+			raw = s
+		}
+		s = s[len(raw):]
+
+		value, err := unquoteBoardTextValue(raw)
+		if err != nil {
+			return nil, err
+		}
+		attrs[key] = value
+	}
+	return attrs, nil
+}
+
+func parseBoardTextPoints(s string) ([]Point, error) {
+	points := []Point{}
+	for _, field := range strings.Fields(s) {
+		p, err := parseBoardTextPoint(field)
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, p)
+	}
+	return points, nil
+}
+
+func parseBoardTextPoint(s string) (Point, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 2 && len(parts) != 4 {
+		return Point{}, fmt.Errorf("invalid point %q", s)
+	}
+	values := make([]int, len(parts))
+	for i, part := range parts {
+		v, err := strconv.Atoi(part)
+		if err != nil {
+			return Point{}, fmt.Errorf("invalid point %q", s)
+		}
+		values[i] = v
+	}
+	p := Point{X: values[0], Y: values[1]}
+	if len(values) == 4 {
+		p.TTL, p.Value = values[2], values[3]
+	}
+	return p, nil
+}
+
+func parseBoardTextPointState(s string, pointState map[Point]int) error {
+	for _, field := range strings.Fields(s) {
+		eq := strings.IndexByte(field, '=')
+		if eq < 0 {
+			return fmt.Errorf("invalid point state %q", field)
+		}
+		p, err := parseBoardTextPoint(field[:eq])
+		if err != nil {
+			return err
+		}
+		value, err := strconv.Atoi(field[eq+1:])
+		if err != nil {
+			return fmt.Errorf("invalid point state %q", field)
+		}
+		pointState[p] = value
+	}
+	return nil
+}
+
+func formatBoardTextPoint(p Point) string {
+	if p.TTL != 0 || p.Value != 0 {
+		return fmt.Sprintf("%d,%d,%d,%d", p.X, p.Y, p.TTL, p.Value)
+	}
+	return fmt.Sprintf("%d,%d", p.X, p.Y)
+}
+
+func formatBoardTextPoints(points []Point) string {
+	formatted := make([]string, 0, len(points))
+	for _, p := range points {
+		formatted = append(formatted, formatBoardTextPoint(p))
+	}
+	return strings.Join(formatted, " ")
+}
+
+// quoteBoardTextValue quotes a value if it can't be written as a bare word.
+func quoteBoardTextValue(s string) string {
+	if s == "" || strings.ContainsAny(s, " \t\r\n\"=:\\") || strconv.Quote(s) != `"`+s+`"` {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+func unquoteBoardTextValue(s string) (string, error) {
+	if strings.HasPrefix(s, `"`) {
+		return strconv.Unquote(s)
+	}
+	return s, nil
+}
+
+func pointsEqual(a, b []Point) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func sortBoardTextPoints(points []Point) {
+	sort.Slice(points, func(i, j int) bool {
+		return pointLess(points[i], points[j])
+	})
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,191 @@
+package rules
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBoardText(t *testing.T) {
+	state, err := ParseBoardText(`
+		turn: 12
+		snake a: id=one health=87
+		snake b: id=two len=4
+		state level: 3
+
+		.......
+		..Aaa..
+		....a.*
+		.%.....
+		..#..bB
+		.....b.
+		..2....
+	`)
+	require.NoError(t, err)
+
+	expected := NewBoardState(7, 7).
+		WithTurn(12).
+		WithFood([]Point{{X: 6, Y: 4}, {X: 1, Y: 3}}).
+		WithHazards([]Point{{X: 1, Y: 3}, {X: 2, Y: 2}, {X: 2, Y: 0}, {X: 2, Y: 0}}).
+		WithSnakes([]Snake{
+			{ID: "one", Health: 87, Body: []Point{{X: 2, Y: 5}, {X: 3, Y: 5}, {X: 4, Y: 5}, {X: 4, Y: 4}}},
+			{ID: "two", Health: 100, Body: []Point{{X: 6, Y: 2}, {X: 5, Y: 2}, {X: 5, Y: 1}, {X: 5, Y: 1}}},
+		}).
+		WithGameState(map[string]string{"level": "3"})
+	require.Equal(t, expected, state)
+}
+
+func TestParseBoardTextErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		err  string
+	}{
+		{"uneven rows", "...\n..", "row 2 has 2 squares, expected 3"},
+		{"invalid character", "..?", `invalid character '?'`},
+		{"unknown header", "colour: red\n...", `unknown header "colour"`},
+		{"invalid point", "food: 1;2\n...", `invalid point "1;2"`},
+		{"size mismatch", "size: 4x4\n...", "doesn't match the 3x1 grid"},
+		{"body without head", "aa.", "body is drawn without a head"},
+		{"two heads", "AaA", "head is drawn 2 times"},
+		{"disconnected body", "Aa.a", "body is not connected to its head"},
+		{"ambiguous body", "aAa", "body is ambiguous"},
+		{"len shorter than body", "snake a: len=1\nAa.", "len=1 can't be used"},
+		{"unknown snake attribute", "snake a: colour=red\nA..", `unknown attribute "colour"`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParseBoardText(test.text)
+			require.ErrorContains(t, err, test.err)
+		})
+	}
+}
+
+func TestFormatBoardText(t *testing.T) {
+	state := NewBoardState(5, 4).
+		WithTurn(3).
+		WithFood([]Point{{X: 4, Y: 3}, {X: 0, Y: 0}}).
+		WithHazards([]Point{{X: 0, Y: 0}, {X: 2, Y: 0}, {X: 2, Y: 0}, {X: 2, Y: 0}}).
+		WithSnakes([]Snake{
+			{ID: "one", Health: 100, Body: []Point{{X: 1, Y: 2}, {X: 1, Y: 1}, {X: 1, Y: 1}}},
+			{ID: "two", Health: 50, Body: []Point{{X: 3, Y: 1}, {X: 3, Y: 2}, {X: 3, Y: 3}}},
+		})
+
+	text, err := FormatBoardText(state)
+	require.NoError(t, err)
+	require.Equal(t, `turn: 3
+snake a: id=one len=3
+snake b: id=two health=50
+
+...b*
+.A.b.
+.a.B.
+%.3..
+`, text)
+}
+
+func TestFormatBoardTextTooManySnakes(t *testing.T) {
+	state := NewBoardState(11, 11)
+	for i := 0; i < 27; i++ {
+		state.Snakes = append(state.Snakes, Snake{ID: fmt.Sprint(i), Body: []Point{}})
+	}
+	_, err := FormatBoardText(state)
+	require.Error(t, err)
+}
+
+func TestBoardTextRoundTrip(t *testing.T) {
+	defaultState, err := CreateDefaultBoardState(MaxRand, BoardSizeMedium, BoardSizeMedium, []string{"1", "2", "3", "4", "5", "6", "7", "8"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		state *BoardState
+	}{
+		{"empty", NewBoardState(0, 0)},
+		{"no height", NewBoardState(7, 0)},
+		{"empty 7x7", NewBoardState(7, 7)},
+		{"default 11x11", defaultState},
+		{
+			"items that can't be drawn on the grid",
+			NewBoardState(5, 5).
+				WithFood([]Point{{X: 2, Y: 2}, {X: 0, Y: 0}, {X: 0, Y: 0}, {X: 3, Y: 3, TTL: 4, Value: 2}}).
+				// stacked beyond 9, off the board, and under a snake
+				WithHazards([]Point{{X: 1, Y: 1}, {X: 1, Y: 1}, {X: 1, Y: 1}, {X: 1, Y: 1}, {X: 1, Y: 1}, {X: 1, Y: 1}, {X: 1, Y: 1}, {X: 1, Y: 1}, {X: 1, Y: 1}, {X: 1, Y: 1}, {X: 2, Y: 7}, {X: 4, Y: 4}}).
+				WithSnakes([]Snake{
+					{ID: "a", Health: 100, Body: []Point{{X: 4, Y: 4}, {X: 4, Y: 3}}},
+				}),
+		},
+		{
+			"overlapping and eliminated snakes",
+			NewBoardState(5, 5).
+				WithTurn(8).
+				WithSnakes([]Snake{
+					{ID: "head to head", Health: 90, Body: []Point{{X: 2, Y: 2}, {X: 1, Y: 2}, {X: 0, Y: 2}}},
+					{ID: "winner", Health: 90, Body: []Point{{X: 2, Y: 2}, {X: 3, Y: 2}, {X: 4, Y: 2}, {X: 4, Y: 1}}},
+					{ID: "coiled", Health: 12, Body: []Point{{X: 1, Y: 4}, {X: 1, Y: 3}, {X: 2, Y: 3}, {X: 2, Y: 4}, {X: 3, Y: 4}}},
+					{ID: "gone", Health: 0, Body: []Point{{X: -1, Y: 0}, {X: 0, Y: 0}}, EliminatedCause: EliminatedByOutOfBounds, EliminatedOnTurn: 3},
+					{ID: "bitten", Health: 40, Body: []Point{{X: 0, Y: 1}}, EliminatedCause: EliminatedByCollision, EliminatedOnTurn: 8, EliminatedBy: "winner"},
+					{ID: "nobody", Health: 100, Body: []Point{}},
+				}),
+		},
+		{
+			"game and point state",
+			NewBoardState(3, 3).
+				WithGameState(map[string]string{
+					"simple":       "value",
+					"with spaces":  " leading and trailing ",
+					"quote\"colon": "a:b=c",
+					"empty":        "",
+				}).
+				WithPointState(map[Point]int{{X: 1, Y: 1}: 5, {X: 0, Y: 2, TTL: 1, Value: 2}: -3}),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			text, err := FormatBoardText(test.state)
+			require.NoError(t, err)
+
+			parsed, err := ParseBoardText(text)
+			require.NoError(t, err, text)
+			require.Equal(t, test.state, parsed, text)
+
+			reformatted, err := FormatBoardText(parsed)
+			require.NoError(t, err)
+			require.Equal(t, text, reformatted)
+		})
+	}
+}
+
+func TestBoardTextScenario(t *testing.T) {
+	// Board text makes it easy to write readable rule scenarios
+	prevState, err := ParseBoardText(`
+		snake a: id=one health=50
+		snake b: id=two
+		.....
+		.Aa*.
+		..aB.
+		...b.
+		...b.
+	`)
+	require.NoError(t, err)
+
+	r := NewRulesetBuilder().NamedRuleset(GameTypeStandard)
+	_, nextState, err := r.Execute(prevState, []SnakeMove{{ID: "one", Move: MoveUp}, {ID: "two", Move: MoveUp}})
+	require.NoError(t, err)
+
+	expectedState, err := ParseBoardText(`
+		snake a: id=one health=49
+		snake b: id=two len=4
+		.A...
+		.aaB.
+		...b.
+		...b.
+		.....
+	`)
+	require.NoError(t, err)
+	require.Equal(t, expectedState.Snakes, nextState.Snakes)
+	require.Empty(t, nextState.Food)
+}
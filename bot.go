@@ -0,0 +1,50 @@
+package rules
+
+import "strconv"
+
+// botKeyPrefix is the GameState key prefix used to mark snakes as bots.
+const botKeyPrefix = "bot."
+
+// Bot describes a snake that is controlled by the game environment instead of a snake server,
+// such as an NPC snake added by a map.
+type Bot struct {
+	// Name of the movement policy that chooses the bot's moves.
+	Policy string
+	// Display name of the bot. The snake ID is used if empty.
+	Name string
+	// Bots excluded from win conditions don't keep a game going, and can't win it.
+	ExcludeFromWin bool
+}
+
+// SetBot marks a snake as a bot. The bot is stored in the board's GameState, so that it
+// persists between turns and is visible to everything that sees the board.
+func SetBot(b *BoardState, snakeID string, bot Bot) {
+	if b.GameState == nil {
+		b.GameState = map[string]string{}
+	}
+	prefix := botKeyPrefix + snakeID + "."
+	b.GameState[prefix+"policy"] = bot.Policy
+	b.GameState[prefix+"name"] = bot.Name
+	b.GameState[prefix+"excludeFromWin"] = strconv.FormatBool(bot.ExcludeFromWin)
+}
+
+// GetBot returns the bot controlling a snake, and false if the snake isn't a bot.
+func GetBot(b *BoardState, snakeID string) (Bot, bool) {
+	prefix := botKeyPrefix + snakeID + "."
+	policy, ok := b.GameState[prefix+"policy"]
+	if !ok {
+		return Bot{}, false
+	}
+	excludeFromWin, _ := strconv.ParseBool(b.GameState[prefix+"excludeFromWin"])
+	return Bot{
+		Policy:         policy,
+		Name:           b.GameState[prefix+"name"],
+		ExcludeFromWin: excludeFromWin,
+	}, true
+}
+
+// countsForWin returns true if a snake takes part in win conditions, which is every snake except excluded bots.
+func countsForWin(b *BoardState, snake Snake) bool {
+	bot, ok := GetBot(b, snake.ID)
+	return !ok || !bot.ExcludeFromWin
+}
@@ -0,0 +1,54 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetBot(t *testing.T) {
+	b := &BoardState{}
+	_, ok := GetBot(b, "npc")
+	require.False(t, ok)
+
+	SetBot(b, "npc", Bot{Policy: "patrol", Name: "Guard", ExcludeFromWin: true})
+	bot, ok := GetBot(b, "npc")
+	require.True(t, ok)
+	require.Equal(t, Bot{Policy: "patrol", Name: "Guard", ExcludeFromWin: true}, bot)
+
+	// Bots are kept when the board is cloned
+	bot, ok = GetBot(b.Clone(), "npc")
+	require.True(t, ok)
+	require.Equal(t, "Guard", bot.Name)
+
+	_, ok = GetBot(b, "player")
+	require.False(t, ok)
+}
+
+func TestGameOverExcludesBots(t *testing.T) {
+	b := NewBoardState(11, 11).WithSnakes([]Snake{{ID: "player"}, {ID: "npc"}, {ID: "rival"}})
+	SetBot(b, "npc", Bot{Policy: "patrol", ExcludeFromWin: true})
+
+	gameOver, err := GameOverStandard(b, Settings{}, nil)
+	require.NoError(t, err)
+	require.False(t, gameOver)
+
+	// A single player and an excluded bot ends a standard game
+	b.Snakes[2].EliminatedCause = EliminatedByCollision
+	gameOver, err = GameOverStandard(b, Settings{}, nil)
+	require.NoError(t, err)
+	require.True(t, gameOver)
+
+	// Bots that aren't excluded take part like any other snake
+	SetBot(b, "npc", Bot{Policy: "patrol"})
+	gameOver, err = GameOverStandard(b, Settings{}, nil)
+	require.NoError(t, err)
+	require.False(t, gameOver)
+
+	// A solo game ends when only excluded bots are left
+	SetBot(b, "npc", Bot{Policy: "patrol", ExcludeFromWin: true})
+	b.Snakes[0].EliminatedCause = EliminatedByCollision
+	gameOver, err = GameOverSolo(b, Settings{}, nil)
+	require.NoError(t, err)
+	require.True(t, gameOver)
+}
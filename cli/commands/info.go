@@ -2,6 +2,7 @@ package commands
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/spf13/cobra"
 	log "github.com/spf13/jwalterweatherman"
@@ -11,6 +12,7 @@ import (
 
 type mapInfo struct {
 	All bool
+	mapQueryFlags
 }
 
 func NewMapInfoCommand() *cobra.Command {
@@ -22,7 +24,11 @@ func NewMapInfoCommand() *cobra.Command {
 		Run: func(cmd *cobra.Command, args []string) {
 			// handle --all flag first as there would be no args
 			if info.All {
-				mapList := maps.List()
+				query, err := info.query()
+				if err != nil {
+					log.ERROR.Fatal(err)
+				}
+				mapList := maps.Query(query)
 				for i, m := range mapList {
 					info.display(m)
 					if i < (len(mapList) - 1) {
@@ -52,7 +58,8 @@ func NewMapInfoCommand() *cobra.Command {
 		},
 	}
 
-	infoCmd.Flags().BoolVarP(&info.All, "all", "a", false, "Display information for all maps")
+	infoCmd.Flags().BoolVarP(&info.All, "all", "a", false, "Display information for all maps, or with the flags below, all matching maps")
+	info.mapQueryFlags.register(infoCmd)
 
 	return infoCmd
 }
@@ -67,6 +74,9 @@ func (m *mapInfo) display(id string) {
 	fmt.Println("Author:", meta.Author)
 	fmt.Println("Description:", meta.Description)
 	fmt.Println("Version:", meta.Version)
+	if versions := maps.Versions(gameMap.ID()); len(versions) > 1 {
+		fmt.Println("Available Versions:", strings.Trim(fmt.Sprint(versions), "[]"))
+	}
 	fmt.Println("Min Players:", meta.MinPlayers)
 	fmt.Println("Max Players:", meta.MaxPlayers)
 	fmt.Print("Board Sizes (WxH):")
@@ -86,4 +96,17 @@ func (m *mapInfo) display(id string) {
 			fmt.Print("\n")
 		}
 	}
+	fmt.Println("Parameters:")
+	for _, p := range meta.Parameters {
+		fmt.Println(" ", formatMapParameter(p))
+	}
+}
+
+// formatMapParameter formats a map parameter on one line, e.g. "shrinkEveryNTurns (int, default 10): ...".
+func formatMapParameter(p maps.Parameter) string {
+	defaultValue := "required"
+	if p.Default != "" {
+		defaultValue = "default " + p.Default
+	}
+	return fmt.Sprintf("%s (%s, %s): %s", p.Name, p.Type, defaultValue, p.Description)
 }
@@ -5,18 +5,62 @@ import (
 
 	"github.com/Pikle2/rules/maps"
 	"github.com/spf13/cobra"
+	log "github.com/spf13/jwalterweatherman"
 )
 
+// mapQueryFlags are the flags used to select maps by their metadata.
+type mapQueryFlags struct {
+	Tags                []string
+	Players             int
+	Size                string
+	IncludeExperimental bool
+}
+
+func (flags *mapQueryFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVar(&flags.Tags, "tag", nil, "Only include maps with this tag. Can be repeated to require several tags")
+	cmd.Flags().IntVar(&flags.Players, "players", 0, "Only include maps that can be played by this number of snakes")
+	cmd.Flags().StringVar(&flags.Size, "size", "", "Only include maps that can be played on this board size, e.g. 19x19")
+	cmd.Flags().BoolVar(&flags.IncludeExperimental, "include-experimental", false, "Include experimental maps when filtering by tag, players or size")
+}
+
+// query returns the map query for the flags. Every map, including experimental maps, is included without any filters.
+func (flags *mapQueryFlags) query() (maps.MapQuery, error) {
+	filtered := len(flags.Tags) > 0 || flags.Players != 0 || flags.Size != ""
+	query := maps.MapQuery{
+		Tags:                flags.Tags,
+		Players:             flags.Players,
+		IncludeExperimental: flags.IncludeExperimental || !filtered,
+	}
+	if flags.Players < 0 {
+		return query, fmt.Errorf("invalid number of players %d", flags.Players)
+	}
+	if flags.Size != "" {
+		size, err := maps.ParseDimensions(flags.Size)
+		if err != nil {
+			return query, err
+		}
+		query.Width, query.Height = size.Width, size.Height
+	}
+	return query, nil
+}
+
 func NewMapListCommand() *cobra.Command {
+	flags := mapQueryFlags{}
 	var listCmd = &cobra.Command{
 		Use:   "list",
 		Short: "List available game maps",
-		Long:  "List available game maps",
+		Long: `List available game maps, optionally only those with the given tags, or that can be played
+by a number of snakes on a board size. When filtering, experimental maps are only listed with --include-experimental.`,
 		Run: func(cmd *cobra.Command, args []string) {
-			for _, m := range maps.List() {
+			query, err := flags.query()
+			if err != nil {
+				log.ERROR.Fatal(err)
+			}
+			for _, m := range maps.Query(query) {
 				fmt.Println(m)
 			}
 		},
 	}
+	flags.register(listCmd)
 	return listCmd
 }
@@ -0,0 +1,47 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/Pikle2/rules"
+	"github.com/Pikle2/rules/maps"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapQueryFlags(t *testing.T) {
+	cmd := &cobra.Command{}
+	flags := mapQueryFlags{}
+	flags.register(cmd)
+	require.NoError(t, cmd.Flags().Parse([]string{"--tag", "hazard-placement", "--tag", "food-placement", "--players", "4", "--size", "19x19", "--include-experimental"}))
+	query, err := flags.query()
+	require.NoError(t, err)
+	require.Equal(t, maps.MapQuery{
+		Tags:                []string{maps.TAG_HAZARD_PLACEMENT, maps.TAG_FOOD_PLACEMENT},
+		Players:             4,
+		Width:               19,
+		Height:              19,
+		IncludeExperimental: true,
+	}, query)
+
+	// Every map found by a query can be set up for that game
+	for _, id := range maps.Query(query) {
+		_, err := maps.SetupBoard(id, rules.NewSettingsWithParams(rules.ParamHazardDamagePerTurn, "14"), 19, 19, []string{"1", "2", "3", "4"})
+		require.NoError(t, err, id)
+	}
+
+	// Experimental maps are listed when there are no filters, and only when asked for with filters
+	query, err = (&mapQueryFlags{}).query()
+	require.NoError(t, err)
+	require.True(t, query.IncludeExperimental)
+	require.Contains(t, maps.Query(query), "snail_mode")
+	query, err = (&mapQueryFlags{Players: 2}).query()
+	require.NoError(t, err)
+	require.False(t, query.IncludeExperimental)
+	require.NotContains(t, maps.Query(query), "snail_mode")
+
+	_, err = (&mapQueryFlags{Size: "19"}).query()
+	require.EqualError(t, err, `invalid board size "19", expected WIDTHxHEIGHT`)
+	_, err = (&mapQueryFlags{Players: -1}).query()
+	require.EqualError(t, err, "invalid number of players -1")
+}
@@ -1,6 +1,10 @@
 package commands
 
 import (
+	"fmt"
+	"strings"
+
+	"github.com/Pikle2/rules/maps"
 	"github.com/spf13/cobra"
 	log "github.com/spf13/jwalterweatherman"
 )
@@ -21,3 +25,43 @@ func NewMapCommand() *cobra.Command {
 
 	return mapCmd
 }
+
+// loadGameMap returns the map to use for a game, from a map plugin command or map file if
+// one is given, or otherwise the registered map with the given ID.
+// Map plugins need to be closed once the game is over.
+func loadGameMap(mapName, mapFile, mapPlugin string) (maps.GameMap, error) {
+	if command := strings.Fields(mapPlugin); len(command) > 0 {
+		gameMap, err := maps.NewPluginMap(command[0], command[1:]...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to start game map plugin: %w", err)
+		}
+		return gameMap, nil
+	}
+
+	if mapFile != "" {
+		gameMap, err := maps.LoadMapFile(mapFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load game map file: %w", err)
+		}
+		return gameMap, nil
+	}
+
+	gameMap, err := maps.GetMap(mapName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load game map %#v: %v", mapName, err)
+	}
+	return gameMap, nil
+}
+
+// parseMapParams parses --map-param values in the form key=value.
+func parseMapParams(values []string) (map[string]string, error) {
+	params := make(map[string]string, len(values))
+	for _, value := range values {
+		key, val, ok := strings.Cut(value, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid map parameter %q, expected key=value", value)
+		}
+		params[key] = val
+	}
+	return params, nil
+}
@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"bufio"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -66,3 +67,20 @@ func (ge *GameExporter) ConvertToJSON() ([]string, error) {
 func (ge *GameExporter) AddSnakeRequest(snakeRequest client.SnakeRequest) {
 	ge.snakeRequests = append(ge.snakeRequests, snakeRequest)
 }
+
+// ReadExportedGame reads the game from the first line of a file written by FlushToFile.
+func ReadExportedGame(inputFile io.Reader) (client.Game, error) {
+	var game client.Game
+	scanner := bufio.NewScanner(inputFile)
+	scanner.Buffer(nil, 1024*1024)
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return game, err
+		}
+		return game, fmt.Errorf("exported game is empty")
+	}
+	if err := json.Unmarshal(scanner.Bytes(), &game); err != nil {
+		return game, fmt.Errorf("invalid exported game: %w", err)
+	}
+	return game, nil
+}
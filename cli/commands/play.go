@@ -2,6 +2,7 @@ package commands
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -14,12 +15,12 @@ import (
 	"path"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/Pikle2/rules"
 	"github.com/Pikle2/rules/board"
 	"github.com/Pikle2/rules/client"
+	"github.com/Pikle2/rules/engine"
 	"github.com/Pikle2/rules/maps"
 	"github.com/google/uuid"
 	"github.com/pkg/browser"
@@ -55,6 +56,11 @@ type GameState struct {
 	Sequential          bool
 	GameType            string
 	MapName             string
+	MapVersion          int
+	Replay              string
+	MapFile             string
+	MapPlugin           string
+	MapParams           []string
 	ViewMap             bool
 	UseColor            bool
 	Seed                int64
@@ -66,6 +72,9 @@ type GameState struct {
 	MinimumFood         int
 	HazardDamagePerTurn int
 	ShrinkEveryNTurns   int
+	Visibility          string
+	VisibilityRadius    int
+	Strict              bool
 
 	// Internal game state
 	settings    map[string]string
@@ -74,8 +83,11 @@ type GameState struct {
 	httpClient  TimedHttpClient
 	ruleset     rules.Ruleset
 	gameMap     maps.GameMap
+	visibility  client.Visibility
 	outputFile  io.WriteCloser
 	idGenerator func(int) string
+	game        *engine.Game
+	snakeIOs    []*snakeIO
 }
 
 func NewPlayCommand() *cobra.Command {
@@ -102,7 +114,12 @@ func NewPlayCommand() *cobra.Command {
 	playCmd.Flags().IntVarP(&gameState.Timeout, "timeout", "t", 500, "Request Timeout")
 	playCmd.Flags().BoolVarP(&gameState.Sequential, "sequential", "s", false, "Use Sequential Processing")
 	playCmd.Flags().StringVarP(&gameState.GameType, "gametype", "g", "standard", "Type of Game Rules")
-	playCmd.Flags().StringVarP(&gameState.MapName, "map", "m", "standard", "Game map to use to populate the board")
+	playCmd.Flags().StringVarP(&gameState.MapName, "map", "m", "standard", "Game map to use to populate the board, or maps joined with + to layer them, e.g. standard+hz_spiral")
+	playCmd.Flags().IntVar(&gameState.MapVersion, "map-version", 0, "Version of the game map to use, see 'battlesnake map info' for the versions of a map (default latest)")
+	playCmd.Flags().StringVar(&gameState.Replay, "replay", "", "Game file written by --output to take the game type, settings, map and map version from, to play the game again")
+	playCmd.Flags().StringVar(&gameState.MapFile, "map-file", "", "JSON, YAML or ASCII (.txt) map file to use instead of a built-in map")
+	playCmd.Flags().StringVar(&gameState.MapPlugin, "map-plugin", "", "Command that runs a map plugin to use instead of a built-in map, e.g. \"python3 my_map.py\"")
+	playCmd.Flags().StringArrayVar(&gameState.MapParams, "map-param", nil, "Map parameter in the form key=value, see 'battlesnake map info' for the parameters of a map")
 	playCmd.Flags().BoolVarP(&gameState.ViewMap, "viewmap", "v", false, "View the Map Each Turn")
 	playCmd.Flags().BoolVarP(&gameState.UseColor, "color", "c", false, "Use color to draw the map")
 	playCmd.Flags().Int64VarP(&gameState.Seed, "seed", "r", time.Now().UTC().UnixNano(), "Random Seed")
@@ -116,6 +133,9 @@ func NewPlayCommand() *cobra.Command {
 	playCmd.Flags().IntVar(&gameState.MinimumFood, "minimumFood", 1, "Minimum food to keep on the board every turn")
 	playCmd.Flags().IntVar(&gameState.HazardDamagePerTurn, "hazardDamagePerTurn", 14, "Health damage a snake will take when ending its turn in a hazard")
 	playCmd.Flags().IntVar(&gameState.ShrinkEveryNTurns, "shrinkEveryNTurns", 25, "In Royale mode, the number of turns between generating new hazards")
+	playCmd.Flags().StringVar(&gameState.Visibility, "visibility", client.VisibilityFull, "What each snake can see of the board: full, radius (squares near its head) or lineOfSight (squares not hidden behind walls)")
+	playCmd.Flags().IntVar(&gameState.VisibilityRadius, "visibilityRadius", 0, "With radius or lineOfSight visibility, how many moves away from its head a snake can see (default 5 for radius, unlimited for lineOfSight)")
+	playCmd.Flags().BoolVar(&gameState.Strict, "strict", false, "Validate the board state after every map and ruleset update, and stop the game if it is inconsistent")
 
 	playCmd.Flags().SortFlags = false
 
@@ -137,12 +157,37 @@ func (gameState *GameState) Initialize() error {
 		},
 	}
 
+	if gameState.Replay != "" {
+		if err := gameState.loadReplay(); err != nil {
+			return err
+		}
+	}
+
 	// Load game map
-	gameMap, err := maps.GetMap(gameState.MapName)
+	mapParams, err := parseMapParams(gameState.MapParams)
+	if err != nil {
+		return err
+	}
+	mapName := gameState.MapName
+	if gameState.MapVersion > 0 {
+		if strings.Contains(mapName, maps.LayerSeparator) {
+			return fmt.Errorf("--map-version can't be used with layered map %s, give the version of each layer instead, e.g. %s",
+				mapName, strings.ReplaceAll(mapName, maps.LayerSeparator, maps.VersionSeparator+"1"+maps.LayerSeparator)+maps.VersionSeparator+"1")
+		}
+		mapName = maps.VersionedID(mapName, gameState.MapVersion)
+	}
+	gameMap, err := loadGameMap(mapName, gameState.MapFile, gameState.MapPlugin)
 	if err != nil {
-		return fmt.Errorf("failed to load game map %#v: %v", gameState.MapName, err)
+		return err
+	}
+	if err := gameMap.Meta().ValidateParams(mapParams); err != nil {
+		if pluginMap, ok := gameMap.(*maps.PluginMap); ok {
+			pluginMap.Close()
+		}
+		return fmt.Errorf("game map %s: %w", gameMap.ID(), err)
 	}
 	gameState.gameMap = gameMap
+	gameState.MapName = gameMap.ID()
 
 	// Create settings object
 	gameState.settings = map[string]string{
@@ -151,6 +196,17 @@ func (gameState *GameState) Initialize() error {
 		rules.ParamHazardDamagePerTurn: fmt.Sprint(gameState.HazardDamagePerTurn),
 		rules.ParamShrinkEveryNTurns:   fmt.Sprint(gameState.ShrinkEveryNTurns),
 	}
+	if gameState.Visibility != "" && gameState.Visibility != client.VisibilityFull {
+		gameState.settings[client.ParamVisibility] = gameState.Visibility
+		// 0 leaves the radius to the default of the visibility mode
+		if gameState.VisibilityRadius != 0 {
+			gameState.settings[client.ParamVisibilityRadius] = fmt.Sprint(gameState.VisibilityRadius)
+		}
+	}
+	// Map parameters override the settings flags
+	for key, value := range mapParams {
+		gameState.settings[key] = value
+	}
 
 	// Build ruleset from settings
 	ruleset := rules.NewRulesetBuilder().
@@ -160,6 +216,12 @@ func (gameState *GameState) Initialize() error {
 		NamedRuleset(gameState.GameType)
 	gameState.ruleset = ruleset
 
+	visibility, err := client.VisibilityFromSettings(ruleset.Settings())
+	if err != nil {
+		return err
+	}
+	gameState.visibility = visibility
+
 	// Initialize snake states as empty until we can ping the snake URLs
 	gameState.snakeStates = map[string]SnakeState{}
 
@@ -174,6 +236,33 @@ func (gameState *GameState) Initialize() error {
 	return nil
 }
 
+// loadReplay sets the game type, settings, map and map version of the game from an exported game,
+// so that the game is played with the same version of the map even if the map has changed since.
+func (gameState *GameState) loadReplay() error {
+	f, err := os.Open(gameState.Replay)
+	if err != nil {
+		return fmt.Errorf("failed to open replay file: %w", err)
+	}
+	defer f.Close()
+
+	game, err := ReadExportedGame(f)
+	if err != nil {
+		return fmt.Errorf("failed to read replay file: %w", err)
+	}
+	gameState.GameType = game.Ruleset.Name
+	gameState.MapName = game.Map
+	gameState.MapVersion = game.MapVersion
+	if strings.Contains(game.Map, maps.LayerSeparator) {
+		// Layered maps are exported with the version of each layer in the map name
+		gameState.MapVersion = 0
+	}
+	gameState.FoodSpawnChance = game.Ruleset.Settings.FoodSpawnChance
+	gameState.MinimumFood = game.Ruleset.Settings.MinimumFood
+	gameState.HazardDamagePerTurn = game.Ruleset.Settings.HazardDamagePerTurn
+	gameState.ShrinkEveryNTurns = game.Ruleset.Settings.RoyaleSettings.ShrinkEveryNTurns
+	return nil
+}
+
 // Setup and run a full game.
 func (gameState *GameState) Run() error {
 	var gameOver bool
@@ -187,7 +276,13 @@ func (gameState *GameState) Run() error {
 
 	rand.Seed(gameState.Seed)
 
-	gameOver, boardState, err := gameState.initializeBoardFromArgs()
+	if pluginMap, ok := gameState.gameMap.(*maps.PluginMap); ok {
+		defer pluginMap.Close()
+	}
+
+	ctx := context.Background()
+	game := gameState.engineGame()
+	gameOver, boardState, err := game.Start(ctx)
 	if err != nil {
 		return fmt.Errorf("error initializing board: %w", err)
 	}
@@ -285,7 +380,7 @@ func (gameState *GameState) Run() error {
 		// The third option (filling the `you` key with an arbitrary snake) is the closest to the actual API request that would need the least manipulation to
 		// be adjusted to look like an API call for a specific snake in the game.
 		for _, snakeState := range gameState.snakeStates {
-			snakeRequest := gameState.getRequestBodyForSnake(boardState, snakeState)
+			snakeRequest := gameState.getFullRequestBodyForSnake(boardState, snakeState)
 			gameExporter.AddSnakeRequest(snakeRequest)
 			break
 		}
@@ -297,7 +392,8 @@ func (gameState *GameState) Run() error {
 			endTime = time.Now().Add(time.Duration(gameState.TurnDuration) * time.Millisecond)
 		}
 
-		gameOver, boardState, err = gameState.createNextBoardState(boardState)
+		gameOver, boardState, err = game.Step(ctx)
+		gameState.applySnakeUpdates()
 		if err != nil {
 			return fmt.Errorf("error processing game: %w", err)
 		}
@@ -327,7 +423,7 @@ func (gameState *GameState) Run() error {
 
 		if exportGame {
 			for _, snakeState := range gameState.snakeStates {
-				snakeRequest := gameState.getRequestBodyForSnake(boardState, snakeState)
+				snakeRequest := gameState.getFullRequestBodyForSnake(boardState, snakeState)
 				gameExporter.AddSnakeRequest(snakeRequest)
 				break
 			}
@@ -341,15 +437,18 @@ func (gameState *GameState) Run() error {
 		gameExporter.isDraw = true
 	}
 
+	snakeStates := withBotStates(boardState, gameState.snakeStates)
 	for _, snake := range boardState.Snakes {
-		snakeState := gameState.snakeStates[snake.ID]
+		if bot, ok := rules.GetBot(boardState, snake.ID); ok && bot.ExcludeFromWin {
+			continue
+		}
+		snakeState := snakeStates[snake.ID]
 		if snake.EliminatedCause == rules.NotEliminated {
 			gameExporter.isDraw = false
 			gameExporter.winner = snakeState
 		}
-
-		gameState.sendEndRequest(boardState, snakeState)
 	}
+	game.End(ctx)
 
 	if gameExporter.isDraw {
 		log.INFO.Printf("Game completed after %v turns. It was a draw.", boardState.Turn)
@@ -377,93 +476,71 @@ func (gameState *GameState) Run() error {
 	return nil
 }
 
-func (gameState *GameState) initializeBoardFromArgs() (bool, *rules.BoardState, error) {
-	snakeIds := []string{}
-	for _, snakeState := range gameState.snakeStates {
-		snakeIds = append(snakeIds, snakeState.ID)
-	}
-	boardState, err := maps.SetupBoard(gameState.gameMap.ID(), gameState.ruleset.Settings(), gameState.Width, gameState.Height, snakeIds)
-	if err != nil {
-		return false, nil, fmt.Errorf("error initializing BoardState with map: %w", err)
-	}
-	gameOver, boardState, err := gameState.ruleset.Execute(boardState, nil)
-	if err != nil {
-		return false, nil, fmt.Errorf("error initializing BoardState with ruleset: %w", err)
+// engineGame returns the engine used to play the game, creating it from the current options and snakes
+// the first time it is needed.
+func (gameState *GameState) engineGame() *engine.Game {
+	if gameState.game != nil {
+		return gameState.game
 	}
 
+	gameState.snakeIOs = nil
+	snakes := []engine.SnakeIO{}
 	for _, snakeState := range gameState.snakeStates {
-		snakeRequest := gameState.getRequestBodyForSnake(boardState, snakeState)
-		requestBody := serialiseSnakeRequest(snakeRequest)
-		u, _ := url.ParseRequestURI(snakeState.URL)
-		u.Path = path.Join(u.Path, "start")
-		log.DEBUG.Printf("POST %s: %v", u, string(requestBody))
-		_, _, err = gameState.httpClient.Post(u.String(), "application/json", bytes.NewBuffer(requestBody))
-		if err != nil {
-			log.WARN.Printf("Request to %v failed", u.String())
-		}
+		snake := &snakeIO{gameState: gameState, snakeID: snakeState.ID}
+		gameState.snakeIOs = append(gameState.snakeIOs, snake)
+		snakes = append(snakes, snake)
 	}
-	return gameOver, boardState, nil
+
+	gameState.game = engine.NewGame(gameState.ruleset, gameState.gameMap, gameState.Width, gameState.Height, snakes)
+	gameState.game.Sequential = gameState.Sequential
+	gameState.game.Strict = gameState.Strict
+	return gameState.game
 }
 
+// createNextBoardState produces the board state for the turn after boardState, using the same game loop as Run.
 func (gameState *GameState) createNextBoardState(boardState *rules.BoardState) (bool, *rules.BoardState, error) {
-	// apply PreUpdateBoard before making requests to snakes
-	boardState, err := maps.PreUpdateBoard(gameState.gameMap, boardState, gameState.ruleset.Settings())
-	if err != nil {
-		return false, boardState, fmt.Errorf("error pre-updating board with game map: %w", err)
-	}
+	gameOver, boardState, err := gameState.engineGame().Next(context.Background(), boardState)
+	gameState.applySnakeUpdates()
+	return gameOver, boardState, err
+}
 
-	// get moves from snakes
-	stateUpdates := make(chan SnakeState, len(gameState.snakeStates))
-	if gameState.Sequential {
-		for _, snakeState := range gameState.snakeStates {
-			for _, snake := range boardState.Snakes {
-				if snakeState.ID == snake.ID && snake.EliminatedCause == rules.NotEliminated {
-					nextSnakeState := gameState.getSnakeUpdate(boardState, snakeState)
-					stateUpdates <- nextSnakeState
-				}
-			}
+// applySnakeUpdates stores the results of the latest move requests once all snakes have moved.
+func (gameState *GameState) applySnakeUpdates() {
+	for _, snake := range gameState.snakeIOs {
+		if snake.update != nil {
+			gameState.snakeStates[snake.snakeID] = *snake.update
+			snake.update = nil
 		}
-		close(stateUpdates)
-	} else {
-		var wg sync.WaitGroup
-
-		for _, snakeState := range gameState.snakeStates {
-			for _, snake := range boardState.Snakes {
-				if snakeState.ID == snake.ID && snake.EliminatedCause == rules.NotEliminated {
-					wg.Add(1)
-					go func(snakeState SnakeState) {
-						defer wg.Done()
-						nextSnakeState := gameState.getSnakeUpdate(boardState, snakeState)
-						stateUpdates <- nextSnakeState
-					}(snakeState)
-				}
-			}
-		}
-
-		wg.Wait()
-		close(stateUpdates)
 	}
+}
 
-	var moves []rules.SnakeMove
-	for snakeState := range stateUpdates {
-		gameState.snakeStates[snakeState.ID] = snakeState
-		moves = append(moves, rules.SnakeMove{ID: snakeState.ID, Move: snakeState.LastMove})
-	}
+// snakeIO connects the game engine to a snake over HTTP.
+// Moves may be requested concurrently, so the result of each request is held until applySnakeUpdates
+// is called, rather than being written to snakeStates straight away.
+type snakeIO struct {
+	gameState *GameState
+	snakeID   string
+	update    *SnakeState
+}
 
-	gameOver, boardState, err := gameState.ruleset.Execute(boardState, moves)
-	if err != nil {
-		return false, boardState, fmt.Errorf("error updating board state from ruleset: %w", err)
-	}
+func (s *snakeIO) ID() string {
+	return s.snakeID
+}
 
-	// apply PostUpdateBoard after ruleset operates on snake moves
-	boardState, err = maps.PostUpdateBoard(gameState.gameMap, boardState, gameState.ruleset.Settings())
-	if err != nil {
-		return false, boardState, fmt.Errorf("error post-updating board with game map: %w", err)
-	}
+func (s *snakeIO) Start(ctx context.Context, boardState *rules.BoardState) error {
+	s.gameState.sendStartRequest(boardState, s.gameState.snakeStates[s.snakeID])
+	return nil
+}
 
-	boardState.Turn += 1
+func (s *snakeIO) Move(ctx context.Context, boardState *rules.BoardState) (string, error) {
+	nextSnakeState := s.gameState.getSnakeUpdate(boardState, s.gameState.snakeStates[s.snakeID])
+	s.update = &nextSnakeState
+	return nextSnakeState.LastMove, nil
+}
 
-	return gameOver, boardState, nil
+func (s *snakeIO) End(ctx context.Context, boardState *rules.BoardState) error {
+	s.gameState.sendEndRequest(boardState, s.gameState.snakeStates[s.snakeID])
+	return nil
 }
 
 func (gameState *GameState) getSnakeUpdate(boardState *rules.BoardState, snakeState SnakeState) SnakeState {
@@ -544,6 +621,18 @@ func (gameState *GameState) getSnakeUpdate(boardState *rules.BoardState, snakeSt
 	return snakeState
 }
 
+func (gameState *GameState) sendStartRequest(boardState *rules.BoardState, snakeState SnakeState) {
+	snakeRequest := gameState.getRequestBodyForSnake(boardState, snakeState)
+	requestBody := serialiseSnakeRequest(snakeRequest)
+	u, _ := url.ParseRequestURI(snakeState.URL)
+	u.Path = path.Join(u.Path, "start")
+	log.DEBUG.Printf("POST %s: %v", u, string(requestBody))
+	_, _, err := gameState.httpClient.Post(u.String(), "application/json", bytes.NewBuffer(requestBody))
+	if err != nil {
+		log.WARN.Printf("Request to %v failed", u.String())
+	}
+}
+
 func (gameState *GameState) sendEndRequest(boardState *rules.BoardState, snakeState SnakeState) {
 	snakeRequest := gameState.getRequestBodyForSnake(boardState, snakeState)
 	requestBody := serialiseSnakeRequest(snakeRequest)
@@ -556,7 +645,15 @@ func (gameState *GameState) sendEndRequest(boardState *rules.BoardState, snakeSt
 	}
 }
 
+// getRequestBodyForSnake returns the request sent to a snake, with the board filtered to what the snake can see.
 func (gameState *GameState) getRequestBodyForSnake(boardState *rules.BoardState, snakeState SnakeState) client.SnakeRequest {
+	request := gameState.getFullRequestBodyForSnake(boardState, snakeState)
+	request.Board = client.FilterBoard(request.Board, request.You, gameState.visibility)
+	return request
+}
+
+// getFullRequestBodyForSnake returns the request for a snake with the whole board, which is written to the output file.
+func (gameState *GameState) getFullRequestBodyForSnake(boardState *rules.BoardState, snakeState SnakeState) client.SnakeRequest {
 	var youSnake rules.Snake
 	for _, snk := range boardState.Snakes {
 		if snakeState.ID == snk.ID {
@@ -570,6 +667,7 @@ func (gameState *GameState) getRequestBodyForSnake(boardState *rules.BoardState,
 		Board: convertStateToBoard(boardState, gameState.snakeStates),
 		You:   convertRulesSnake(youSnake, snakeState),
 	}
+	request.You.Score = maps.Scores(boardState)[youSnake.ID]
 	return request
 }
 
@@ -582,8 +680,18 @@ func (gameState *GameState) createClientGame() client.Game {
 			Version:  "cli", // TODO: Use GitHub Release Version
 			Settings: client.ConvertRulesetSettings(gameState.ruleset.Settings()),
 		},
-		Map: gameState.gameMap.ID(),
+		Map:        gameState.mapID(),
+		MapVersion: gameState.gameMap.Meta().Version,
+	}
+}
+
+// mapID returns the ID of the game map, including the version of each layer for layered maps so that
+// the same versions are used when the game is replayed.
+func (gameState *GameState) mapID() string {
+	if layered, ok := gameState.gameMap.(*maps.LayeredMap); ok {
+		return layered.VersionedID()
 	}
+	return gameState.gameMap.ID()
 }
 
 func (gameState *GameState) buildSnakesFromOptions() (map[string]SnakeState, error) {
@@ -671,9 +779,10 @@ func (gameState *GameState) buildSnakesFromOptions() (map[string]SnakeState, err
 
 func (gameState *GameState) printState(boardState *rules.BoardState) {
 	var aliveSnakeNames []string
+	snakeStates := withBotStates(boardState, gameState.snakeStates)
 	for _, snake := range boardState.Snakes {
 		if snake.EliminatedCause == rules.NotEliminated {
-			aliveSnakeNames = append(aliveSnakeNames, gameState.snakeStates[snake.ID].Name)
+			aliveSnakeNames = append(aliveSnakeNames, snakeStates[snake.ID].Name)
 		}
 	}
 	log.INFO.Printf(
@@ -722,8 +831,9 @@ func (gameState *GameState) printMap(boardState *rules.BoardState) {
 	} else {
 		o.WriteString(fmt.Sprintf("Food ⚕: %v\n", boardState.Food))
 	}
+	snakeStates := withBotStates(boardState, gameState.snakeStates)
 	for _, s := range boardState.Snakes {
-		state := gameState.snakeStates[s.ID]
+		state := snakeStates[s.ID]
 
 		red, green, blue := parseSnakeColor(state.Color)
 		for _, b := range s.Body {
@@ -763,9 +873,12 @@ func (gameState *GameState) printMap(boardState *rules.BoardState) {
 
 func (gameState *GameState) buildFrameEvent(boardState *rules.BoardState) board.GameEvent {
 	snakes := []board.Snake{}
+	snakeStates := withBotStates(boardState, gameState.snakeStates)
+	scores := maps.Scores(boardState)
 
 	for _, snake := range boardState.Snakes {
-		snakeState := gameState.snakeStates[snake.ID]
+		snakeState := snakeStates[snake.ID]
+		_, isBot := rules.GetBot(boardState, snake.ID)
 
 		latencyMS := snakeState.Latency.Milliseconds()
 		// round up latency of 0 to 1, to avoid legacy error display in board
@@ -782,9 +895,10 @@ func (gameState *GameState) buildFrameEvent(boardState *rules.BoardState) board.
 			TailType:      snakeState.Tail,
 			Author:        snakeState.Author,
 			StatusCode:    snakeState.StatusCode,
-			IsBot:         false,
-			IsEnvironment: false,
+			IsBot:         isBot,
+			IsEnvironment: isBot,
 			Latency:       fmt.Sprint(latencyMS),
+			Score:         scores[snake.ID],
 		}
 		if snakeState.Error != nil {
 			// Instead of trying to keep in sync with the production engine's
@@ -810,6 +924,9 @@ func (gameState *GameState) buildFrameEvent(boardState *rules.BoardState) board.
 		Food:    boardState.Food,
 		Hazards: boardState.Hazards,
 	}
+	for _, zone := range maps.ControlZones(boardState) {
+		gameFrame.Zones = append(gameFrame.Zones, board.Zone{ID: zone.ID, Cells: zone.Points, Controller: zone.Controller})
+	}
 
 	return board.GameEvent{
 		EventType: board.EVENT_TYPE_FRAME,
@@ -856,13 +973,62 @@ func convertRulesSnakes(snakes []rules.Snake, snakeStates map[string]SnakeState)
 }
 
 func convertStateToBoard(boardState *rules.BoardState, snakeStates map[string]SnakeState) client.Board {
-	return client.Board{
+	clientBoard := client.Board{
 		Height:  boardState.Height,
 		Width:   boardState.Width,
 		Food:    client.CoordFromPointArray(boardState.Food),
 		Hazards: client.CoordFromPointArray(boardState.Hazards),
-		Snakes:  convertRulesSnakes(boardState.Snakes, snakeStates),
+		Snakes:  convertRulesSnakes(boardState.Snakes, withBotStates(boardState, snakeStates)),
+	}
+
+	// Maps with control zones show the zones and the snakes' scores
+	scores := maps.Scores(boardState)
+	for i := range clientBoard.Snakes {
+		clientBoard.Snakes[i].Score = scores[clientBoard.Snakes[i].ID]
+	}
+	for _, zone := range maps.ControlZones(boardState) {
+		clientBoard.Zones = append(clientBoard.Zones, client.Zone{
+			ID:         zone.ID,
+			Cells:      client.CoordFromPointArray(zone.Points),
+			Controller: zone.Controller,
+		})
+	}
+	return clientBoard
+}
+
+// botSnakeColor is the color of bot snakes, which don't have customizations of their own.
+const botSnakeColor = "#888888"
+
+// withBotStates adds a SnakeState for each bot snake on the board, since bots are controlled by the map
+// instead of a snake server. The snakeStates map is returned unchanged if there are no bots.
+func withBotStates(boardState *rules.BoardState, snakeStates map[string]SnakeState) map[string]SnakeState {
+	result := snakeStates
+	for _, snake := range boardState.Snakes {
+		bot, ok := rules.GetBot(boardState, snake.ID)
+		if _, isSnake := snakeStates[snake.ID]; !ok || isSnake {
+			continue
+		}
+		if len(result) == len(snakeStates) {
+			result = make(map[string]SnakeState, len(snakeStates)+1)
+			for id, state := range snakeStates {
+				result[id] = state
+			}
+		}
+		name := bot.Name
+		if name == "" {
+			name = snake.ID
+		}
+		result[snake.ID] = SnakeState{
+			Name:       name,
+			ID:         snake.ID,
+			Character:  '*',
+			Color:      botSnakeColor,
+			Head:       "default",
+			Tail:       "default",
+			StatusCode: http.StatusOK,
+		}
 	}
+	return result
 }
 
 // Parses a color string like "#ef03d3" to rgb values from 0 to 255 or returns
@@ -8,6 +8,8 @@ import (
 
 	//"io/ioutil"
 	"net/http"
+	"os"
+	"path"
 	"strings"
 	"testing"
 	"time"
@@ -15,6 +17,7 @@ import (
 	"github.com/Pikle2/rules"
 	"github.com/Pikle2/rules/board"
 	"github.com/Pikle2/rules/client"
+	"github.com/Pikle2/rules/maps"
 	"github.com/Pikle2/rules/test"
 	"github.com/stretchr/testify/require"
 )
@@ -80,6 +83,54 @@ func TestGetIndividualBoardStateForSnake(t *testing.T) {
 	requestBody := serialiseSnakeRequest(snakeRequest)
 
 	test.RequireJSONMatchesFixture(t, "testdata/snake_request_body.json", string(requestBody))
+
+	// Maps with control zones add the zones and scores to requests
+	state.PointState = map[rules.Point]int{{X: 5, Y: 5}: 1}
+	state.GameState = map[string]string{}
+	require.NoError(t, maps.NewMapState(state.GameState, "control_zones").SetJSON("zoneControllers", []string{"two"}))
+	require.NoError(t, maps.NewMapState(state.GameState, "control_zones").SetJSON("scores", map[string]int{"one": 2, "two": 5}))
+	snakeRequest = gameState.getRequestBodyForSnake(state, s1State)
+	require.Equal(t, 2, snakeRequest.You.Score)
+	require.Equal(t, 2, snakeRequest.Board.Snakes[0].Score)
+	require.Equal(t, 5, snakeRequest.Board.Snakes[1].Score)
+	require.Equal(t, []client.Zone{{ID: 1, Cells: []client.Coord{{X: 5, Y: 5}}, Controller: "two"}}, snakeRequest.Board.Zones)
+}
+
+func TestGetRequestBodyForSnakeVisibility(t *testing.T) {
+	s1 := rules.Snake{ID: "one", Body: []rules.Point{{X: 1, Y: 1}}}
+	s2 := rules.Snake{ID: "two", Body: []rules.Point{{X: 9, Y: 9}}}
+	state := rules.NewBoardState(11, 11).
+		WithSnakes([]rules.Snake{s1, s2}).
+		WithFood([]rules.Point{{X: 2, Y: 1}, {X: 8, Y: 9}})
+
+	gameState := buildDefaultGameState()
+	gameState.Visibility = client.VisibilityRadius
+	gameState.VisibilityRadius = 3
+	require.NoError(t, gameState.Initialize())
+	require.Equal(t, client.Visibility{Mode: client.VisibilityRadius, Radius: 3, HazardDamage: 14}, gameState.visibility)
+	s1State := SnakeState{ID: "one", Name: "ONE"}
+	gameState.snakeStates = map[string]SnakeState{
+		"one": s1State,
+		"two": {ID: "two", Name: "TWO"},
+	}
+
+	// Snakes only see what's near them, but the output file gets the whole board
+	snakeRequest := gameState.getRequestBodyForSnake(state, s1State)
+	require.Len(t, snakeRequest.Board.Snakes, 1)
+	require.Equal(t, []client.Coord{{X: 2, Y: 1}}, snakeRequest.Board.Food)
+	snakeRequest = gameState.getFullRequestBodyForSnake(state, s1State)
+	require.Len(t, snakeRequest.Board.Snakes, 2)
+	require.Len(t, snakeRequest.Board.Food, 2)
+
+	// Without a radius, line of sight is only limited by walls
+	gameState = buildDefaultGameState()
+	gameState.Visibility = client.VisibilityLineOfSight
+	require.NoError(t, gameState.Initialize())
+	require.Equal(t, client.Visibility{Mode: client.VisibilityLineOfSight, HazardDamage: 14}, gameState.visibility)
+
+	gameState = buildDefaultGameState()
+	gameState.Visibility = "xray"
+	require.EqualError(t, gameState.Initialize(), "unknown visibility 'xray', expected one of: full, radius, lineOfSight")
 }
 
 func TestSettingsRequestSerialization(t *testing.T) {
@@ -229,6 +280,13 @@ func TestConvertRulesSnakes(t *testing.T) {
 	}
 }
 
+func withBots(boardState *rules.BoardState, bots map[string]rules.Bot) *rules.BoardState {
+	for id, bot := range bots {
+		rules.SetBot(boardState, id, bot)
+	}
+	return boardState
+}
+
 func TestBuildFrameEvent(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -362,6 +420,88 @@ func TestBuildFrameEvent(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "bots",
+			boardState: withBots(rules.NewBoardState(11, 11).
+				WithSnakes([]rules.Snake{
+					{ID: "bot-1", Body: []rules.Point{{X: 1, Y: 1}}, Health: 100},
+					{ID: "bot-2", Body: []rules.Point{{X: 2, Y: 2}}, Health: 100},
+				}), map[string]rules.Bot{
+				"bot-1": {Policy: maps.BotPolicyPatrol, Name: "Guard"},
+				"bot-2": {Policy: maps.BotPolicyPatrol},
+			}),
+			snakeStates: map[string]SnakeState{},
+			expected: board.GameEvent{
+				EventType: board.EVENT_TYPE_FRAME,
+
+				Data: board.GameFrame{
+					Snakes: []board.Snake{
+						{
+							ID:            "bot-1",
+							Name:          "Guard",
+							Body:          []rules.Point{{X: 1, Y: 1}},
+							Health:        100,
+							Color:         botSnakeColor,
+							HeadType:      "default",
+							TailType:      "default",
+							Latency:       "1",
+							StatusCode:    200,
+							IsBot:         true,
+							IsEnvironment: true,
+						},
+						{
+							ID:            "bot-2",
+							Name:          "bot-2",
+							Body:          []rules.Point{{X: 2, Y: 2}},
+							Health:        100,
+							Color:         botSnakeColor,
+							HeadType:      "default",
+							TailType:      "default",
+							Latency:       "1",
+							StatusCode:    200,
+							IsBot:         true,
+							IsEnvironment: true,
+						},
+					},
+					Food:    []rules.Point{},
+					Hazards: []rules.Point{},
+				},
+			},
+		},
+		{
+			name: "control zones",
+			boardState: rules.NewBoardState(11, 11).
+				WithSnakes([]rules.Snake{
+					{ID: "1", Body: []rules.Point{{X: 5, Y: 5}}, Health: 100},
+				}).
+				WithPointState(map[rules.Point]int{{X: 5, Y: 5}: 1, {X: 5, Y: 6}: 1}).
+				WithGameState(map[string]string{"map.control_zones.zoneControllers": `["1"]`, "map.control_zones.scores": `{"1":4}`}),
+			snakeStates: map[string]SnakeState{
+				"1": {Name: "one", StatusCode: 200},
+			},
+			expected: board.GameEvent{
+				EventType: board.EVENT_TYPE_FRAME,
+
+				Data: board.GameFrame{
+					Snakes: []board.Snake{
+						{
+							ID:         "1",
+							Name:       "one",
+							Body:       []rules.Point{{X: 5, Y: 5}},
+							Health:     100,
+							Latency:    "1",
+							StatusCode: 200,
+							Score:      4,
+						},
+					},
+					Food:    []rules.Point{},
+					Hazards: []rules.Point{},
+					Zones: []board.Zone{
+						{ID: 1, Cells: []rules.Point{{X: 5, Y: 5}, {X: 5, Y: 6}}, Controller: "1"},
+					},
+				},
+			},
+		},
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
@@ -549,6 +689,40 @@ func TestCreateNextBoardState(t *testing.T) {
 	}
 }
 
+func TestCreateNextBoardStateStrict(t *testing.T) {
+	s1 := rules.Snake{ID: "one", Body: []rules.Point{{X: 3, Y: 3}}, Health: 100}
+	snakeState := SnakeState{
+		ID:  s1.ID,
+		URL: "http://example.com",
+	}
+
+	for _, strict := range []bool{false, true} {
+		t.Run(fmt.Sprintf("strict_%v", strict), func(t *testing.T) {
+			// food out of bounds is never cleaned up by the standard map or ruleset
+			boardState := rules.NewBoardState(11, 11).
+				WithSnakes([]rules.Snake{s1}).
+				WithFood([]rules.Point{{X: 11, Y: 0}})
+
+			gameState := buildDefaultGameState()
+			gameState.Strict = strict
+			err := gameState.Initialize()
+			require.NoError(t, err)
+			gameState.snakeStates = map[string]SnakeState{s1.ID: snakeState}
+			gameState.httpClient = stubHTTPClient{nil, 200, func(_ string) string { return `{"move": "right"}` }, 0}
+
+			_, _, err = gameState.createNextBoardState(boardState)
+			if !strict {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, "invalid after PreUpdateBoard")
+			var validationErr rules.BoardStateValidationError
+			require.ErrorAs(t, err, &validationErr)
+			require.Equal(t, "Food[0]", validationErr[0].Field)
+		})
+	}
+}
+
 func TestOutputFile(t *testing.T) {
 	gameState := buildDefaultGameState()
 	gameState.Names = []string{"example snake"}
@@ -648,3 +822,138 @@ func (client stubHTTPClient) Get(url string) (*http.Response, time.Duration, err
 func (client stubHTTPClient) Post(url string, contentType string, body io.Reader) (*http.Response, time.Duration, error) {
 	return client.request(url)
 }
+
+func TestInitializeMapParams(t *testing.T) {
+	gameState := buildDefaultGameState()
+	gameState.MapName = "sinkholes"
+	gameState.MapParams = []string{"shrinkEveryNTurns=5", "damagePerTurn=30"}
+	require.NoError(t, gameState.Initialize())
+	require.Equal(t, "5", gameState.settings[rules.ParamShrinkEveryNTurns])
+	require.Equal(t, 5, gameState.ruleset.Settings().Int(rules.ParamShrinkEveryNTurns, 0))
+	require.Equal(t, 30, gameState.ruleset.Settings().Int(rules.ParamHazardDamagePerTurn, 0))
+
+	gameState = buildDefaultGameState()
+	gameState.MapName = "sinkholes"
+	gameState.MapParams = []string{"shrinkEveryNTurns=often"}
+	require.EqualError(t, gameState.Initialize(), `game map sinkholes: map parameter shrinkEveryNTurns must be an int, got "often"`)
+
+	gameState = buildDefaultGameState()
+	gameState.MapParams = []string{"shrinkEveryNTurns=5"}
+	require.EqualError(t, gameState.Initialize(), "game map standard: unknown map parameter shrinkEveryNTurns, expected one of: foodSpawner, snakePlacement, startBody")
+
+	gameState = buildDefaultGameState()
+	gameState.MapParams = []string{"shrinkEveryNTurns"}
+	require.EqualError(t, gameState.Initialize(), `invalid map parameter "shrinkEveryNTurns", expected key=value`)
+}
+
+type versionedTestMap struct {
+	maps.StubMap
+	version int
+}
+
+func (m versionedTestMap) Meta() maps.Metadata {
+	return maps.Metadata{Version: m.version, MinPlayers: 1, MaxPlayers: 8, BoardSizes: maps.AnySize()}
+}
+
+func TestInitializeMapVersion(t *testing.T) {
+	latest := versionedTestMap{StubMap: maps.StubMap{Id: "versioned"}, version: 2}
+	previous := versionedTestMap{StubMap: maps.StubMap{Id: "versioned"}, version: 1}
+	maps.TestMap("versioned", latest, func() {
+		maps.TestMap(maps.VersionedID("versioned", 1), previous, func() {
+			gameState := buildDefaultGameState()
+			gameState.MapName = "versioned"
+			require.NoError(t, gameState.Initialize())
+			require.Equal(t, 2, gameState.createClientGame().MapVersion)
+
+			gameState = buildDefaultGameState()
+			gameState.MapName = "versioned"
+			gameState.MapVersion = 1
+			require.NoError(t, gameState.Initialize())
+			require.Equal(t, "versioned", gameState.MapName)
+			require.Equal(t, 1, gameState.createClientGame().MapVersion)
+
+			gameState = buildDefaultGameState()
+			gameState.MapName = "versioned"
+			gameState.MapVersion = 3
+			require.EqualError(t, gameState.Initialize(), `failed to load game map "versioned@3": version 3 of map 'versioned' is not available, available versions: [1 2]`)
+
+			// Replays take the map version and settings from the exported game
+			exported := &GameExporter{game: client.Game{
+				ID:         "GAME_ID",
+				Map:        "versioned",
+				MapVersion: 1,
+				Ruleset: client.Ruleset{
+					Name: rules.GameTypeRoyale,
+					Settings: client.ConvertRulesetSettings(rules.NewSettings(map[string]string{
+						rules.ParamFoodSpawnChance:   "1",
+						rules.ParamMinimumFood:       "2",
+						rules.ParamShrinkEveryNTurns: "4",
+					})),
+				},
+			}}
+			replayPath := path.Join(t.TempDir(), "game.jsonl")
+			replayFile, err := os.Create(replayPath)
+			require.NoError(t, err)
+			_, err = exported.FlushToFile(replayFile)
+			require.NoError(t, err)
+			require.NoError(t, replayFile.Close())
+
+			gameState = buildDefaultGameState()
+			gameState.Replay = replayPath
+			require.NoError(t, gameState.Initialize())
+			require.Equal(t, rules.GameTypeRoyale, gameState.ruleset.Name())
+			require.Equal(t, 1, gameState.gameMap.Meta().Version)
+			require.Equal(t, 1, gameState.ruleset.Settings().Int(rules.ParamFoodSpawnChance, 0))
+			require.Equal(t, 2, gameState.ruleset.Settings().Int(rules.ParamMinimumFood, 0))
+			require.Equal(t, 4, gameState.ruleset.Settings().Int(rules.ParamShrinkEveryNTurns, 0))
+		})
+	})
+}
+
+func TestInitializeLayeredMapVersion(t *testing.T) {
+	standardVersion := maps.StandardMap{}.Meta().Version
+	snailVersion := maps.SnailModeMap{}.Meta().Version
+
+	// Layered maps are exported with the version of each layer
+	gameState := buildDefaultGameState()
+	gameState.MapName = "standard+snail_mode"
+	require.NoError(t, gameState.Initialize())
+	game := gameState.createClientGame()
+	require.Equal(t, fmt.Sprintf("standard@%d+snail_mode@%d", standardVersion, snailVersion), game.Map)
+	require.Equal(t, standardVersion+snailVersion, game.MapVersion)
+
+	gameState = buildDefaultGameState()
+	gameState.MapName = "standard+snail_mode"
+	gameState.MapVersion = 3
+	require.EqualError(t, gameState.Initialize(), "--map-version can't be used with layered map standard+snail_mode, give the version of each layer instead, e.g. standard@1+snail_mode@1")
+
+	// Replays of layered maps use the version of each layer
+	exported := &GameExporter{game: client.Game{
+		ID:         "GAME_ID",
+		Map:        fmt.Sprintf("standard@%d+snail_mode@1", standardVersion),
+		MapVersion: standardVersion + 1,
+		Ruleset:    client.Ruleset{Name: rules.GameTypeStandard, Settings: client.ConvertRulesetSettings(rules.Settings{})},
+	}}
+	replayPath := path.Join(t.TempDir(), "game.jsonl")
+	replayFile, err := os.Create(replayPath)
+	require.NoError(t, err)
+	_, err = exported.FlushToFile(replayFile)
+	require.NoError(t, err)
+	require.NoError(t, replayFile.Close())
+
+	gameState = buildDefaultGameState()
+	gameState.Replay = replayPath
+	require.NoError(t, gameState.Initialize())
+	require.Equal(t, "standard+snail_mode", gameState.gameMap.ID())
+	require.Equal(t, standardVersion+1, gameState.gameMap.Meta().Version)
+	require.Equal(t, exported.game.Map, gameState.createClientGame().Map)
+}
+
+func TestFormatMapParameter(t *testing.T) {
+	require.Equal(t, "shrinkEveryNTurns (int, default 10): Turns between growth", formatMapParameter(maps.Parameter{
+		Name: "shrinkEveryNTurns", Type: maps.ParamTypeInt, Default: "10", Description: "Turns between growth",
+	}))
+	require.Equal(t, "damagePerTurn (int, required): Wall damage", formatMapParameter(maps.Parameter{
+		Name: "damagePerTurn", Type: maps.ParamTypeInt, Description: "Wall damage",
+	}))
+}
@@ -0,0 +1,325 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Pikle2/rules"
+	"github.com/Pikle2/rules/maps"
+	"github.com/spf13/cobra"
+	log "github.com/spf13/jwalterweatherman"
+)
+
+const (
+	renderFormatASCII    = "ascii"
+	renderFormatTimeline = "timeline"
+	renderFormatSVG      = "svg"
+)
+
+// mapRenderer runs a map's updates without a ruleset or moving snakes, to show how the board changes over time.
+type mapRenderer struct {
+	MapFile    string
+	MapPlugin  string
+	Width      int
+	Height     int
+	Seed       int64
+	Turns      int
+	Players    int
+	Format     string
+	Every      int
+	OutputPath string
+	MapParams  []string
+
+	FoodSpawnChance     int
+	MinimumFood         int
+	HazardDamagePerTurn int
+	ShrinkEveryNTurns   int
+}
+
+// Frames drawn in each row of an SVG contact sheet, and the size of each board square.
+const svgColumns, svgSquareSize = 6, 12
+
+func NewMapRenderCommand() *cobra.Command {
+	renderer := mapRenderer{}
+	var renderCmd = &cobra.Command{
+		Use:   "render [flags] [map_name]",
+		Short: "Show how a map changes over a game, without snakes",
+		Long: `Show how a map changes over a game, without snakes.
+
+The map sets up the board with placeholder snakes that never move, and then updates it
+every turn. The board can be output as ASCII frames, as a timeline of hazard changes, or
+as an SVG contact sheet with a frame for every few turns.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			mapName := ""
+			if len(args) > 0 {
+				mapName = args[0]
+			}
+			if len(args) > 1 || (mapName == "" && renderer.MapFile == "" && renderer.MapPlugin == "") {
+				err := cmd.Help()
+				if err != nil {
+					log.ERROR.Fatal(err)
+				}
+				return
+			}
+
+			gameMap, err := loadGameMap(mapName, renderer.MapFile, renderer.MapPlugin)
+			if err != nil {
+				log.ERROR.Fatal(err)
+			}
+			if pluginMap, ok := gameMap.(*maps.PluginMap); ok {
+				defer pluginMap.Close()
+			}
+
+			frames, err := renderer.frames(gameMap)
+			if err != nil {
+				log.ERROR.Fatalf("Error rendering map: %v", err)
+			}
+
+			var output io.Writer = os.Stdout
+			if renderer.OutputPath != "" {
+				f, err := os.OpenFile(renderer.OutputPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+				if err != nil {
+					log.ERROR.Fatalf("Failed to open output file: %v", err)
+				}
+				defer f.Close()
+				output = f
+			}
+			if err := renderer.render(output, gameMap, frames); err != nil {
+				log.ERROR.Fatalf("Error rendering map: %v", err)
+			}
+		},
+	}
+
+	renderCmd.Flags().StringVar(&renderer.MapFile, "map-file", "", "JSON, YAML or ASCII (.txt) map file to render instead of a built-in map")
+	renderCmd.Flags().StringVar(&renderer.MapPlugin, "map-plugin", "", "Command that runs a map plugin to render instead of a built-in map")
+	renderCmd.Flags().IntVarP(&renderer.Width, "width", "W", 11, "Width of Board")
+	renderCmd.Flags().IntVarP(&renderer.Height, "height", "H", 11, "Height of Board")
+	renderCmd.Flags().Int64VarP(&renderer.Seed, "seed", "r", time.Now().UTC().UnixNano(), "Random Seed")
+	renderCmd.Flags().IntVar(&renderer.Turns, "turns", 100, "Number of turns to render")
+	renderCmd.Flags().IntVar(&renderer.Players, "players", 0, "Number of placeholder snakes (default the map's minimum players)")
+	renderCmd.Flags().StringVarP(&renderer.Format, "format", "f", renderFormatASCII, "Output format: ascii, timeline or svg")
+	renderCmd.Flags().IntVar(&renderer.Every, "every", 0, "Only output every N turns (default 1, or enough for 24 frames in SVG)")
+	renderCmd.Flags().StringVarP(&renderer.OutputPath, "output", "o", "", "File path to write to instead of stdout. Existing files will be overwritten")
+	renderCmd.Flags().IntVar(&renderer.FoodSpawnChance, "foodSpawnChance", 15, "Percentage chance of spawning a new food every round")
+	renderCmd.Flags().IntVar(&renderer.MinimumFood, "minimumFood", 1, "Minimum food to keep on the board every turn")
+	renderCmd.Flags().IntVar(&renderer.HazardDamagePerTurn, "hazardDamagePerTurn", 14, "Health damage a snake will take when ending its turn in a hazard")
+	renderCmd.Flags().IntVar(&renderer.ShrinkEveryNTurns, "shrinkEveryNTurns", 25, "In Royale mode, the number of turns between generating new hazards")
+	renderCmd.Flags().StringArrayVar(&renderer.MapParams, "map-param", nil, "Map parameter in the form key=value, see 'battlesnake map info' for the parameters of a map")
+
+	renderCmd.Flags().SortFlags = false
+
+	return renderCmd
+}
+
+// frames sets up the board and applies the map's updates for every turn, returning the board on each turn.
+func (renderer *mapRenderer) frames(gameMap maps.GameMap) ([]*rules.BoardState, error) {
+	mapParams, err := parseMapParams(renderer.MapParams)
+	if err != nil {
+		return nil, err
+	}
+	if err := gameMap.Meta().ValidateParams(mapParams); err != nil {
+		return nil, fmt.Errorf("game map %s: %w", gameMap.ID(), err)
+	}
+	params := map[string]string{
+		rules.ParamFoodSpawnChance:     fmt.Sprint(renderer.FoodSpawnChance),
+		rules.ParamMinimumFood:         fmt.Sprint(renderer.MinimumFood),
+		rules.ParamHazardDamagePerTurn: fmt.Sprint(renderer.HazardDamagePerTurn),
+		rules.ParamShrinkEveryNTurns:   fmt.Sprint(renderer.ShrinkEveryNTurns),
+	}
+	// Map parameters override the settings flags
+	for key, value := range mapParams {
+		params[key] = value
+	}
+	settings := rules.NewSettings(params).WithSeed(renderer.Seed)
+
+	players := renderer.Players
+	if players <= 0 {
+		players = gameMap.Meta().MinPlayers
+	}
+	if players <= 0 {
+		players = 1
+	}
+	snakeIDs := make([]string, 0, players)
+	for i := 0; i < players; i++ {
+		snakeIDs = append(snakeIDs, fmt.Sprintf("snake-%d", i+1))
+	}
+
+	boardState, err := maps.SetupBoardWithMap(gameMap, settings, renderer.Width, renderer.Height, snakeIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up board: %w", err)
+	}
+	frames := []*rules.BoardState{boardState}
+	for boardState.Turn < renderer.Turns {
+		boardState, err = maps.PreUpdateBoard(gameMap, boardState, settings)
+		if err != nil {
+			return frames, fmt.Errorf("failed to pre-update board on turn %d: %w", boardState.Turn, err)
+		}
+		boardState, err = maps.PostUpdateBoard(gameMap, boardState, settings)
+		if err != nil {
+			return frames, fmt.Errorf("failed to post-update board on turn %d: %w", boardState.Turn, err)
+		}
+		boardState.Turn += 1
+		frames = append(frames, boardState)
+	}
+	return frames, nil
+}
+
+// render writes the frames in the chosen format.
+func (renderer *mapRenderer) render(w io.Writer, gameMap maps.GameMap, frames []*rules.BoardState) error {
+	every := renderer.Every
+	if every <= 0 {
+		every = 1
+		if renderer.Format == renderFormatSVG {
+			every = (len(frames) + 23) / 24
+		}
+	}
+
+	switch renderer.Format {
+	case renderFormatASCII:
+		return renderASCIIFrames(w, frames, every)
+	case renderFormatTimeline:
+		return renderHazardTimeline(w, frames, every)
+	case renderFormatSVG:
+		return renderSVGContactSheet(w, gameMap.ID(), frames, every)
+	}
+	return fmt.Errorf("unknown format %q, expected ascii, timeline or svg", renderer.Format)
+}
+
+// renderASCIIFrames writes every Nth frame in the board text format, separated by blank lines.
+func renderASCIIFrames(w io.Writer, frames []*rules.BoardState, every int) error {
+	for i := 0; i < len(frames); i += every {
+		text, err := rules.FormatBoardText(frames[i])
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			text = "\n" + text
+		}
+		if _, err := io.WriteString(w, text); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderHazardTimeline writes a table of how many hazards there are on each turn, and how many
+// were added and removed since the previous line.
+func renderHazardTimeline(w io.Writer, frames []*rules.BoardState, every int) error {
+	if _, err := fmt.Fprintf(w, "%5s %8s %8s %6s %8s %5s\n", "TURN", "HAZARDS", "SQUARES", "ADDED", "REMOVED", "FOOD"); err != nil {
+		return err
+	}
+	var previous []rules.Point
+	for i := 0; i < len(frames); i += every {
+		frame := frames[i]
+		added, removed := countPointChanges(previous, frame.Hazards)
+		squares := map[rules.Point]bool{}
+		for _, p := range frame.Hazards {
+			squares[rules.Point{X: p.X, Y: p.Y}] = true
+		}
+		if _, err := fmt.Fprintf(w, "%5d %8d %8d %6d %8d %5d\n", frame.Turn, len(frame.Hazards), len(squares), added, removed, len(frame.Food)); err != nil {
+			return err
+		}
+		previous = frame.Hazards
+	}
+	return nil
+}
+
+// countPointChanges counts how many points were added and removed between two lists, counting repeated points separately.
+func countPointChanges(from, to []rules.Point) (added, removed int) {
+	counts := map[rules.Point]int{}
+	for _, p := range from {
+		counts[p]++
+	}
+	for _, p := range to {
+		counts[p]--
+	}
+	for _, n := range counts {
+		if n > 0 {
+			removed += n
+		} else {
+			added -= n
+		}
+	}
+	return added, removed
+}
+
+// renderSVGContactSheet draws every Nth frame side by side in an SVG image, with darker squares for stacked hazards.
+func renderSVGContactSheet(w io.Writer, title string, frames []*rules.BoardState, every int) error {
+	var shown []*rules.BoardState
+	for i := 0; i < len(frames); i += every {
+		shown = append(shown, frames[i])
+	}
+	if len(shown) == 0 {
+		return nil
+	}
+
+	const margin, labelHeight = 8, 16
+	boardWidth := shown[0].Width * svgSquareSize
+	boardHeight := shown[0].Height * svgSquareSize
+	cellWidth := boardWidth + margin
+	cellHeight := boardHeight + labelHeight + margin
+	columns := svgColumns
+	if len(shown) < columns {
+		columns = len(shown)
+	}
+	rows := (len(shown) + columns - 1) / columns
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-family="monospace" font-size="11">`+"\n",
+		columns*cellWidth+margin, rows*cellHeight+margin+labelHeight)
+	fmt.Fprintf(&sb, `<title>%s</title>`+"\n", svgEscape(title))
+	fmt.Fprintf(&sb, `<text x="%d" y="%d">%s</text>`+"\n", margin, labelHeight-4, svgEscape(title))
+
+	for i, frame := range shown {
+		x0 := margin + (i%columns)*cellWidth
+		y0 := labelHeight + margin + (i/columns)*cellHeight
+		fmt.Fprintf(&sb, `<g transform="translate(%d,%d)">`+"\n", x0, y0)
+		fmt.Fprintf(&sb, `<text x="0" y="%d">turn %d</text>`+"\n", labelHeight-4, frame.Turn)
+		fmt.Fprintf(&sb, `<rect x="0" y="%d" width="%d" height="%d" fill="#f4f4f4" stroke="#999"/>`+"\n", labelHeight, boardWidth, boardHeight)
+
+		squareY := func(y int) int {
+			return labelHeight + (frame.Height-1-y)*svgSquareSize
+		}
+		stacks := map[rules.Point]int{}
+		var order []rules.Point
+		for _, p := range frame.Hazards {
+			key := rules.Point{X: p.X, Y: p.Y}
+			if stacks[key] == 0 {
+				order = append(order, key)
+			}
+			stacks[key]++
+		}
+		for _, p := range order {
+			opacity := 0.35 * float64(stacks[p])
+			if opacity > 1 {
+				opacity = 1
+			}
+			fmt.Fprintf(&sb, `<rect class="hazard" x="%d" y="%d" width="%d" height="%d" fill="#6b2d8c" fill-opacity="%.2f"/>`+"\n",
+				p.X*svgSquareSize, squareY(p.Y), svgSquareSize, svgSquareSize, opacity)
+		}
+		for _, p := range frame.Food {
+			fmt.Fprintf(&sb, `<circle class="food" cx="%d" cy="%d" r="%d" fill="#e0453a"/>`+"\n",
+				p.X*svgSquareSize+svgSquareSize/2, squareY(p.Y)+svgSquareSize/2, svgSquareSize/3)
+		}
+		for _, snake := range frame.Snakes {
+			if len(snake.Body) == 0 {
+				continue
+			}
+			head := snake.Body[0]
+			fmt.Fprintf(&sb, `<rect class="snake" x="%d" y="%d" width="%d" height="%d" fill="#2d7d46"/>`+"\n",
+				head.X*svgSquareSize+1, squareY(head.Y)+1, svgSquareSize-2, svgSquareSize-2)
+		}
+		sb.WriteString("</g>\n")
+	}
+	sb.WriteString("</svg>\n")
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func svgEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;").Replace(s)
+}
@@ -0,0 +1,156 @@
+package commands
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/Pikle2/rules"
+	"github.com/Pikle2/rules/maps"
+	"github.com/stretchr/testify/require"
+)
+
+func buildTestMapRenderer(format string) *mapRenderer {
+	return &mapRenderer{
+		Width:               5,
+		Height:              5,
+		Seed:                1,
+		Turns:               4,
+		Format:              format,
+		FoodSpawnChance:     0,
+		MinimumFood:         0,
+		HazardDamagePerTurn: 14,
+		ShrinkEveryNTurns:   25,
+	}
+}
+
+func newRenderTestMap(t *testing.T) maps.GameMap {
+	gameMap, err := maps.ParseMapFile([]byte(`
+id: pulse
+food: {placement: none}
+layouts:
+  - size: 5x5
+    snakeStarts: [[0, 0]]
+    food: [[4, 4]]
+    schedule:
+      - turn: 2
+        every: 2
+        hazards: [[2, 2]]
+      - turn: 3
+        clear: true
+`), "yaml")
+	require.NoError(t, err)
+	return gameMap
+}
+
+func TestMapRendererFrames(t *testing.T) {
+	gameMap := newRenderTestMap(t)
+	frames, err := buildTestMapRenderer(renderFormatASCII).frames(gameMap)
+	require.NoError(t, err)
+
+	require.Len(t, frames, 5)
+	for turn, frame := range frames {
+		require.Equal(t, turn, frame.Turn)
+		require.Equal(t, []rules.Point{{X: 4, Y: 4}}, frame.Food)
+		require.Equal(t, []rules.Point{{X: 0, Y: 0}, {X: 0, Y: 0}, {X: 0, Y: 0}}, frame.Snakes[0].Body)
+	}
+	require.Empty(t, frames[1].Hazards)
+	require.Equal(t, []rules.Point{{X: 2, Y: 2}}, frames[2].Hazards)
+	require.Empty(t, frames[3].Hazards)
+	require.Equal(t, []rules.Point{{X: 2, Y: 2}}, frames[4].Hazards)
+}
+
+func TestMapRendererASCII(t *testing.T) {
+	gameMap := newRenderTestMap(t)
+	renderer := buildTestMapRenderer(renderFormatASCII)
+	renderer.Every = 2
+	frames, err := renderer.frames(gameMap)
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, renderer.render(&out, gameMap, frames))
+
+	expected := make([]string, 0, 3)
+	for _, i := range []int{0, 2, 4} {
+		text, err := rules.FormatBoardText(frames[i])
+		require.NoError(t, err)
+		expected = append(expected, text)
+	}
+	require.Equal(t, strings.Join(expected, "\n"), out.String())
+}
+
+func TestMapRendererTimeline(t *testing.T) {
+	gameMap := newRenderTestMap(t)
+	renderer := buildTestMapRenderer(renderFormatTimeline)
+	frames, err := renderer.frames(gameMap)
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, renderer.render(&out, gameMap, frames))
+	require.Equal(t, ` TURN  HAZARDS  SQUARES  ADDED  REMOVED  FOOD
+    0        0        0      0        0     1
+    1        0        0      0        0     1
+    2        1        1      1        0     1
+    3        0        0      0        1     1
+    4        1        1      1        0     1
+`, out.String())
+}
+
+func TestMapRendererSVG(t *testing.T) {
+	gameMap := newRenderTestMap(t)
+	renderer := buildTestMapRenderer(renderFormatSVG)
+	renderer.Turns = 47
+	frames, err := renderer.frames(gameMap)
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, renderer.render(&out, gameMap, frames))
+	svg := out.String()
+
+	// 48 frames are shown as 24 frames, 2 turns apart, and every frame after turn 0 has the hazard
+	require.True(t, strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg"`))
+	require.Equal(t, 24, strings.Count(svg, "<g "))
+	require.Contains(t, svg, "<text x=\"0\" y=\"12\">turn 46</text>")
+	require.NotContains(t, svg, "turn 47")
+	require.Equal(t, 23, strings.Count(svg, `class="hazard"`))
+	require.Equal(t, 24, strings.Count(svg, `class="food"`))
+	require.Equal(t, 24, strings.Count(svg, `class="snake"`))
+}
+
+func TestMapRendererErrors(t *testing.T) {
+	gameMap := newRenderTestMap(t)
+	renderer := buildTestMapRenderer("png")
+	err := renderer.render(&bytes.Buffer{}, gameMap, nil)
+	require.EqualError(t, err, `unknown format "png", expected ascii, timeline or svg`)
+
+	renderer.Width = 7
+	_, err = renderer.frames(gameMap)
+	require.EqualError(t, err, "failed to set up board: This map can only be played on these board sizes: 5x5")
+}
+
+func TestMapRendererMapParams(t *testing.T) {
+	gameMap, err := maps.GetMap("royale")
+	require.NoError(t, err)
+	renderer := buildTestMapRenderer(renderFormatASCII)
+	renderer.Width, renderer.Height = 7, 7
+	renderer.ShrinkEveryNTurns = 2
+	renderer.MapParams = []string{"shrinkPattern=concentric"}
+	frames, err := renderer.frames(gameMap)
+	require.NoError(t, err)
+	require.Empty(t, frames[1].Hazards)
+	require.Len(t, frames[2].Hazards, 7*7-5*5)
+	require.Len(t, frames[4].Hazards, 7*7-3*3)
+
+	renderer.MapParams = []string{"shrinkFinalCells=many"}
+	_, err = renderer.frames(gameMap)
+	require.EqualError(t, err, `game map royale: map parameter shrinkFinalCells must be an int, got "many"`)
+}
+
+func TestCountPointChanges(t *testing.T) {
+	added, removed := countPointChanges(
+		[]rules.Point{{X: 1, Y: 1}, {X: 1, Y: 1}, {X: 2, Y: 2}},
+		[]rules.Point{{X: 1, Y: 1}, {X: 3, Y: 3}, {X: 3, Y: 3}},
+	)
+	require.Equal(t, 2, added)
+	require.Equal(t, 2, removed)
+}
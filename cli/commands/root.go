@@ -27,6 +27,8 @@ func Execute() {
 	mapCommand := NewMapCommand()
 	mapCommand.AddCommand(NewMapListCommand())
 	mapCommand.AddCommand(NewMapInfoCommand())
+	mapCommand.AddCommand(NewMapValidateCommand())
+	mapCommand.AddCommand(NewMapRenderCommand())
 
 	rootCmd.AddCommand(mapCommand)
 
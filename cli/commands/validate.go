@@ -0,0 +1,425 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/Pikle2/rules"
+	"github.com/Pikle2/rules/engine"
+	"github.com/Pikle2/rules/maps"
+	"github.com/spf13/cobra"
+	log "github.com/spf13/jwalterweatherman"
+)
+
+// mapValidator plays simulated games on a map to check that it behaves well.
+type mapValidator struct {
+	MapFile   string
+	MapPlugin string
+	GameType  string
+	Seeds     int
+	Turns     int
+	Fairness  float64
+
+	FoodSpawnChance     int
+	MinimumFood         int
+	HazardDamagePerTurn int
+	ShrinkEveryNTurns   int
+}
+
+// mapValidationIssue is a problem found by the validator in one simulated game.
+type mapValidationIssue struct {
+	Width   int
+	Height  int
+	Players int
+	Seed    int64
+	Turn    int
+	Message string
+}
+
+func (issue mapValidationIssue) String() string {
+	return fmt.Sprintf("%dx%d, %d players, seed %d, turn %d: %s", issue.Width, issue.Height, issue.Players, issue.Seed, issue.Turn, issue.Message)
+}
+
+// Board sizes validated for maps that support any size.
+var unlimitedValidationSizes = []maps.Dimensions{
+	{Width: rules.BoardSizeSmall, Height: rules.BoardSizeSmall},
+	{Width: rules.BoardSizeMedium, Height: rules.BoardSizeMedium},
+	{Width: rules.BoardSizeLarge, Height: rules.BoardSizeLarge},
+}
+
+// Player counts validated for maps that don't declare a maximum.
+const defaultValidationMaxPlayers = 8
+
+func NewMapValidateCommand() *cobra.Command {
+	validator := mapValidator{}
+	var validateCmd = &cobra.Command{
+		Use:   "validate [flags] [map_name]",
+		Short: "Check a map by playing simulated games on it",
+		Long: `Check a map by playing simulated games on it, for every supported board size and number of players.
+
+Each game is played twice with the same seed to check that the map is deterministic.
+Games are also checked for maps that fail to set up the board, items that are off
+the board, food placed on snakes or hazards, and unfair snake start positions.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			mapName := ""
+			if len(args) > 0 {
+				mapName = args[0]
+			}
+			if len(args) > 1 || (mapName == "" && validator.MapFile == "" && validator.MapPlugin == "") {
+				err := cmd.Help()
+				if err != nil {
+					log.ERROR.Fatal(err)
+				}
+				return
+			}
+
+			gameMap, err := loadGameMap(mapName, validator.MapFile, validator.MapPlugin)
+			if err != nil {
+				log.ERROR.Fatal(err)
+			}
+			if pluginMap, ok := gameMap.(*maps.PluginMap); ok {
+				defer pluginMap.Close()
+			}
+
+			issues := validator.validate(gameMap, func(width, height, players int, issues []mapValidationIssue) {
+				status := "ok"
+				if len(issues) > 0 {
+					status = fmt.Sprintf("%d issues", len(issues))
+				}
+				fmt.Printf("%dx%d, %d players: %s\n", width, height, players, status)
+				for _, issue := range issues {
+					fmt.Printf("  %s\n", issue)
+				}
+			})
+			if len(issues) > 0 {
+				log.ERROR.Fatalf("Map %s failed validation with %d issues", gameMap.ID(), len(issues))
+			}
+			fmt.Printf("Map %s passed validation\n", gameMap.ID())
+		},
+	}
+
+	validateCmd.Flags().StringVar(&validator.MapFile, "map-file", "", "JSON, YAML or ASCII (.txt) map file to validate instead of a built-in map")
+	validateCmd.Flags().StringVar(&validator.MapPlugin, "map-plugin", "", "Command that runs a map plugin to validate instead of a built-in map")
+	validateCmd.Flags().StringVarP(&validator.GameType, "gametype", "g", rules.GameTypeStandard, "Type of Game Rules")
+	validateCmd.Flags().IntVar(&validator.Seeds, "seeds", 10, "Number of seeded games to play for each board size and number of players")
+	validateCmd.Flags().IntVar(&validator.Turns, "turns", 100, "Maximum number of turns to play in each game")
+	validateCmd.Flags().Float64Var(&validator.Fairness, "fairness", 0.25, "Minimum ratio between the smallest and largest area closest to each snake at the start")
+	validateCmd.Flags().IntVar(&validator.FoodSpawnChance, "foodSpawnChance", 15, "Percentage chance of spawning a new food every round")
+	validateCmd.Flags().IntVar(&validator.MinimumFood, "minimumFood", 1, "Minimum food to keep on the board every turn")
+	validateCmd.Flags().IntVar(&validator.HazardDamagePerTurn, "hazardDamagePerTurn", 14, "Health damage a snake will take when ending its turn in a hazard")
+	validateCmd.Flags().IntVar(&validator.ShrinkEveryNTurns, "shrinkEveryNTurns", 25, "In Royale mode, the number of turns between generating new hazards")
+
+	validateCmd.Flags().SortFlags = false
+
+	return validateCmd
+}
+
+// validate plays games for every supported size and player count, calling report with the issues for each.
+// It returns all the issues found.
+func (validator *mapValidator) validate(gameMap maps.GameMap, report func(width, height, players int, issues []mapValidationIssue)) []mapValidationIssue {
+	meta := gameMap.Meta()
+	sizes := []maps.Dimensions(meta.BoardSizes)
+	if meta.BoardSizes.IsUnlimited() {
+		sizes = unlimitedValidationSizes
+	}
+	minPlayers, maxPlayers := meta.MinPlayers, meta.MaxPlayers
+	if minPlayers < 1 {
+		minPlayers = 1
+	}
+	if maxPlayers == 0 {
+		maxPlayers = defaultValidationMaxPlayers
+	}
+
+	var allIssues []mapValidationIssue
+	for _, size := range sizes {
+		for players := minPlayers; players <= maxPlayers; players++ {
+			var issues []mapValidationIssue
+			for seed := int64(1); seed <= int64(validator.Seeds); seed++ {
+				issues = append(issues, validator.validateGame(gameMap, size.Width, size.Height, players, seed)...)
+			}
+			if report != nil {
+				report(size.Width, size.Height, players, issues)
+			}
+			allIssues = append(allIssues, issues...)
+		}
+	}
+	return allIssues
+}
+
+// validateGame plays the same seeded game twice and checks every board state.
+// Each kind of problem is only reported the first time it happens in the game.
+func (validator *mapValidator) validateGame(gameMap maps.GameMap, width, height, players int, seed int64) []mapValidationIssue {
+	var issues []mapValidationIssue
+	reported := map[string]bool{}
+	addIssue := func(turn int, format string, args ...interface{}) {
+		message := fmt.Sprintf(format, args...)
+		if reported[message] {
+			return
+		}
+		reported[message] = true
+		issues = append(issues, mapValidationIssue{
+			Width:   width,
+			Height:  height,
+			Players: players,
+			Seed:    seed,
+			Turn:    turn,
+			Message: message,
+		})
+	}
+
+	states, err := validator.playGame(gameMap, width, height, players, seed)
+	if err != nil {
+		turn := 0
+		if len(states) > 0 {
+			turn = states[len(states)-1].Turn
+		}
+		if len(states) == 0 {
+			addIssue(turn, "failed to place snakes, food or hazards: %v", err)
+		} else {
+			addIssue(turn, "map error: %v", err)
+		}
+	}
+	if len(states) == 0 {
+		return issues
+	}
+
+	replay, _ := validator.playGame(gameMap, width, height, players, seed)
+	for i, state := range states {
+		if i >= len(replay) || !reflect.DeepEqual(state, replay[i]) {
+			addIssue(state.Turn, "not deterministic: the board is different when the game is replayed with the same seed")
+			break
+		}
+	}
+
+	var previous *rules.BoardState
+	for _, state := range states {
+		for _, message := range checkValidationBoard(previous, state) {
+			addIssue(state.Turn, "%s", message)
+		}
+		previous = state
+	}
+	if message := checkStartFairness(states[0], validator.Fairness); message != "" {
+		addIssue(0, "%s", message)
+	}
+
+	return issues
+}
+
+// playGame plays a game with stub snakes and returns the board state of every turn.
+// If the map fails, the states up to that point are returned along with the error.
+func (validator *mapValidator) playGame(gameMap maps.GameMap, width, height, players int, seed int64) ([]*rules.BoardState, error) {
+	ruleset := rules.NewRulesetBuilder().
+		WithSeed(seed).
+		WithParams(map[string]string{
+			rules.ParamFoodSpawnChance:     fmt.Sprint(validator.FoodSpawnChance),
+			rules.ParamMinimumFood:         fmt.Sprint(validator.MinimumFood),
+			rules.ParamHazardDamagePerTurn: fmt.Sprint(validator.HazardDamagePerTurn),
+			rules.ParamShrinkEveryNTurns:   fmt.Sprint(validator.ShrinkEveryNTurns),
+		}).
+		WithSolo(players < 2).
+		NamedRuleset(validator.GameType)
+
+	snakes := make([]engine.SnakeIO, 0, players)
+	for i := 0; i < players; i++ {
+		snakes = append(snakes, engine.SnakeFunc{
+			SnakeID:  fmt.Sprintf("snake-%d", i+1),
+			MoveFunc: stubSnakeMove(seed, i),
+		})
+	}
+	game := engine.NewGame(ruleset, gameMap, width, height, snakes)
+	game.Sequential = true
+
+	ctx := context.Background()
+	gameOver, state, err := game.Start(ctx)
+	if err != nil {
+		return nil, err
+	}
+	states := []*rules.BoardState{state}
+	for !gameOver && state.Turn < validator.Turns {
+		gameOver, state, err = game.Step(ctx)
+		if err != nil {
+			return states, err
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+// stubSnakeMove returns a move function that picks a random move that doesn't leave the board
+// or run into a snake, using a random number generator seeded from the game seed and turn.
+func stubSnakeMove(seed int64, index int) func(context.Context, *rules.BoardState) (string, error) {
+	return func(ctx context.Context, boardState *rules.BoardState) (string, error) {
+		var head rules.Point
+		occupied := map[rules.Point]bool{}
+		for _, snake := range boardState.Snakes {
+			if snake.EliminatedCause != rules.NotEliminated {
+				continue
+			}
+			for _, p := range snake.Body {
+				occupied[p] = true
+			}
+			if snake.ID == fmt.Sprintf("snake-%d", index+1) && len(snake.Body) > 0 {
+				head = snake.Body[0]
+			}
+		}
+
+		moves := map[string]rules.Point{
+			rules.MoveUp:    {X: head.X, Y: head.Y + 1},
+			rules.MoveDown:  {X: head.X, Y: head.Y - 1},
+			rules.MoveLeft:  {X: head.X - 1, Y: head.Y},
+			rules.MoveRight: {X: head.X + 1, Y: head.Y},
+		}
+		var safe []string
+		for _, move := range []string{rules.MoveUp, rules.MoveDown, rules.MoveLeft, rules.MoveRight} {
+			p := moves[move]
+			if p.X >= 0 && p.Y >= 0 && p.X < boardState.Width && p.Y < boardState.Height && !occupied[p] {
+				safe = append(safe, move)
+			}
+		}
+		if len(safe) == 0 {
+			return rules.MoveUp, nil
+		}
+		rand := rules.NewSeedRand(seed*1000003 + int64(boardState.Turn)*101 + int64(index))
+		return safe[rand.Intn(len(safe))], nil
+	}
+}
+
+// checkValidationBoard returns problems with a board state that a map is likely to be responsible for.
+// Only food that wasn't on the previous board is checked, so that hazards spreading over food aren't reported.
+func checkValidationBoard(previous, state *rules.BoardState) []string {
+	var messages []string
+	if err := state.Validate(); err != nil {
+		if violations, ok := err.(rules.BoardStateValidationError); ok {
+			for _, v := range violations {
+				messages = append(messages, "invalid board: "+v.String())
+			}
+		} else {
+			messages = append(messages, "invalid board: "+err.Error())
+		}
+	}
+
+	onBoard := func(p rules.Point) bool {
+		return p.X >= 0 && p.Y >= 0 && p.X < state.Width && p.Y < state.Height
+	}
+	hazards := map[rules.Point]bool{}
+	for _, p := range state.Hazards {
+		if !onBoard(p) {
+			messages = append(messages, fmt.Sprintf("hazard at (%d,%d) is off the board", p.X, p.Y))
+		}
+		hazards[rules.Point{X: p.X, Y: p.Y}] = true
+	}
+	bodies := map[rules.Point]string{}
+	for _, snake := range state.Snakes {
+		if snake.EliminatedCause != rules.NotEliminated {
+			continue
+		}
+		for _, p := range snake.Body {
+			bodies[p] = snake.ID
+		}
+	}
+	oldFood := map[rules.Point]int{}
+	if previous != nil {
+		for _, p := range previous.Food {
+			oldFood[p]++
+		}
+	}
+	for _, p := range state.Food {
+		if oldFood[p] > 0 {
+			oldFood[p]--
+			continue
+		}
+		if id, ok := bodies[rules.Point{X: p.X, Y: p.Y}]; ok {
+			messages = append(messages, fmt.Sprintf("food at (%d,%d) is on snake %s", p.X, p.Y, id))
+		}
+		if hazards[rules.Point{X: p.X, Y: p.Y}] {
+			messages = append(messages, fmt.Sprintf("food at (%d,%d) is on a hazard", p.X, p.Y))
+		}
+	}
+	return messages
+}
+
+// checkStartFairness compares the number of squares each snake can reach before any other snake
+// at the start of the game, without going through hazards or snake bodies.
+// It returns a message if the smallest area is less than the given fraction of the largest.
+func checkStartFairness(state *rules.BoardState, fairness float64) string {
+	if len(state.Snakes) < 2 {
+		return ""
+	}
+
+	blocked := map[rules.Point]bool{}
+	for _, p := range state.Hazards {
+		blocked[rules.Point{X: p.X, Y: p.Y}] = true
+	}
+	heads := map[rules.Point][]string{}
+	for _, snake := range state.Snakes {
+		if len(snake.Body) == 0 {
+			return ""
+		}
+		for _, p := range snake.Body {
+			blocked[p] = true
+		}
+		heads[snake.Body[0]] = append(heads[snake.Body[0]], snake.ID)
+	}
+	for head, ids := range heads {
+		if len(ids) > 1 {
+			sort.Strings(ids)
+			return fmt.Sprintf("snakes %s start on the same square (%d,%d)", strings.Join(ids, " and "), head.X, head.Y)
+		}
+	}
+
+	// Breadth first search from all heads at once, where squares reached by more than one snake at
+	// the same distance don't belong to anyone
+	owner := map[rules.Point]string{}
+	distance := map[rules.Point]int{}
+	var queue []rules.Point
+	for _, snake := range state.Snakes {
+		head := snake.Body[0]
+		owner[head] = snake.ID
+		distance[head] = 0
+		queue = append(queue, head)
+	}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		for _, next := range []rules.Point{{X: p.X, Y: p.Y + 1}, {X: p.X, Y: p.Y - 1}, {X: p.X - 1, Y: p.Y}, {X: p.X + 1, Y: p.Y}} {
+			if next.X < 0 || next.Y < 0 || next.X >= state.Width || next.Y >= state.Height || blocked[next] {
+				continue
+			}
+			d, seen := distance[next]
+			if !seen {
+				distance[next] = distance[p] + 1
+				owner[next] = owner[p]
+				queue = append(queue, next)
+			} else if d == distance[p]+1 && owner[next] != owner[p] {
+				owner[next] = ""
+			}
+		}
+	}
+
+	areas := map[string]int{}
+	for _, id := range owner {
+		if id != "" {
+			areas[id]++
+		}
+	}
+	// Bots take up space, but only the players need to start on equal terms
+	smallest, largest := "", ""
+	for _, snake := range state.Snakes {
+		if _, isBot := rules.GetBot(state, snake.ID); isBot {
+			continue
+		}
+		if smallest == "" || areas[snake.ID] < areas[smallest] {
+			smallest = snake.ID
+		}
+		if largest == "" || areas[snake.ID] > areas[largest] {
+			largest = snake.ID
+		}
+	}
+	if float64(areas[smallest]) < fairness*float64(areas[largest]) {
+		return fmt.Sprintf("unfair start positions: snake %s can reach %d squares first, but snake %s can reach %d", smallest, areas[smallest], largest, areas[largest])
+	}
+	return ""
+}
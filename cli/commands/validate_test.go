@@ -0,0 +1,168 @@
+package commands
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Pikle2/rules"
+	"github.com/Pikle2/rules/maps"
+	"github.com/stretchr/testify/require"
+)
+
+func buildDefaultMapValidator() *mapValidator {
+	return &mapValidator{
+		GameType:            rules.GameTypeStandard,
+		Seeds:               3,
+		Turns:               30,
+		Fairness:            0.25,
+		FoodSpawnChance:     15,
+		MinimumFood:         1,
+		HazardDamagePerTurn: 14,
+		ShrinkEveryNTurns:   25,
+	}
+}
+
+// validationTestMap is a StubMap for 7x7 boards with 1 or 2 players
+type validationTestMap struct {
+	maps.StubMap
+	postUpdate func(lastBoardState *rules.BoardState, editor maps.Editor)
+}
+
+func (m validationTestMap) Meta() maps.Metadata {
+	return maps.Metadata{
+		Name:       m.Id,
+		MinPlayers: 1,
+		MaxPlayers: 2,
+		BoardSizes: maps.FixedSizes(maps.Dimensions{Width: 7, Height: 7}),
+	}
+}
+
+func (m validationTestMap) PostUpdateBoard(lastBoardState *rules.BoardState, settings rules.Settings, editor maps.Editor) error {
+	if m.postUpdate != nil {
+		m.postUpdate(lastBoardState, editor)
+	}
+	return m.StubMap.PostUpdateBoard(lastBoardState, settings, editor)
+}
+
+func newValidationTestMap() validationTestMap {
+	return validationTestMap{
+		StubMap: maps.StubMap{
+			Id: "test",
+			SnakePositions: map[string]rules.Point{
+				"snake-1": {X: 1, Y: 1},
+				"snake-2": {X: 5, Y: 5},
+			},
+		},
+	}
+}
+
+func TestMapValidatorValid(t *testing.T) {
+	gameMap, err := maps.ParseASCIIMap(`
+id: quadrants
+minPlayers: 2
+maxPlayers: 4
+[7x7]
+.......
+.C...D.
+.......
+.......
+.......
+.A...B.
+.......
+`)
+	require.NoError(t, err)
+
+	var reported []int
+	issues := buildDefaultMapValidator().validate(gameMap, func(width, height, players int, issues []mapValidationIssue) {
+		require.Equal(t, 7, width)
+		require.Equal(t, 7, height)
+		reported = append(reported, players)
+	})
+	require.Empty(t, issues)
+	require.Equal(t, []int{2, 3, 4}, reported)
+}
+
+func TestMapValidatorIssues(t *testing.T) {
+	validator := buildDefaultMapValidator()
+	validator.Seeds = 1
+
+	t.Run("setup error", func(t *testing.T) {
+		gameMap := newValidationTestMap()
+		gameMap.Error = errors.New("no room")
+		issues := validator.validate(gameMap, nil)
+		require.Len(t, issues, 2)
+		require.Equal(t, "7x7, 1 players, seed 1, turn 0: failed to place snakes, food or hazards: error initializing BoardState with map: no room", issues[0].String())
+	})
+
+	t.Run("not deterministic", func(t *testing.T) {
+		gameMap := newValidationTestMap()
+		calls := 0
+		gameMap.postUpdate = func(lastBoardState *rules.BoardState, editor maps.Editor) {
+			calls++
+			if calls == 5 {
+				editor.AddHazard(rules.Point{X: 3, Y: 3})
+			}
+		}
+		issues := validator.validateGame(gameMap, 7, 7, 2, 1)
+		require.Equal(t, []mapValidationIssue{{
+			Width: 7, Height: 7, Players: 2, Seed: 1, Turn: 5,
+			Message: "not deterministic: the board is different when the game is replayed with the same seed",
+		}}, issues)
+	})
+
+	t.Run("bad placement", func(t *testing.T) {
+		gameMap := newValidationTestMap()
+		gameMap.Hazards = []rules.Point{{X: 3, Y: 3}, {X: 7, Y: 0}}
+		gameMap.postUpdate = func(lastBoardState *rules.BoardState, editor maps.Editor) {
+			editor.AddFood(editor.SnakeBodies()["snake-1"][1])
+			editor.AddFood(rules.Point{X: 3, Y: 3})
+		}
+		oneTurn := *validator
+		oneTurn.Turns = 1
+		var messages []string
+		for _, issue := range oneTurn.validateGame(gameMap, 7, 7, 1, 1) {
+			messages = append(messages, issue.Message)
+		}
+		require.Equal(t, []string{
+			"hazard at (7,0) is off the board",
+			"food at (1,1) is on snake snake-1",
+			"food at (3,3) is on a hazard",
+		}, messages)
+	})
+
+	t.Run("unfair", func(t *testing.T) {
+		gameMap := newValidationTestMap()
+		gameMap.SnakePositions["snake-2"] = rules.Point{X: 0, Y: 0}
+		issues := validator.validateGame(gameMap, 7, 7, 2, 1)
+		require.Len(t, issues, 1)
+		require.Equal(t, "unfair start positions: snake snake-2 can reach 1 squares first, but snake snake-1 can reach 36", issues[0].Message)
+	})
+}
+
+func TestCheckStartFairness(t *testing.T) {
+	state := rules.NewBoardState(5, 1)
+	state.Snakes = []rules.Snake{
+		{ID: "one", Body: []rules.Point{{X: 0, Y: 0}}},
+		{ID: "two", Body: []rules.Point{{X: 4, Y: 0}}},
+	}
+	// The middle square is equally close to both snakes, so it doesn't count
+	require.Empty(t, checkStartFairness(state, 1))
+
+	state.Hazards = []rules.Point{{X: 1, Y: 0}}
+	require.Equal(t, "unfair start positions: snake one can reach 1 squares first, but snake two can reach 3", checkStartFairness(state, 0.5))
+	require.Empty(t, checkStartFairness(state, 0.3))
+
+	state.Snakes[1].Body[0] = rules.Point{X: 0, Y: 0}
+	require.Equal(t, "snakes one and two start on the same square (0,0)", checkStartFairness(state, 0.5))
+
+	// Bots block squares, but are not compared with the players
+	state = rules.NewBoardState(7, 1)
+	state.Snakes = []rules.Snake{
+		{ID: "one", Body: []rules.Point{{X: 0, Y: 0}}},
+		{ID: "bot", Body: []rules.Point{{X: 3, Y: 0}}},
+		{ID: "two", Body: []rules.Point{{X: 6, Y: 0}}},
+	}
+	require.Equal(t, "unfair start positions: snake one can reach 2 squares first, but snake bot can reach 3", checkStartFairness(state, 1))
+	rules.SetBot(state, "bot", rules.Bot{Policy: maps.BotPolicyPatrol})
+	require.Empty(t, checkStartFairness(state, 1))
+}
@@ -12,11 +12,12 @@ type SnakeRequest struct {
 
 // Game represents the current game state
 type Game struct {
-	ID      string  `json:"id"`
-	Ruleset Ruleset `json:"ruleset"`
-	Map     string  `json:"map"`
-	Timeout int     `json:"timeout"`
-	Source  string  `json:"source"`
+	ID         string  `json:"id"`
+	Ruleset    Ruleset `json:"ruleset"`
+	Map        string  `json:"map"`
+	MapVersion int     `json:"mapVersion,omitempty"`
+	Timeout    int     `json:"timeout"`
+	Source     string  `json:"source"`
 }
 
 // Board provides information about the game board
@@ -26,6 +27,14 @@ type Board struct {
 	Snakes  []Snake `json:"snakes"`
 	Food    []Coord `json:"food"`
 	Hazards []Coord `json:"hazards"`
+	Zones   []Zone  `json:"zones,omitempty"`
+}
+
+// Zone is an area of the board that snakes score points for controlling, on maps with control zones
+type Zone struct {
+	ID         int     `json:"id"`
+	Cells      []Coord `json:"cells"`
+	Controller string  `json:"controller"`
 }
 
 // Snake represents information about a snake in the game
@@ -39,6 +48,7 @@ type Snake struct {
 	Length         int            `json:"length"`
 	Shout          string         `json:"shout"`
 	Squad          string         `json:"squad"`
+	Score          int            `json:"score,omitempty"`
 	Customizations Customizations `json:"customizations"`
 }
 
@@ -0,0 +1,191 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/Pikle2/rules"
+)
+
+// Settings that choose what each snake can see of the board.
+const (
+	ParamVisibility       = "visibility"
+	ParamVisibilityRadius = "visibilityRadius"
+)
+
+// Visibility modes, see Visibility.
+const (
+	VisibilityFull        = "full"        // snakes see the whole board
+	VisibilityRadius      = "radius"      // snakes see squares within a number of moves of their head
+	VisibilityLineOfSight = "lineOfSight" // snakes see squares that aren't hidden behind walls
+)
+
+const defaultVisibilityRadius = 5
+
+// Visibility decides which squares of the board each snake can see, for games with hidden information.
+type Visibility struct {
+	Mode string
+	// Radius is the furthest a snake can see, in moves from its head. Line of sight is only limited by walls if Radius is 0.
+	Radius int
+	// HazardDamage is the health lost for each hazard on a square. Squares with enough hazards stacked on them to
+	// eliminate a snake at full health are walls, which block line of sight.
+	HazardDamage int
+}
+
+// VisibilityFromSettings returns the visibility chosen with the visibility and visibilityRadius settings.
+// Snakes see the whole board if visibility isn't set.
+func VisibilityFromSettings(settings rules.Settings) (Visibility, error) {
+	v := Visibility{
+		Mode:         settings.Params()[ParamVisibility],
+		HazardDamage: settings.Int(rules.ParamHazardDamagePerTurn, 0),
+	}
+	switch v.Mode {
+	case "", VisibilityFull:
+		v.Mode = VisibilityFull
+	case VisibilityRadius:
+		v.Radius = settings.Int(ParamVisibilityRadius, defaultVisibilityRadius)
+		if v.Radius < 1 {
+			return v, rules.RulesetError(fmt.Sprintf("%s must be at least 1", ParamVisibilityRadius))
+		}
+	case VisibilityLineOfSight:
+		v.Radius = settings.Int(ParamVisibilityRadius, 0)
+		if v.Radius < 0 {
+			return v, rules.RulesetError(fmt.Sprintf("%s can't be negative", ParamVisibilityRadius))
+		}
+	default:
+		return v, rules.RulesetError(fmt.Sprintf("unknown visibility '%s', expected one of: %s, %s, %s", v.Mode, VisibilityFull, VisibilityRadius, VisibilityLineOfSight))
+	}
+	return v, nil
+}
+
+// VisibleSquares returns the squares of the board that can be seen from a square.
+// Every square is visible with full visibility.
+func (v Visibility) VisibleSquares(board Board, from Coord) map[Coord]bool {
+	walls := v.walls(board)
+	visible := make(map[Coord]bool)
+	for x := 0; x < board.Width; x++ {
+		for y := 0; y < board.Height; y++ {
+			to := Coord{X: x, Y: y}
+			if v.canSee(from, to, walls) {
+				visible[to] = true
+			}
+		}
+	}
+	return visible
+}
+
+func (v Visibility) canSee(from, to Coord, walls map[Coord]bool) bool {
+	switch v.Mode {
+	case VisibilityRadius:
+		return distance(from, to) <= v.Radius
+	case VisibilityLineOfSight:
+		if v.Radius > 0 && distance(from, to) > v.Radius {
+			return false
+		}
+		// Walls can be seen, but not the squares behind them
+		line := lineBetween(from, to)
+		if len(line) <= 2 {
+			return true
+		}
+		for _, p := range line[1 : len(line)-1] {
+			if walls[p] {
+				return false
+			}
+		}
+		return true
+	}
+	return true
+}
+
+// walls returns the squares with enough hazards stacked on them to eliminate a snake at full health.
+func (v Visibility) walls(board Board) map[Coord]bool {
+	walls := map[Coord]bool{}
+	if v.Mode != VisibilityLineOfSight || v.HazardDamage <= 0 {
+		return walls
+	}
+	stacked := map[Coord]int{}
+	for _, p := range board.Hazards {
+		stacked[p]++
+		if stacked[p]*v.HazardDamage >= rules.SnakeMaxHealth {
+			walls[p] = true
+		}
+	}
+	return walls
+}
+
+// FilterBoard returns the board as seen by a snake. Food, hazards and other snakes' bodies are only included
+// on squares the snake can see, and snakes whose head can't be seen are left out, so Head is always a real head.
+// Other snakes that are partly hidden only include the segments that can be seen, starting with the head,
+// and keep their real Length.
+// The snake itself, and control zones, are always included.
+func FilterBoard(board Board, you Snake, v Visibility) Board {
+	if v.Mode == "" || v.Mode == VisibilityFull {
+		return board
+	}
+	visible := v.VisibleSquares(board, you.Head)
+
+	filtered := board
+	filtered.Food = filterCoords(board.Food, visible)
+	filtered.Hazards = filterCoords(board.Hazards, visible)
+	filtered.Snakes = make([]Snake, 0, len(board.Snakes))
+	for _, snake := range board.Snakes {
+		if snake.ID == you.ID {
+			filtered.Snakes = append(filtered.Snakes, snake)
+			continue
+		}
+		if !visible[snake.Head] {
+			continue
+		}
+		snake.Body = filterCoords(snake.Body, visible)
+		filtered.Snakes = append(filtered.Snakes, snake)
+	}
+	return filtered
+}
+
+func filterCoords(coords []Coord, visible map[Coord]bool) []Coord {
+	filtered := make([]Coord, 0, len(coords))
+	for _, p := range coords {
+		if visible[p] {
+			filtered = append(filtered, p)
+		}
+	}
+	return filtered
+}
+
+func distance(a, b Coord) int {
+	return abs(a.X-b.X) + abs(a.Y-b.Y)
+}
+
+func abs(a int) int {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+// lineBetween returns the squares on the line from one square to another, including both ends.
+func lineBetween(from, to Coord) []Coord {
+	dx, dy := abs(to.X-from.X), -abs(to.Y-from.Y)
+	sx, sy := 1, 1
+	if from.X > to.X {
+		sx = -1
+	}
+	if from.Y > to.Y {
+		sy = -1
+	}
+
+	line := []Coord{from}
+	p, e := from, dx+dy
+	for p != to {
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			p.X += sx
+		}
+		if e2 <= dx {
+			e += dx
+			p.Y += sy
+		}
+		line = append(line, p)
+	}
+	return line
+}
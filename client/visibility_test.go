@@ -0,0 +1,91 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/Pikle2/rules"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVisibilityFromSettings(t *testing.T) {
+	v, err := VisibilityFromSettings(rules.Settings{})
+	require.NoError(t, err)
+	require.Equal(t, Visibility{Mode: VisibilityFull}, v)
+
+	v, err = VisibilityFromSettings(rules.NewSettingsWithParams(ParamVisibility, VisibilityRadius))
+	require.NoError(t, err)
+	require.Equal(t, Visibility{Mode: VisibilityRadius, Radius: 5}, v)
+
+	v, err = VisibilityFromSettings(rules.NewSettingsWithParams(ParamVisibility, VisibilityLineOfSight, rules.ParamHazardDamagePerTurn, "50"))
+	require.NoError(t, err)
+	require.Equal(t, Visibility{Mode: VisibilityLineOfSight, HazardDamage: 50}, v)
+
+	_, err = VisibilityFromSettings(rules.NewSettingsWithParams(ParamVisibility, VisibilityRadius, ParamVisibilityRadius, "0"))
+	require.EqualError(t, err, "visibilityRadius must be at least 1")
+	_, err = VisibilityFromSettings(rules.NewSettingsWithParams(ParamVisibility, "xray"))
+	require.EqualError(t, err, "unknown visibility 'xray', expected one of: full, radius, lineOfSight")
+}
+
+func TestFilterBoardRadius(t *testing.T) {
+	you := Snake{ID: "you", Head: Coord{X: 1, Y: 1}, Body: []Coord{{X: 1, Y: 1}, {X: 0, Y: 1}, {X: 0, Y: 0}}, Length: 3}
+	near := Snake{ID: "near", Head: Coord{X: 5, Y: 1}, Body: []Coord{{X: 5, Y: 1}, {X: 4, Y: 1}, {X: 3, Y: 1}}, Length: 3}
+	far := Snake{ID: "far", Head: Coord{X: 9, Y: 9}, Body: []Coord{{X: 9, Y: 9}, {X: 9, Y: 8}, {X: 9, Y: 7}}, Length: 3}
+	tail := Snake{ID: "tail", Head: Coord{X: 3, Y: 2}, Body: []Coord{{X: 3, Y: 2}, {X: 4, Y: 2}, {X: 5, Y: 2}}, Length: 3}
+	board := Board{
+		Width:   11,
+		Height:  11,
+		Snakes:  []Snake{you, near, far, tail},
+		Food:    []Coord{{X: 2, Y: 2}, {X: 8, Y: 8}},
+		Hazards: []Coord{{X: 1, Y: 4}, {X: 1, Y: 5}},
+		Zones:   []Zone{{ID: 1, Cells: []Coord{{X: 5, Y: 5}}}},
+	}
+
+	filtered := FilterBoard(board, you, Visibility{Mode: VisibilityRadius, Radius: 3})
+	require.Equal(t, []Coord{{X: 2, Y: 2}}, filtered.Food)
+	require.Equal(t, []Coord{{X: 1, Y: 4}}, filtered.Hazards)
+	require.Equal(t, board.Zones, filtered.Zones)
+
+	// The near snake's head is out of sight, so it's left out even though some of its body can be seen.
+	// Only the visible segments of the tail snake are included, but it keeps its real length.
+	require.Equal(t, []Snake{
+		you,
+		{ID: "tail", Head: Coord{X: 3, Y: 2}, Body: []Coord{{X: 3, Y: 2}}, Length: 3},
+	}, filtered.Snakes)
+
+	// The original board isn't changed, and full visibility sees everything
+	require.Len(t, board.Snakes, 4)
+	require.Equal(t, near, board.Snakes[1])
+	require.Equal(t, board, FilterBoard(board, you, Visibility{Mode: VisibilityFull}))
+	require.Equal(t, board, FilterBoard(board, you, Visibility{}))
+}
+
+func TestFilterBoardLineOfSight(t *testing.T) {
+	you := Snake{ID: "you", Head: Coord{X: 1, Y: 5}, Body: []Coord{{X: 1, Y: 5}}, Length: 1}
+	board := Board{
+		Width:  11,
+		Height: 11,
+		Snakes: []Snake{you},
+		// A wall of hazards stacked twice, and a single hazard that isn't a wall
+		Hazards: []Coord{{X: 3, Y: 4}, {X: 3, Y: 4}, {X: 3, Y: 5}, {X: 3, Y: 5}, {X: 3, Y: 6}, {X: 3, Y: 6}, {X: 6, Y: 0}},
+		Food:    []Coord{{X: 5, Y: 5}, {X: 2, Y: 5}, {X: 1, Y: 9}, {X: 5, Y: 0}},
+	}
+
+	filtered := FilterBoard(board, you, Visibility{Mode: VisibilityLineOfSight, HazardDamage: 50})
+	require.Equal(t, []Coord{{X: 2, Y: 5}, {X: 1, Y: 9}, {X: 5, Y: 0}}, filtered.Food)
+	require.Equal(t, board.Hazards, filtered.Hazards)
+
+	// Without enough damage to eliminate a snake, stacked hazards aren't walls
+	filtered = FilterBoard(board, you, Visibility{Mode: VisibilityLineOfSight, HazardDamage: 14})
+	require.Equal(t, board.Food, filtered.Food)
+
+	// Line of sight can also be limited by distance
+	filtered = FilterBoard(board, you, Visibility{Mode: VisibilityLineOfSight, Radius: 4, HazardDamage: 50})
+	require.Equal(t, []Coord{{X: 2, Y: 5}, {X: 1, Y: 9}}, filtered.Food)
+}
+
+func TestLineBetween(t *testing.T) {
+	require.Equal(t, []Coord{{X: 1, Y: 1}}, lineBetween(Coord{X: 1, Y: 1}, Coord{X: 1, Y: 1}))
+	require.Equal(t, []Coord{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 2, Y: 0}}, lineBetween(Coord{X: 0, Y: 0}, Coord{X: 2, Y: 0}))
+	require.Equal(t, []Coord{{X: 2, Y: 2}, {X: 1, Y: 1}, {X: 0, Y: 0}}, lineBetween(Coord{X: 2, Y: 2}, Coord{X: 0, Y: 0}))
+	require.Equal(t, []Coord{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 2, Y: 1}, {X: 3, Y: 1}}, lineBetween(Coord{X: 0, Y: 0}, Coord{X: 3, Y: 1}))
+}
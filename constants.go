@@ -27,6 +27,7 @@ const (
 	EliminatedByHeadToHeadCollision = "head-collision"
 	EliminatedByOutOfBounds         = "wall-collision"
 	EliminatedByHazard              = "hazard"
+	EliminatedByScoreLimit          = "score-limit"
 
 	// Error constants
 	ErrorTooManySnakes   = RulesetError("too many snakes for fixed start positions")
@@ -39,6 +40,10 @@ const (
 	ErrorStageNotFound   = RulesetError("stage not found")
 	ErrorMapNotFound     = RulesetError("map not found")
 
+	ErrorUnsupportedEncodingVersion = RulesetError("unsupported board state encoding version")
+	ErrorDeltaMismatch              = RulesetError("board state doesn't match delta")
+	ErrorHistoryOutOfRange          = RulesetError("game history position out of range")
+
 	// Ruleset / game type names
 	GameTypeConstrictor        = "constrictor"
 	GameTypeRoyale             = "royale"
@@ -55,6 +60,8 @@ const (
 	ParamHazardMap           = "hazardMap"
 	ParamHazardMapAuthor     = "hazardMapAuthor"
 	ParamShrinkEveryNTurns   = "shrinkEveryNTurns"
+	ParamShrinkPattern       = "shrinkPattern"
+	ParamShrinkFinalCells    = "shrinkFinalCells"
 	ParamAllowBodyCollisions = "allowBodyCollisions"
 	ParamSharedElimination   = "sharedElimination"
 	ParamSharedHealth        = "sharedHealth"
@@ -0,0 +1,468 @@
+package rules
+
+import (
+	"fmt"
+	"sort"
+)
+
+// maxPointsDiffCells limits the size of the table used to find the smallest set of changes between
+// two point lists. Larger changes are recorded as a single replacement, which is still correct but
+// less compact.
+const maxPointsDiffCells = 1 << 20
+
+// IntChange records the previous and next value of an integer field.
+type IntChange struct {
+	Old int
+	New int
+}
+
+// StringChange records the previous and next value of a string field.
+type StringChange struct {
+	Old string
+	New string
+}
+
+// PointsHunk replaces a run of points in a list.
+// Index is the position of the run in the list the hunk is applied to.
+type PointsHunk struct {
+	Index   int
+	Removed []Point `json:",omitempty"`
+	Added   []Point `json:",omitempty"`
+}
+
+// PointsDelta is an ordered list of non-overlapping hunks that transforms one point list into another.
+type PointsDelta []PointsHunk
+
+// SnakeDelta records the changes to a single snake, identified by its index in BoardState.Snakes.
+type SnakeDelta struct {
+	Index            int
+	Body             PointsDelta   `json:",omitempty"`
+	Health           *IntChange    `json:",omitempty"`
+	EliminatedCause  *StringChange `json:",omitempty"`
+	EliminatedOnTurn *IntChange    `json:",omitempty"`
+	EliminatedBy     *StringChange `json:",omitempty"`
+}
+
+// SnakesChange replaces the whole snake list. It is used when snakes are added, removed or reordered.
+type SnakesChange struct {
+	Old []Snake
+	New []Snake
+}
+
+// GameStateChange records a change to a single GameState key. A nil value means the key is not set.
+type GameStateChange struct {
+	Key string
+	Old *string `json:",omitempty"`
+	New *string `json:",omitempty"`
+}
+
+// PointStateChange records a change to a single PointState entry. A nil value means the point is not set.
+type PointStateChange struct {
+	Point Point
+	Old   *int `json:",omitempty"`
+	New   *int `json:",omitempty"`
+}
+
+// BoardStateDelta captures the changes between two BoardStates.
+// A delta can be applied to the first state to produce the second, or reverted on the second state
+// to produce the first. Fields that didn't change are left empty, so deltas between consecutive turns
+// are much smaller than the states themselves.
+type BoardStateDelta struct {
+	Turn       *IntChange         `json:",omitempty"`
+	Height     *IntChange         `json:",omitempty"`
+	Width      *IntChange         `json:",omitempty"`
+	Food       PointsDelta        `json:",omitempty"`
+	Hazards    PointsDelta        `json:",omitempty"`
+	Snakes     []SnakeDelta       `json:",omitempty"`
+	AllSnakes  *SnakesChange      `json:",omitempty"`
+	GameState  []GameStateChange  `json:",omitempty"`
+	PointState []PointStateChange `json:",omitempty"`
+}
+
+// DiffBoardStates returns the delta that transforms from into to.
+// The delta doesn't share any memory with either state.
+func DiffBoardStates(from, to *BoardState) *BoardStateDelta {
+	delta := &BoardStateDelta{
+		Turn:    diffInts(from.Turn, to.Turn),
+		Height:  diffInts(from.Height, to.Height),
+		Width:   diffInts(from.Width, to.Width),
+		Food:    diffPoints(from.Food, to.Food),
+		Hazards: diffPoints(from.Hazards, to.Hazards),
+	}
+
+	if sameSnakeIDs(from.Snakes, to.Snakes) {
+		for i := range from.Snakes {
+			snakeDelta := SnakeDelta{
+				Index:            i,
+				Body:             diffPoints(from.Snakes[i].Body, to.Snakes[i].Body),
+				Health:           diffInts(from.Snakes[i].Health, to.Snakes[i].Health),
+				EliminatedCause:  diffStrings(from.Snakes[i].EliminatedCause, to.Snakes[i].EliminatedCause),
+				EliminatedOnTurn: diffInts(from.Snakes[i].EliminatedOnTurn, to.Snakes[i].EliminatedOnTurn),
+				EliminatedBy:     diffStrings(from.Snakes[i].EliminatedBy, to.Snakes[i].EliminatedBy),
+			}
+			if !snakeDelta.isEmpty() {
+				delta.Snakes = append(delta.Snakes, snakeDelta)
+			}
+		}
+	} else {
+		delta.AllSnakes = &SnakesChange{Old: copySnakes(from.Snakes), New: copySnakes(to.Snakes)}
+	}
+
+	for key := range unionKeys(from.GameState, to.GameState) {
+		oldValue, oldOK := from.GameState[key]
+		newValue, newOK := to.GameState[key]
+		if oldOK == newOK && oldValue == newValue {
+			continue
+		}
+		change := GameStateChange{Key: key}
+		if oldOK {
+			change.Old = &oldValue
+		}
+		if newOK {
+			change.New = &newValue
+		}
+		delta.GameState = append(delta.GameState, change)
+	}
+	sort.Slice(delta.GameState, func(i, j int) bool { return delta.GameState[i].Key < delta.GameState[j].Key })
+
+	for p := range from.PointState {
+		if _, ok := to.PointState[p]; !ok {
+			oldValue := from.PointState[p]
+			delta.PointState = append(delta.PointState, PointStateChange{Point: p, Old: &oldValue})
+		}
+	}
+	for p, newValue := range to.PointState {
+		newValue := newValue
+		oldValue, ok := from.PointState[p]
+		if !ok {
+			delta.PointState = append(delta.PointState, PointStateChange{Point: p, New: &newValue})
+		} else if oldValue != newValue {
+			delta.PointState = append(delta.PointState, PointStateChange{Point: p, Old: &oldValue, New: &newValue})
+		}
+	}
+	sort.Slice(delta.PointState, func(i, j int) bool {
+		return pointLess(delta.PointState[i].Point, delta.PointState[j].Point)
+	})
+
+	return delta
+}
+
+// IsEmpty returns true if the delta doesn't change anything.
+func (delta *BoardStateDelta) IsEmpty() bool {
+	return delta.Turn == nil && delta.Height == nil && delta.Width == nil &&
+		len(delta.Food) == 0 && len(delta.Hazards) == 0 &&
+		len(delta.Snakes) == 0 && delta.AllSnakes == nil &&
+		len(delta.GameState) == 0 && len(delta.PointState) == 0
+}
+
+// Apply returns a copy of state with the delta applied.
+// It returns an error wrapping ErrorDeltaMismatch if state isn't the state the delta was created from.
+func (delta *BoardStateDelta) Apply(state *BoardState) (*BoardState, error) {
+	next := state.Clone()
+	var err error
+
+	if err = applyInt("Turn", &next.Turn, delta.Turn); err != nil {
+		return nil, err
+	}
+	if err = applyInt("Height", &next.Height, delta.Height); err != nil {
+		return nil, err
+	}
+	if err = applyInt("Width", &next.Width, delta.Width); err != nil {
+		return nil, err
+	}
+	if next.Food, err = delta.Food.apply("Food", next.Food); err != nil {
+		return nil, err
+	}
+	if next.Hazards, err = delta.Hazards.apply("Hazards", next.Hazards); err != nil {
+		return nil, err
+	}
+
+	if delta.AllSnakes != nil {
+		if !sameSnakeIDs(delta.AllSnakes.Old, next.Snakes) {
+			return nil, fmt.Errorf("%w: Snakes", ErrorDeltaMismatch)
+		}
+		next.Snakes = copySnakes(delta.AllSnakes.New)
+	}
+	for _, snakeDelta := range delta.Snakes {
+		if snakeDelta.Index < 0 || snakeDelta.Index >= len(next.Snakes) {
+			return nil, fmt.Errorf("%w: Snakes[%d] doesn't exist", ErrorDeltaMismatch, snakeDelta.Index)
+		}
+		snake := &next.Snakes[snakeDelta.Index]
+		field := fmt.Sprintf("Snakes[%d]", snakeDelta.Index)
+		if snake.Body, err = snakeDelta.Body.apply(field+".Body", snake.Body); err != nil {
+			return nil, err
+		}
+		if err = applyInt(field+".Health", &snake.Health, snakeDelta.Health); err != nil {
+			return nil, err
+		}
+		if err = applyString(field+".EliminatedCause", &snake.EliminatedCause, snakeDelta.EliminatedCause); err != nil {
+			return nil, err
+		}
+		if err = applyInt(field+".EliminatedOnTurn", &snake.EliminatedOnTurn, snakeDelta.EliminatedOnTurn); err != nil {
+			return nil, err
+		}
+		if err = applyString(field+".EliminatedBy", &snake.EliminatedBy, snakeDelta.EliminatedBy); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, change := range delta.GameState {
+		value, ok := next.GameState[change.Key]
+		if ok != (change.Old != nil) || (ok && value != *change.Old) {
+			return nil, fmt.Errorf("%w: GameState[%q]", ErrorDeltaMismatch, change.Key)
+		}
+		if change.New == nil {
+			delete(next.GameState, change.Key)
+		} else {
+			next.GameState[change.Key] = *change.New
+		}
+	}
+
+	for _, change := range delta.PointState {
+		value, ok := next.PointState[change.Point]
+		if ok != (change.Old != nil) || (ok && value != *change.Old) {
+			return nil, fmt.Errorf("%w: PointState[%#v]", ErrorDeltaMismatch, change.Point)
+		}
+		if change.New == nil {
+			delete(next.PointState, change.Point)
+		} else {
+			next.PointState[change.Point] = *change.New
+		}
+	}
+
+	return next, nil
+}
+
+// Revert returns a copy of state with the delta undone, i.e. the state the delta was created from.
+// It returns an error wrapping ErrorDeltaMismatch if state isn't the state the delta produces.
+func (delta *BoardStateDelta) Revert(state *BoardState) (*BoardState, error) {
+	return delta.Invert().Apply(state)
+}
+
+// Invert returns a delta that undoes this one.
+func (delta *BoardStateDelta) Invert() *BoardStateDelta {
+	inverse := &BoardStateDelta{
+		Turn:    delta.Turn.invert(),
+		Height:  delta.Height.invert(),
+		Width:   delta.Width.invert(),
+		Food:    delta.Food.invert(),
+		Hazards: delta.Hazards.invert(),
+	}
+	for _, snakeDelta := range delta.Snakes {
+		inverse.Snakes = append(inverse.Snakes, SnakeDelta{
+			Index:            snakeDelta.Index,
+			Body:             snakeDelta.Body.invert(),
+			Health:           snakeDelta.Health.invert(),
+			EliminatedCause:  snakeDelta.EliminatedCause.invert(),
+			EliminatedOnTurn: snakeDelta.EliminatedOnTurn.invert(),
+			EliminatedBy:     snakeDelta.EliminatedBy.invert(),
+		})
+	}
+	if delta.AllSnakes != nil {
+		inverse.AllSnakes = &SnakesChange{Old: delta.AllSnakes.New, New: delta.AllSnakes.Old}
+	}
+	for _, change := range delta.GameState {
+		inverse.GameState = append(inverse.GameState, GameStateChange{Key: change.Key, Old: change.New, New: change.Old})
+	}
+	for _, change := range delta.PointState {
+		inverse.PointState = append(inverse.PointState, PointStateChange{Point: change.Point, Old: change.New, New: change.Old})
+	}
+	return inverse
+}
+
+func (delta SnakeDelta) isEmpty() bool {
+	return len(delta.Body) == 0 && delta.Health == nil && delta.EliminatedCause == nil &&
+		delta.EliminatedOnTurn == nil && delta.EliminatedBy == nil
+}
+
+func (c *IntChange) invert() *IntChange {
+	if c == nil {
+		return nil
+	}
+	return &IntChange{Old: c.New, New: c.Old}
+}
+
+func (c *StringChange) invert() *StringChange {
+	if c == nil {
+		return nil
+	}
+	return &StringChange{Old: c.New, New: c.Old}
+}
+
+func diffInts(from, to int) *IntChange {
+	if from == to {
+		return nil
+	}
+	return &IntChange{Old: from, New: to}
+}
+
+func diffStrings(from, to string) *StringChange {
+	if from == to {
+		return nil
+	}
+	return &StringChange{Old: from, New: to}
+}
+
+func applyInt(field string, value *int, change *IntChange) error {
+	if change == nil {
+		return nil
+	}
+	if *value != change.Old {
+		return fmt.Errorf("%w: %s is %d, expected %d", ErrorDeltaMismatch, field, *value, change.Old)
+	}
+	*value = change.New
+	return nil
+}
+
+func applyString(field string, value *string, change *StringChange) error {
+	if change == nil {
+		return nil
+	}
+	if *value != change.Old {
+		return fmt.Errorf("%w: %s is %q, expected %q", ErrorDeltaMismatch, field, *value, change.Old)
+	}
+	*value = change.New
+	return nil
+}
+
+// diffPoints finds a small set of hunks that transforms from into to.
+// Common leading and trailing points are skipped, and the remainder is compared using the longest
+// common subsequence so that e.g. a moving snake is recorded as a new head and a removed tail.
+func diffPoints(from, to []Point) PointsDelta {
+	prefix := 0
+	for prefix < len(from) && prefix < len(to) && from[prefix] == to[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(from)-prefix && suffix < len(to)-prefix && from[len(from)-1-suffix] == to[len(to)-1-suffix] {
+		suffix++
+	}
+	a := from[prefix : len(from)-suffix]
+	b := to[prefix : len(to)-suffix]
+
+	if len(a) == 0 && len(b) == 0 {
+		return nil
+	}
+	if len(a) == 0 || len(b) == 0 || len(a)*len(b) > maxPointsDiffCells {
+		return PointsDelta{{Index: prefix, Removed: copyPoints(a), Added: copyPoints(b)}}
+	}
+
+	// lcs[i*(m+1)+j] is the length of the longest common subsequence of a[i:] and b[j:]
+	n, m := len(a), len(b)
+	lcs := make([]int, (n+1)*(m+1))
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i*(m+1)+j] = lcs[(i+1)*(m+1)+j+1] + 1
+			} else if lcs[(i+1)*(m+1)+j] >= lcs[i*(m+1)+j+1] {
+				lcs[i*(m+1)+j] = lcs[(i+1)*(m+1)+j]
+			} else {
+				lcs[i*(m+1)+j] = lcs[i*(m+1)+j+1]
+			}
+		}
+	}
+
+	var delta PointsDelta
+	var hunk *PointsHunk
+	i, j := 0, 0
+	for i < n || j < m {
+		if i < n && j < m && a[i] == b[j] {
+			if hunk != nil {
+				delta = append(delta, *hunk)
+				hunk = nil
+			}
+			i++
+			j++
+			continue
+		}
+		if hunk == nil {
+			hunk = &PointsHunk{Index: prefix + i}
+		}
+		if j < m && (i == n || lcs[i*(m+1)+j+1] >= lcs[(i+1)*(m+1)+j]) {
+			hunk.Added = append(hunk.Added, b[j])
+			j++
+		} else {
+			hunk.Removed = append(hunk.Removed, a[i])
+			i++
+		}
+	}
+	if hunk != nil {
+		delta = append(delta, *hunk)
+	}
+	return delta
+}
+
+func (delta PointsDelta) apply(field string, points []Point) ([]Point, error) {
+	if len(delta) == 0 {
+		return points, nil
+	}
+
+	result := make([]Point, 0, len(points))
+	pos := 0
+	for _, hunk := range delta {
+		if hunk.Index < pos || hunk.Index+len(hunk.Removed) > len(points) {
+			return nil, fmt.Errorf("%w: %s has %d points, which doesn't fit a change at %d", ErrorDeltaMismatch, field, len(points), hunk.Index)
+		}
+		for k, p := range hunk.Removed {
+			if points[hunk.Index+k] != p {
+				return nil, fmt.Errorf("%w: %s[%d] is %#v, expected %#v", ErrorDeltaMismatch, field, hunk.Index+k, points[hunk.Index+k], p)
+			}
+		}
+		result = append(result, points[pos:hunk.Index]...)
+		result = append(result, hunk.Added...)
+		pos = hunk.Index + len(hunk.Removed)
+	}
+	return append(result, points[pos:]...), nil
+}
+
+func (delta PointsDelta) invert() PointsDelta {
+	if delta == nil {
+		return nil
+	}
+	inverse := make(PointsDelta, 0, len(delta))
+	offset := 0
+	for _, hunk := range delta {
+		inverse = append(inverse, PointsHunk{Index: hunk.Index + offset, Removed: hunk.Added, Added: hunk.Removed})
+		offset += len(hunk.Added) - len(hunk.Removed)
+	}
+	return inverse
+}
+
+func sameSnakeIDs(a, b []Snake) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].ID != b[i].ID {
+			return false
+		}
+	}
+	return true
+}
+
+func copySnakes(snakes []Snake) []Snake {
+	copied := make([]Snake, len(snakes))
+	for i, snake := range snakes {
+		copied[i] = snake
+		copied[i].Body = append([]Point{}, snake.Body...)
+	}
+	return copied
+}
+
+func copyPoints(points []Point) []Point {
+	if len(points) == 0 {
+		return nil
+	}
+	return append([]Point{}, points...)
+}
+
+func unionKeys(a, b map[string]string) map[string]struct{} {
+	keys := make(map[string]struct{}, len(a)+len(b))
+	for key := range a {
+		keys[key] = struct{}{}
+	}
+	for key := range b {
+		keys[key] = struct{}{}
+	}
+	return keys
+}
@@ -0,0 +1,177 @@
+package rules
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffPoints(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to []Point
+		expected PointsDelta
+	}{
+		{"unchanged", []Point{{X: 1, Y: 1}}, []Point{{X: 1, Y: 1}}, nil},
+		{"both empty", []Point{}, nil, nil},
+		{
+			"append",
+			[]Point{{X: 1, Y: 1}},
+			[]Point{{X: 1, Y: 1}, {X: 2, Y: 2}},
+			PointsDelta{{Index: 1, Added: []Point{{X: 2, Y: 2}}}},
+		},
+		{
+			"remove from middle",
+			[]Point{{X: 1, Y: 1}, {X: 2, Y: 2}, {X: 3, Y: 3}},
+			[]Point{{X: 1, Y: 1}, {X: 3, Y: 3}},
+			PointsDelta{{Index: 1, Removed: []Point{{X: 2, Y: 2}}}},
+		},
+		{
+			"snake moves",
+			[]Point{{X: 2, Y: 2}, {X: 2, Y: 1}, {X: 2, Y: 0}},
+			[]Point{{X: 2, Y: 3}, {X: 2, Y: 2}, {X: 2, Y: 1}},
+			PointsDelta{
+				{Index: 0, Added: []Point{{X: 2, Y: 3}}},
+				{Index: 2, Removed: []Point{{X: 2, Y: 0}}},
+			},
+		},
+		{
+			"replace",
+			[]Point{{X: 1, Y: 1}, {X: 2, Y: 2}},
+			[]Point{{X: 1, Y: 1}, {X: 5, Y: 5, TTL: 1}},
+			PointsDelta{{Index: 1, Removed: []Point{{X: 2, Y: 2}}, Added: []Point{{X: 5, Y: 5, TTL: 1}}}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			delta := diffPoints(test.from, test.to)
+			require.Equal(t, test.expected, delta)
+
+			applied, err := delta.apply("Points", test.from)
+			require.NoError(t, err)
+			require.Equal(t, len(test.to), len(applied))
+			if len(test.to) > 0 {
+				require.Equal(t, test.to, applied)
+			}
+
+			reverted, err := delta.invert().apply("Points", applied)
+			require.NoError(t, err)
+			require.Equal(t, len(test.from), len(reverted))
+			if len(test.from) > 0 {
+				require.Equal(t, test.from, reverted)
+			}
+		})
+	}
+}
+
+func TestBoardStateDeltaApplyAndRevert(t *testing.T) {
+	from := NewBoardState(11, 11).
+		WithTurn(4).
+		WithFood([]Point{{X: 5, Y: 5}, {X: 8, Y: 8}}).
+		WithHazards([]Point{{X: 0, Y: 0}}).
+		WithSnakes([]Snake{
+			{ID: "one", Health: 90, Body: []Point{{X: 4, Y: 5}, {X: 3, Y: 5}, {X: 2, Y: 5}}},
+			{ID: "two", Health: 60, Body: []Point{{X: 1, Y: 1}, {X: 1, Y: 2}, {X: 1, Y: 3}}},
+		}).
+		WithGameState(map[string]string{"kept": "1", "changed": "a", "removed": "x"}).
+		WithPointState(map[Point]int{{X: 1, Y: 1}: 1, {X: 2, Y: 2}: 2})
+
+	to := NewBoardState(11, 11).
+		WithTurn(5).
+		WithFood([]Point{{X: 8, Y: 8}, {X: 0, Y: 10}}).
+		WithHazards([]Point{{X: 0, Y: 0}, {X: 0, Y: 1}}).
+		WithSnakes([]Snake{
+			{ID: "one", Health: 100, Body: []Point{{X: 5, Y: 5}, {X: 4, Y: 5}, {X: 3, Y: 5}, {X: 3, Y: 5}}},
+			{ID: "two", Health: 59, Body: []Point{{X: 1, Y: 0}, {X: 1, Y: 1}, {X: 1, Y: 2}}, EliminatedCause: EliminatedByHazard, EliminatedOnTurn: 5},
+		}).
+		WithGameState(map[string]string{"kept": "1", "changed": "b", "added": ""}).
+		WithPointState(map[Point]int{{X: 1, Y: 1}: 1, {X: 2, Y: 2}: 3, {X: 3, Y: 3, TTL: 1}: 0})
+
+	delta := DiffBoardStates(from, to)
+	require.False(t, delta.IsEmpty())
+	require.Nil(t, delta.AllSnakes)
+	require.Len(t, delta.Snakes, 2)
+
+	applied, err := delta.Apply(from)
+	require.NoError(t, err)
+	require.Equal(t, to, applied)
+
+	reverted, err := delta.Revert(to)
+	require.NoError(t, err)
+	require.Equal(t, from, reverted)
+
+	// Applying doesn't modify the original states
+	require.Equal(t, 4, from.Turn)
+	require.Equal(t, 5, to.Turn)
+
+	// Deltas survive a JSON round trip
+	data, err := json.Marshal(delta)
+	require.NoError(t, err)
+	decoded := &BoardStateDelta{}
+	require.NoError(t, json.Unmarshal(data, decoded))
+	applied, err = decoded.Apply(from)
+	require.NoError(t, err)
+	require.Equal(t, to, applied)
+
+	require.True(t, DiffBoardStates(from, from.Clone()).IsEmpty())
+}
+
+func TestBoardStateDeltaSnakesChanged(t *testing.T) {
+	from := NewBoardState(5, 5).WithSnakes([]Snake{
+		{ID: "one", Health: 100, Body: []Point{{X: 1, Y: 1}}},
+	})
+	to := NewBoardState(5, 5).WithSnakes([]Snake{
+		{ID: "two", Health: 100, Body: []Point{{X: 2, Y: 2}}},
+		{ID: "one", Health: 100, Body: []Point{{X: 1, Y: 1}}},
+	})
+
+	delta := DiffBoardStates(from, to)
+	require.NotNil(t, delta.AllSnakes)
+
+	applied, err := delta.Apply(from)
+	require.NoError(t, err)
+	require.Equal(t, to, applied)
+
+	reverted, err := delta.Revert(to)
+	require.NoError(t, err)
+	require.Equal(t, from, reverted)
+}
+
+func TestBoardStateDeltaMismatch(t *testing.T) {
+	from := NewBoardState(5, 5).WithTurn(1).WithFood([]Point{{X: 1, Y: 1}})
+	to := NewBoardState(5, 5).WithTurn(2).WithFood([]Point{})
+	delta := DiffBoardStates(from, to)
+
+	_, err := delta.Apply(to)
+	require.ErrorIs(t, err, ErrorDeltaMismatch)
+
+	_, err = delta.Apply(NewBoardState(5, 5).WithTurn(1).WithFood([]Point{{X: 2, Y: 2}}))
+	require.ErrorIs(t, err, ErrorDeltaMismatch)
+
+	_, err = delta.Revert(from)
+	require.ErrorIs(t, err, ErrorDeltaMismatch)
+}
+
+func TestBoardStateDeltaIsSmall(t *testing.T) {
+	state, err := CreateDefaultBoardState(MaxRand, BoardSizeXXLarge, BoardSizeXXLarge, []string{"1", "2", "3", "4", "5", "6", "7", "8"})
+	require.NoError(t, err)
+	for i := 0; i < 100; i++ {
+		state.Hazards = append(state.Hazards, Point{X: i % BoardSizeXXLarge, Y: i / BoardSizeXXLarge})
+	}
+
+	r := NewRulesetBuilder().NamedRuleset(GameTypeStandard)
+	moves := []SnakeMove{}
+	for _, snake := range state.Snakes {
+		moves = append(moves, SnakeMove{ID: snake.ID, Move: MoveUp})
+	}
+	_, next, err := r.Execute(state, moves)
+	require.NoError(t, err)
+
+	stateJSON, err := json.Marshal(next)
+	require.NoError(t, err)
+	deltaJSON, err := json.Marshal(DiffBoardStates(state, next))
+	require.NoError(t, err)
+	require.Less(t, len(deltaJSON)*2, len(stateJSON))
+}
@@ -0,0 +1,435 @@
+package rules
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// BoardStateEncodingVersion is the version of the JSON and binary encodings written by
+// BoardState.MarshalJSON and BoardState.MarshalBinary.
+//
+// It must be incremented whenever the encoded form of a BoardState changes. When it is, register
+// a JSON migration from the previous version with RegisterBoardStateJSONMigration and a decoder for
+// the previous binary layout with RegisterBoardStateBinaryDecoder, so that checkpoints written by
+// older releases can still be read.
+//
+// Version 1 JSON encoding:
+//
+//	{
+//	  "Version": 1,
+//	  "Turn": 3,
+//	  "Height": 11,
+//	  "Width": 11,
+//	  "Food": [{"X": 5, "Y": 5}],
+//	  "Snakes": [{"ID": "one", "Body": [{"X": 1, "Y": 1}], "Health": 100, "EliminatedCause": "", "EliminatedOnTurn": 0, "EliminatedBy": ""}],
+//	  "Hazards": [{"X": 0, "Y": 0, "TTL": 2, "Value": 1}],
+//	  "GameState": {"key": "value"},
+//	  "PointState": [{"Point": {"X": 1, "Y": 2}, "State": 5}]
+//	}
+//
+// Version 1 binary encoding, where "varint" and "uvarint" are the encodings from encoding/binary:
+//
+//	magic      "BSB"
+//	version    uvarint
+//	turn       varint
+//	height     varint
+//	width      varint
+//	food       point list
+//	snakes     list of: ID string, body point list, health varint, eliminated cause string,
+//	           eliminated on turn varint, eliminated by string
+//	hazards    point list
+//	game state list of: key string, value string (sorted by key)
+//	point state list of: point, state varint (sorted by point)
+//
+// A point is four varints (X, Y, TTL, Value), a string is a uvarint length followed by the bytes,
+// and a list is a uvarint count followed by the items. List counts are stored as count+1 so that
+// nil slices and maps can be told apart from empty ones; a stored count of 0 means nil.
+const BoardStateEncodingVersion = 1
+
+// boardStateBinaryMagic prefixes every binary encoded BoardState.
+const boardStateBinaryMagic = "BSB"
+
+// BoardStateJSONMigration upgrades a JSON encoded BoardState in place from the version it
+// was registered for to the next version. The "Version" key is updated by the caller.
+type BoardStateJSONMigration func(doc map[string]json.RawMessage) error
+
+// BoardStateBinaryDecoder decodes the body of a binary encoded BoardState written in an older
+// encoding version. The body is everything following the magic and version header.
+type BoardStateBinaryDecoder func(body []byte) (*BoardState, error)
+
+// jsonMigrations maps an encoding version to the migration that upgrades it to the next version.
+var jsonMigrations = map[int]BoardStateJSONMigration{}
+
+// binaryDecoders maps an encoding version to the decoder for that binary layout.
+var binaryDecoders = map[int]BoardStateBinaryDecoder{
+	BoardStateEncodingVersion: decodeBoardStateBinaryV1,
+}
+
+// RegisterBoardStateJSONMigration registers a migration that upgrades JSON encoded board states
+// from fromVersion to fromVersion+1. Migrations are chained, so a state several versions old will
+// be upgraded one version at a time.
+// It will panic if a migration has already been registered for fromVersion.
+func RegisterBoardStateJSONMigration(fromVersion int, migration BoardStateJSONMigration) {
+	if _, ok := jsonMigrations[fromVersion]; ok {
+		panic(RulesetError(fmt.Sprintf("board state JSON migration from version %d has already been registered", fromVersion)))
+	}
+	jsonMigrations[fromVersion] = migration
+}
+
+// RegisterBoardStateBinaryDecoder registers a decoder for binary encoded board states written
+// in the given encoding version.
+// It will panic if a decoder has already been registered for the version.
+func RegisterBoardStateBinaryDecoder(version int, decoder BoardStateBinaryDecoder) {
+	if _, ok := binaryDecoders[version]; ok {
+		panic(RulesetError(fmt.Sprintf("board state binary decoder for version %d has already been registered", version)))
+	}
+	binaryDecoders[version] = decoder
+}
+
+type boardStateJSON struct {
+	Version    int
+	Turn       int
+	Height     int
+	Width      int
+	Food       []Point
+	Snakes     []Snake
+	Hazards    []Point
+	GameState  map[string]string
+	PointState []pointStateJSON
+}
+
+type pointStateJSON struct {
+	Point Point
+	State int
+}
+
+// MarshalJSON encodes the BoardState using the versioned JSON encoding described by
+// BoardStateEncodingVersion.
+func (state *BoardState) MarshalJSON() ([]byte, error) {
+	doc := boardStateJSON{
+		Version:   BoardStateEncodingVersion,
+		Turn:      state.Turn,
+		Height:    state.Height,
+		Width:     state.Width,
+		Food:      state.Food,
+		Snakes:    state.Snakes,
+		Hazards:   state.Hazards,
+		GameState: state.GameState,
+	}
+	if state.PointState != nil {
+		doc.PointState = make([]pointStateJSON, 0, len(state.PointState))
+		for _, p := range sortedPointStateKeys(state.PointState) {
+			doc.PointState = append(doc.PointState, pointStateJSON{Point: p, State: state.PointState[p]})
+		}
+	}
+	return json.Marshal(doc)
+}
+
+// UnmarshalJSON decodes a BoardState written by MarshalJSON, applying any registered migrations
+// needed to bring older encoding versions up to date.
+func (state *BoardState) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	version := 0
+	if rawVersion, ok := raw["Version"]; ok {
+		if err := json.Unmarshal(rawVersion, &version); err != nil {
+			return fmt.Errorf("invalid board state version: %w", err)
+		}
+	}
+	if version > BoardStateEncodingVersion {
+		return fmt.Errorf("%w: %d is newer than %d", ErrorUnsupportedEncodingVersion, version, BoardStateEncodingVersion)
+	}
+	for ; version < BoardStateEncodingVersion; version++ {
+		migrate, ok := jsonMigrations[version]
+		if !ok {
+			return fmt.Errorf("%w: no JSON migration from version %d", ErrorUnsupportedEncodingVersion, version)
+		}
+		if err := migrate(raw); err != nil {
+			return fmt.Errorf("migrating board state from version %d: %w", version, err)
+		}
+	}
+	raw["Version"] = json.RawMessage(fmt.Sprint(version))
+
+	migrated, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	var doc boardStateJSON
+	if err := json.Unmarshal(migrated, &doc); err != nil {
+		return err
+	}
+
+	*state = BoardState{
+		Turn:      doc.Turn,
+		Height:    doc.Height,
+		Width:     doc.Width,
+		Food:      doc.Food,
+		Snakes:    doc.Snakes,
+		Hazards:   doc.Hazards,
+		GameState: doc.GameState,
+	}
+	if doc.PointState != nil {
+		state.PointState = make(map[Point]int, len(doc.PointState))
+		for _, ps := range doc.PointState {
+			state.PointState[ps.Point] = ps.State
+		}
+	}
+	return nil
+}
+
+// MarshalBinary encodes the BoardState using the compact binary encoding described by
+// BoardStateEncodingVersion.
+func (state *BoardState) MarshalBinary() ([]byte, error) {
+	e := &boardStateEncoder{}
+	e.buf.WriteString(boardStateBinaryMagic)
+	e.uvarint(BoardStateEncodingVersion)
+
+	e.varint(state.Turn)
+	e.varint(state.Height)
+	e.varint(state.Width)
+	e.points(state.Food)
+
+	e.count(len(state.Snakes), state.Snakes == nil)
+	for _, snake := range state.Snakes {
+		e.string(snake.ID)
+		e.points(snake.Body)
+		e.varint(snake.Health)
+		e.string(snake.EliminatedCause)
+		e.varint(snake.EliminatedOnTurn)
+		e.string(snake.EliminatedBy)
+	}
+
+	e.points(state.Hazards)
+
+	e.count(len(state.GameState), state.GameState == nil)
+	keys := make([]string, 0, len(state.GameState))
+	for key := range state.GameState {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		e.string(key)
+		e.string(state.GameState[key])
+	}
+
+	e.count(len(state.PointState), state.PointState == nil)
+	for _, p := range sortedPointStateKeys(state.PointState) {
+		e.point(p)
+		e.varint(state.PointState[p])
+	}
+
+	return e.buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes a BoardState written by MarshalBinary, using the registered decoder
+// for the encoding version it was written with.
+func (state *BoardState) UnmarshalBinary(data []byte) error {
+	if !bytes.HasPrefix(data, []byte(boardStateBinaryMagic)) {
+		return RulesetError("invalid board state binary encoding: missing header")
+	}
+	d := &boardStateDecoder{data: data[len(boardStateBinaryMagic):]}
+	version := int(d.uvarint())
+	if d.err != nil {
+		return d.err
+	}
+
+	decode, ok := binaryDecoders[version]
+	if !ok {
+		return fmt.Errorf("%w: no binary decoder for version %d", ErrorUnsupportedEncodingVersion, version)
+	}
+	decoded, err := decode(d.data)
+	if err != nil {
+		return err
+	}
+	*state = *decoded
+	return nil
+}
+
+func decodeBoardStateBinaryV1(body []byte) (*BoardState, error) {
+	d := &boardStateDecoder{data: body}
+	state := &BoardState{}
+
+	state.Turn = d.varint()
+	state.Height = d.varint()
+	state.Width = d.varint()
+	state.Food = d.points()
+
+	if n, ok := d.count(); ok {
+		state.Snakes = make([]Snake, 0, n)
+		for i := 0; i < n && d.err == nil; i++ {
+			state.Snakes = append(state.Snakes, Snake{
+				ID:               d.string(),
+				Body:             d.points(),
+				Health:           d.varint(),
+				EliminatedCause:  d.string(),
+				EliminatedOnTurn: d.varint(),
+				EliminatedBy:     d.string(),
+			})
+		}
+	}
+
+	state.Hazards = d.points()
+
+	if n, ok := d.count(); ok {
+		state.GameState = make(map[string]string, n)
+		for i := 0; i < n && d.err == nil; i++ {
+			key := d.string()
+			state.GameState[key] = d.string()
+		}
+	}
+
+	if n, ok := d.count(); ok {
+		state.PointState = make(map[Point]int, n)
+		for i := 0; i < n && d.err == nil; i++ {
+			p := d.point()
+			state.PointState[p] = d.varint()
+		}
+	}
+
+	if d.err == nil && len(d.data) > 0 {
+		d.err = RulesetError(fmt.Sprintf("invalid board state binary encoding: %d unexpected trailing bytes", len(d.data)))
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	return state, nil
+}
+
+// sortedPointStateKeys returns the points in a PointState map in a stable order.
+func sortedPointStateKeys(pointState map[Point]int) []Point {
+	points := make([]Point, 0, len(pointState))
+	for p := range pointState {
+		points = append(points, p)
+	}
+	sort.Slice(points, func(i, j int) bool {
+		return pointLess(points[i], points[j])
+	})
+	return points
+}
+
+type boardStateEncoder struct {
+	buf bytes.Buffer
+	tmp [binary.MaxVarintLen64]byte
+}
+
+func (e *boardStateEncoder) varint(v int) {
+	n := binary.PutVarint(e.tmp[:], int64(v))
+	e.buf.Write(e.tmp[:n])
+}
+
+func (e *boardStateEncoder) uvarint(v uint64) {
+	n := binary.PutUvarint(e.tmp[:], v)
+	e.buf.Write(e.tmp[:n])
+}
+
+func (e *boardStateEncoder) count(n int, isNil bool) {
+	if isNil {
+		e.uvarint(0)
+		return
+	}
+	e.uvarint(uint64(n) + 1)
+}
+
+func (e *boardStateEncoder) string(s string) {
+	e.uvarint(uint64(len(s)))
+	e.buf.WriteString(s)
+}
+
+func (e *boardStateEncoder) point(p Point) {
+	e.varint(p.X)
+	e.varint(p.Y)
+	e.varint(p.TTL)
+	e.varint(p.Value)
+}
+
+func (e *boardStateEncoder) points(points []Point) {
+	e.count(len(points), points == nil)
+	for _, p := range points {
+		e.point(p)
+	}
+}
+
+// boardStateDecoder reads values written by boardStateEncoder.
+// After the first error every read returns a zero value, so err only needs to be checked at the end.
+type boardStateDecoder struct {
+	data []byte
+	err  error
+}
+
+var errTruncatedBoardState = RulesetError("invalid board state binary encoding: unexpected end of data")
+
+func (d *boardStateDecoder) varint() int {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Varint(d.data)
+	if n <= 0 {
+		d.err = errTruncatedBoardState
+		return 0
+	}
+	d.data = d.data[n:]
+	return int(v)
+}
+
+func (d *boardStateDecoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(d.data)
+	if n <= 0 {
+		d.err = errTruncatedBoardState
+		return 0
+	}
+	d.data = d.data[n:]
+	return v
+}
+
+// count reads a list length, returning false if the list was nil.
+func (d *boardStateDecoder) count() (int, bool) {
+	n := d.uvarint()
+	if d.err != nil || n == 0 {
+		return 0, false
+	}
+	// Every item takes at least one byte, which guards against huge allocations from corrupt data
+	if n-1 > uint64(len(d.data)) {
+		d.err = errTruncatedBoardState
+		return 0, false
+	}
+	return int(n - 1), true
+}
+
+func (d *boardStateDecoder) string() string {
+	n := d.uvarint()
+	if d.err != nil {
+		return ""
+	}
+	if n > uint64(len(d.data)) {
+		d.err = errTruncatedBoardState
+		return ""
+	}
+	s := string(d.data[:n])
+	d.data = d.data[n:]
+	return s
+}
+
+func (d *boardStateDecoder) point() Point {
+	return Point{X: d.varint(), Y: d.varint(), TTL: d.varint(), Value: d.varint()}
+}
+
+func (d *boardStateDecoder) points() []Point {
+	n, ok := d.count()
+	if !ok {
+		return nil
+	}
+	points := make([]Point, 0, n)
+	for i := 0; i < n && d.err == nil; i++ {
+		points = append(points, d.point())
+	}
+	return points
+}
@@ -0,0 +1,168 @@
+package rules
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func encodingTestStates(t *testing.T) map[string]*BoardState {
+	defaultState, err := CreateDefaultBoardState(MaxRand, BoardSizeMedium, BoardSizeMedium, []string{"1", "2", "3", "4"})
+	require.NoError(t, err)
+
+	return map[string]*BoardState{
+		"empty":         NewBoardState(0, 0),
+		"zero value":    {},
+		"default 11x11": defaultState,
+		"every field": NewBoardState(7, 5).
+			WithTurn(42).
+			WithFood([]Point{{X: 1, Y: 1}, {X: 3, Y: 3, TTL: 4, Value: -2}}).
+			WithHazards([]Point{{X: 0, Y: 0}, {X: 0, Y: 0}, {X: -1, Y: 9, TTL: 1}}).
+			WithSnakes([]Snake{
+				{ID: "one", Health: 87, Body: []Point{{X: 2, Y: 2}, {X: 2, Y: 1}, {X: 2, Y: 1}}},
+				{ID: "two", Health: 0, Body: []Point{}, EliminatedCause: EliminatedByCollision, EliminatedOnTurn: 40, EliminatedBy: "one"},
+				{ID: "three", Health: 5, Body: nil, EliminatedCause: EliminatedByOutOfHealth, EliminatedOnTurn: 12},
+			}).
+			WithGameState(map[string]string{"level": "3", "": "empty key", "unicode": "🐍"}).
+			WithPointState(map[Point]int{{X: 1, Y: 1}: 5, {X: 1, Y: 1, TTL: 2}: 6, {X: -3, Y: 0, Value: 1}: -100}),
+	}
+}
+
+func TestBoardStateJSONRoundTrip(t *testing.T) {
+	for name, state := range encodingTestStates(t) {
+		t.Run(name, func(t *testing.T) {
+			data, err := json.Marshal(state)
+			require.NoError(t, err)
+
+			decoded := &BoardState{}
+			require.NoError(t, json.Unmarshal(data, decoded))
+			require.Equal(t, state, decoded)
+		})
+	}
+}
+
+func TestBoardStateBinaryRoundTrip(t *testing.T) {
+	for name, state := range encodingTestStates(t) {
+		t.Run(name, func(t *testing.T) {
+			data, err := state.MarshalBinary()
+			require.NoError(t, err)
+
+			decoded := &BoardState{}
+			require.NoError(t, decoded.UnmarshalBinary(data))
+			require.Equal(t, state, decoded)
+
+			// The encoding is deterministic despite the map fields
+			again, err := decoded.MarshalBinary()
+			require.NoError(t, err)
+			require.Equal(t, data, again)
+		})
+	}
+}
+
+func TestBoardStateBinaryIsCompact(t *testing.T) {
+	state, err := CreateDefaultBoardState(MaxRand, BoardSizeMedium, BoardSizeMedium, []string{"1", "2", "3", "4"})
+	require.NoError(t, err)
+
+	jsonData, err := json.Marshal(state)
+	require.NoError(t, err)
+	binaryData, err := state.MarshalBinary()
+	require.NoError(t, err)
+	require.Less(t, len(binaryData)*4, len(jsonData))
+}
+
+func TestBoardStateJSONEncoding(t *testing.T) {
+	state := NewBoardState(3, 3).
+		WithTurn(1).
+		WithFood([]Point{{X: 1, Y: 1}}).
+		WithSnakes([]Snake{{ID: "one", Health: 99, Body: []Point{{X: 0, Y: 0}}}}).
+		WithGameState(map[string]string{"key": "value"}).
+		WithPointState(map[Point]int{{X: 2, Y: 2, TTL: 1}: 7, {X: 0, Y: 1}: 3})
+
+	data, err := json.Marshal(state)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"Version": 1,
+		"Turn": 1,
+		"Height": 3,
+		"Width": 3,
+		"Food": [{"X": 1, "Y": 1}],
+		"Snakes": [{"ID": "one", "Body": [{"X": 0, "Y": 0}], "Health": 99, "EliminatedCause": "", "EliminatedOnTurn": 0, "EliminatedBy": ""}],
+		"Hazards": [],
+		"GameState": {"key": "value"},
+		"PointState": [
+			{"Point": {"X": 0, "Y": 1}, "State": 3},
+			{"Point": {"X": 2, "Y": 2, "TTL": 1}, "State": 7}
+		]
+	}`, string(data))
+}
+
+func TestBoardStateJSONMigrations(t *testing.T) {
+	// Pretend that version 0 named the turn field "T" and version 1 renamed it
+	jsonMigrations[0] = func(doc map[string]json.RawMessage) error {
+		doc["Turn"] = doc["T"]
+		delete(doc, "T")
+		return nil
+	}
+	defer delete(jsonMigrations, 0)
+
+	decoded := &BoardState{}
+	require.NoError(t, json.Unmarshal([]byte(`{"Version": 0, "T": 12, "Width": 5, "Height": 5}`), decoded))
+	require.Equal(t, 12, decoded.Turn)
+	require.Equal(t, 5, decoded.Width)
+
+	// Documents without a version are treated as version 0
+	decoded = &BoardState{}
+	require.NoError(t, json.Unmarshal([]byte(`{"T": 3}`), decoded))
+	require.Equal(t, 3, decoded.Turn)
+
+	require.Panics(t, func() {
+		RegisterBoardStateJSONMigration(0, func(map[string]json.RawMessage) error { return nil })
+	})
+}
+
+func TestBoardStateJSONUnsupportedVersion(t *testing.T) {
+	err := json.Unmarshal([]byte(`{"Version": 99}`), &BoardState{})
+	require.ErrorIs(t, err, ErrorUnsupportedEncodingVersion)
+
+	// There is no migration registered from version 0
+	err = json.Unmarshal([]byte(`{"Turn": 3}`), &BoardState{})
+	require.ErrorIs(t, err, ErrorUnsupportedEncodingVersion)
+}
+
+func TestBoardStateBinaryDecoders(t *testing.T) {
+	data, err := NewBoardState(5, 5).MarshalBinary()
+	require.NoError(t, err)
+
+	// Rewrite the version byte to one that has no decoder
+	data[len(boardStateBinaryMagic)] = 99
+	err = (&BoardState{}).UnmarshalBinary(data)
+	require.ErrorIs(t, err, ErrorUnsupportedEncodingVersion)
+
+	binaryDecoders[99] = func(body []byte) (*BoardState, error) {
+		return NewBoardState(1, 1), nil
+	}
+	defer delete(binaryDecoders, 99)
+
+	decoded := &BoardState{}
+	require.NoError(t, decoded.UnmarshalBinary(data))
+	require.Equal(t, NewBoardState(1, 1), decoded)
+
+	require.Panics(t, func() {
+		RegisterBoardStateBinaryDecoder(BoardStateEncodingVersion, decodeBoardStateBinaryV1)
+	})
+}
+
+func TestBoardStateBinaryInvalid(t *testing.T) {
+	data, err := encodingTestStates(t)["every field"].MarshalBinary()
+	require.NoError(t, err)
+
+	require.Error(t, (&BoardState{}).UnmarshalBinary(nil))
+	require.Error(t, (&BoardState{}).UnmarshalBinary([]byte("XYZ\x01")))
+	require.Error(t, (&BoardState{}).UnmarshalBinary(append(data, 0)))
+
+	// Every truncation of a valid encoding must fail cleanly
+	for i := 0; i < len(data); i++ {
+		require.Error(t, (&BoardState{}).UnmarshalBinary(data[:i]), "truncated to %d bytes", i)
+	}
+}
@@ -0,0 +1,269 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/Pikle2/rules"
+	"github.com/Pikle2/rules/maps"
+)
+
+const (
+	ErrorGameNotStarted     = rules.RulesetError("game has not been started")
+	ErrorGameAlreadyStarted = rules.RulesetError("game has already been started")
+	ErrorGameOver           = rules.RulesetError("game is over")
+)
+
+// Game runs a game of Battlesnake: it sets up the board with a map, collects moves from each snake every turn,
+// and applies the map and ruleset to produce the next board state.
+//
+// The board states returned by a Game are shared with it and must not be modified; use Clone to get a copy.
+type Game struct {
+	Ruleset rules.Ruleset
+	Map     maps.GameMap
+	Width   int
+	Height  int
+	Snakes  []SnakeIO
+
+	// Sequential requests moves from one snake at a time instead of all at once.
+	Sequential bool
+	// Strict validates the board state after every map and ruleset update, and stops the game if it is inconsistent.
+	Strict bool
+	// KeyframeInterval is passed to rules.NewGameHistory when the game starts.
+	KeyframeInterval int
+	// OnSnakeError is called, if set, when a snake returns an error. These errors don't stop the game.
+	OnSnakeError func(snakeID string, err error)
+
+	boardState *rules.BoardState
+	gameOver   bool
+	history    *rules.GameHistory
+}
+
+// NewGame creates a game for the snakes, which is ready to Start or Run.
+func NewGame(ruleset rules.Ruleset, gameMap maps.GameMap, width, height int, snakes []SnakeIO) *Game {
+	return &Game{
+		Ruleset: ruleset,
+		Map:     gameMap,
+		Width:   width,
+		Height:  height,
+		Snakes:  snakes,
+	}
+}
+
+// State returns the current board state, or nil if the game hasn't started.
+func (g *Game) State() *rules.BoardState {
+	return g.boardState
+}
+
+// IsOver returns true once the ruleset has ended the game.
+func (g *Game) IsOver() bool {
+	return g.gameOver
+}
+
+// History returns every board state of the game so far, or nil if the game hasn't started.
+func (g *Game) History() *rules.GameHistory {
+	return g.history
+}
+
+// Start sets up the initial board state and sends it to every snake.
+// It returns true if the game is already over, which can happen with some rulesets.
+func (g *Game) Start(ctx context.Context) (bool, *rules.BoardState, error) {
+	if g.boardState != nil {
+		return false, nil, ErrorGameAlreadyStarted
+	}
+	if err := ctx.Err(); err != nil {
+		return false, nil, err
+	}
+
+	snakeIDs := make([]string, 0, len(g.Snakes))
+	for _, snake := range g.Snakes {
+		snakeIDs = append(snakeIDs, snake.ID())
+	}
+	boardState, err := maps.SetupBoardWithMap(g.Map, g.Ruleset.Settings(), g.Width, g.Height, snakeIDs)
+	if err != nil {
+		return false, nil, fmt.Errorf("error initializing BoardState with map: %w", err)
+	}
+	if err := g.validate("SetupBoard", boardState); err != nil {
+		return false, nil, err
+	}
+	gameOver, boardState, err := g.Ruleset.Execute(boardState, nil)
+	if err != nil {
+		return false, nil, fmt.Errorf("error initializing BoardState with ruleset: %w", err)
+	}
+	if err := g.validate("Execute", boardState); err != nil {
+		return false, nil, err
+	}
+
+	g.boardState = boardState
+	g.gameOver = gameOver
+	g.history = rules.NewGameHistory(boardState, g.KeyframeInterval)
+
+	for _, snake := range g.Snakes {
+		if err := snake.Start(ctx, boardState); err != nil {
+			g.snakeError(snake.ID(), err)
+		}
+	}
+
+	return gameOver, boardState, nil
+}
+
+// Step plays a single turn and returns the new board state, and true if the game is now over.
+func (g *Game) Step(ctx context.Context) (bool, *rules.BoardState, error) {
+	if g.boardState == nil {
+		return false, nil, ErrorGameNotStarted
+	}
+	if g.gameOver {
+		return true, g.boardState, ErrorGameOver
+	}
+
+	gameOver, boardState, err := g.Next(ctx, g.boardState)
+	if err != nil {
+		return false, boardState, err
+	}
+
+	g.boardState = boardState
+	g.gameOver = gameOver
+	g.history.Push(boardState)
+
+	return gameOver, boardState, nil
+}
+
+// Run plays the game until it is over or ctx is cancelled, then sends the final board state to every snake.
+// The game is started first if Start hasn't already been called.
+func (g *Game) Run(ctx context.Context) (*rules.BoardState, error) {
+	if g.boardState == nil {
+		if _, _, err := g.Start(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	for !g.gameOver {
+		if _, _, err := g.Step(ctx); err != nil {
+			return g.boardState, err
+		}
+	}
+
+	g.End(ctx)
+	return g.boardState, nil
+}
+
+// End sends the current board state to every snake to tell them the game is over.
+func (g *Game) End(ctx context.Context) {
+	for _, snake := range g.Snakes {
+		if err := snake.End(ctx, g.boardState); err != nil {
+			g.snakeError(snake.ID(), err)
+		}
+	}
+}
+
+// Next produces the board state for the turn after boardState, without changing the game's own state.
+// It applies the map's PreUpdateBoard, collects moves from the snakes and bots that are still alive, executes the ruleset,
+// applies the map's PostUpdateBoard and finally advances the turn.
+func (g *Game) Next(ctx context.Context, boardState *rules.BoardState) (bool, *rules.BoardState, error) {
+	if err := ctx.Err(); err != nil {
+		return false, boardState, err
+	}
+
+	// apply PreUpdateBoard before making requests to snakes
+	boardState, err := maps.PreUpdateBoard(g.Map, boardState, g.Ruleset.Settings())
+	if err != nil {
+		return false, boardState, fmt.Errorf("error pre-updating board with game map: %w", err)
+	}
+	if err := g.validate("PreUpdateBoard", boardState); err != nil {
+		return false, boardState, err
+	}
+
+	moves := g.collectMoves(ctx, boardState)
+	botMoves, err := maps.BotMoves(boardState, g.Ruleset.Settings())
+	if err != nil {
+		return false, boardState, fmt.Errorf("error moving bot snakes: %w", err)
+	}
+	moves = append(moves, botMoves...)
+
+	gameOver, boardState, err := g.Ruleset.Execute(boardState, moves)
+	if err != nil {
+		return false, boardState, fmt.Errorf("error updating board state from ruleset: %w", err)
+	}
+	if err := g.validate("Execute", boardState); err != nil {
+		return false, boardState, err
+	}
+
+	// apply PostUpdateBoard after ruleset operates on snake moves
+	boardState, err = maps.PostUpdateBoard(g.Map, boardState, g.Ruleset.Settings())
+	if err != nil {
+		return false, boardState, fmt.Errorf("error post-updating board with game map: %w", err)
+	}
+	if err := g.validate("PostUpdateBoard", boardState); err != nil {
+		return false, boardState, err
+	}
+
+	boardState.Turn += 1
+
+	return gameOver, boardState, nil
+}
+
+// collectMoves asks every snake that is still alive for its move.
+func (g *Game) collectMoves(ctx context.Context, boardState *rules.BoardState) []rules.SnakeMove {
+	var alive []SnakeIO
+	for _, snakeIO := range g.Snakes {
+		for _, snake := range boardState.Snakes {
+			if snake.ID == snakeIO.ID() && snake.EliminatedCause == rules.NotEliminated {
+				alive = append(alive, snakeIO)
+				break
+			}
+		}
+	}
+
+	moves := make([]rules.SnakeMove, len(alive))
+	errs := make([]error, len(alive))
+	getMove := func(i int) {
+		move, err := alive[i].Move(ctx, boardState)
+		if err != nil {
+			move = ""
+		}
+		moves[i] = rules.SnakeMove{ID: alive[i].ID(), Move: move}
+		errs[i] = err
+	}
+
+	if g.Sequential {
+		for i := range alive {
+			getMove(i)
+		}
+	} else {
+		var wg sync.WaitGroup
+		for i := range alive {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				getMove(i)
+			}(i)
+		}
+		wg.Wait()
+	}
+
+	for i, err := range errs {
+		if err != nil {
+			g.snakeError(alive[i].ID(), err)
+		}
+	}
+	return moves
+}
+
+// validate checks the board state for consistency when running in strict mode.
+// The step is used to identify which part of the game loop produced an invalid state.
+func (g *Game) validate(step string, boardState *rules.BoardState) error {
+	if !g.Strict {
+		return nil
+	}
+	if err := boardState.Validate(); err != nil {
+		return fmt.Errorf("strict mode: board state on turn %d is invalid after %s: %w", boardState.Turn, step, err)
+	}
+	return nil
+}
+
+func (g *Game) snakeError(snakeID string, err error) {
+	if g.OnSnakeError != nil {
+		g.OnSnakeError(snakeID, err)
+	}
+}
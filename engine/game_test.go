@@ -0,0 +1,231 @@
+package engine_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/Pikle2/rules"
+	"github.com/Pikle2/rules/engine"
+	"github.com/Pikle2/rules/maps"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingSnake always moves the same way and records the calls made to it
+type recordingSnake struct {
+	id      string
+	move    string
+	err     error
+	mu      sync.Mutex
+	started int
+	moves   int
+	ended   *rules.BoardState
+}
+
+func (s *recordingSnake) ID() string { return s.id }
+
+func (s *recordingSnake) Start(ctx context.Context, boardState *rules.BoardState) error {
+	s.started++
+	return nil
+}
+
+func (s *recordingSnake) Move(ctx context.Context, boardState *rules.BoardState) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.moves++
+	return s.move, s.err
+}
+
+func (s *recordingSnake) End(ctx context.Context, boardState *rules.BoardState) error {
+	s.ended = boardState
+	return nil
+}
+
+func newTestGame(t *testing.T, snakes ...engine.SnakeIO) *engine.Game {
+	gameMap := maps.StubMap{
+		Id: "stub",
+		SnakePositions: map[string]rules.Point{
+			"one": {X: 1, Y: 1},
+			"two": {X: 9, Y: 9},
+		},
+	}
+	ruleset := rules.NewRulesetBuilder().
+		WithSeed(1).
+		WithSolo(len(snakes) < 2).
+		NamedRuleset(rules.GameTypeStandard)
+	return engine.NewGame(ruleset, gameMap, rules.BoardSizeMedium, rules.BoardSizeMedium, snakes)
+}
+
+func TestGameRun(t *testing.T) {
+	for _, sequential := range []bool{false, true} {
+		one := &recordingSnake{id: "one", move: rules.MoveUp}
+		two := &recordingSnake{id: "two", move: rules.MoveDown}
+		game := newTestGame(t, one, two)
+		game.Sequential = sequential
+		game.Strict = true
+
+		final, err := game.Run(context.Background())
+		require.NoError(t, err)
+		require.True(t, game.IsOver())
+		require.Same(t, final, game.State())
+
+		// Both snakes run into a wall on turn 10, and the ruleset ends the game on the next turn
+		require.Equal(t, 11, final.Turn)
+		require.Equal(t, 1, one.started)
+		require.Equal(t, 1, two.started)
+		require.Same(t, final, one.ended)
+		require.Same(t, final, two.ended)
+
+		history := game.History()
+		require.Equal(t, final.Turn+1, history.Len())
+		initial, err := history.At(0)
+		require.NoError(t, err)
+		require.Equal(t, 0, initial.Turn)
+		last, err := history.At(final.Turn)
+		require.NoError(t, err)
+		require.Equal(t, final, last)
+	}
+}
+
+func TestGameStep(t *testing.T) {
+	one := &recordingSnake{id: "one", move: rules.MoveUp}
+	game := newTestGame(t, one)
+
+	_, _, err := game.Step(context.Background())
+	require.ErrorIs(t, err, engine.ErrorGameNotStarted)
+
+	gameOver, initial, err := game.Start(context.Background())
+	require.NoError(t, err)
+	require.False(t, gameOver)
+	require.Equal(t, 0, initial.Turn)
+	require.Len(t, initial.Snakes, 1)
+
+	_, _, err = game.Start(context.Background())
+	require.ErrorIs(t, err, engine.ErrorGameAlreadyStarted)
+
+	gameOver, next, err := game.Step(context.Background())
+	require.NoError(t, err)
+	require.False(t, gameOver)
+	require.Equal(t, 1, next.Turn)
+	require.Equal(t, 1, one.moves)
+	require.Equal(t, initial.Snakes[0].Body[0].Y+1, next.Snakes[0].Body[0].Y)
+
+	// Next doesn't change the game
+	_, peek, err := game.Next(context.Background(), next)
+	require.NoError(t, err)
+	require.Equal(t, 2, peek.Turn)
+	require.Same(t, next, game.State())
+	require.Equal(t, 2, game.History().Len())
+
+	for !gameOver {
+		gameOver, _, err = game.Step(context.Background())
+		require.NoError(t, err)
+	}
+	_, _, err = game.Step(context.Background())
+	require.ErrorIs(t, err, engine.ErrorGameOver)
+}
+
+func TestGameSnakeErrors(t *testing.T) {
+	moveErr := errors.New("timed out")
+	one := &recordingSnake{id: "one", move: rules.MoveLeft, err: moveErr}
+	game := newTestGame(t, one)
+
+	var errs []error
+	game.OnSnakeError = func(snakeID string, err error) {
+		require.Equal(t, "one", snakeID)
+		errs = append(errs, err)
+	}
+
+	_, initial, err := game.Start(context.Background())
+	require.NoError(t, err)
+	_, next, err := game.Step(context.Background())
+	require.NoError(t, err)
+
+	// The returned move is ignored, and the snake continues in its default direction
+	require.Equal(t, []error{moveErr}, errs)
+	require.Equal(t, initial.Snakes[0].Body[0].Y+1, next.Snakes[0].Body[0].Y)
+}
+
+func TestGameCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	game := newTestGame(t, engine.SnakeFunc{
+		SnakeID: "one",
+		MoveFunc: func(ctx context.Context, boardState *rules.BoardState) (string, error) {
+			if boardState.Turn == 2 {
+				cancel()
+			}
+			return rules.MoveUp, nil
+		},
+	})
+
+	final, err := game.Run(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+	require.False(t, game.IsOver())
+	require.Equal(t, 3, final.Turn)
+}
+
+func TestGameStrict(t *testing.T) {
+	gameMap := maps.StubMap{
+		Id:             "stub",
+		SnakePositions: map[string]rules.Point{"one": {X: 1, Y: 1}},
+		Food:           []rules.Point{{X: 20, Y: 20}},
+	}
+	ruleset := rules.NewRulesetBuilder().NamedRuleset(rules.GameTypeStandard)
+
+	for _, strict := range []bool{false, true} {
+		game := engine.NewGame(ruleset, gameMap, 5, 5, []engine.SnakeIO{&recordingSnake{id: "one", move: rules.MoveUp}})
+		game.Strict = strict
+
+		_, _, err := game.Start(context.Background())
+		if !strict {
+			require.NoError(t, err)
+			continue
+		}
+		require.ErrorContains(t, err, "invalid after SetupBoard")
+		var validationErr rules.BoardStateValidationError
+		require.ErrorAs(t, err, &validationErr)
+	}
+}
+
+// botMap is a StubMap that also places a patrolling bot
+type botMap struct {
+	maps.StubMap
+	bot rules.Bot
+}
+
+func (m botMap) SetupBoard(initialBoardState *rules.BoardState, settings rules.Settings, editor maps.Editor) error {
+	if err := m.StubMap.SetupBoard(initialBoardState, settings, editor); err != nil {
+		return err
+	}
+	start := rules.Point{X: 5, Y: 5}
+	return maps.EditorPlaceBot(editor, "bot", []rules.Point{start, start, start}, rules.SnakeMaxHealth, m.bot)
+}
+
+func TestGameBots(t *testing.T) {
+	one := &recordingSnake{id: "one", move: rules.MoveUp}
+	gameMap := botMap{
+		StubMap: maps.StubMap{Id: "bots", SnakePositions: map[string]rules.Point{"one": {X: 1, Y: 1}}},
+		bot:     rules.Bot{Policy: maps.BotPolicyPatrol, ExcludeFromWin: true},
+	}
+	ruleset := rules.NewRulesetBuilder().WithSeed(1).WithSolo(true).NamedRuleset(rules.GameTypeStandard)
+	game := engine.NewGame(ruleset, gameMap, rules.BoardSizeMedium, rules.BoardSizeMedium, []engine.SnakeIO{one})
+	game.Strict = true
+
+	// The bot is moved by the engine without being asked, and doesn't keep the game going by itself
+	final, err := game.Run(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 11, final.Turn)
+	require.Len(t, final.Snakes, 2)
+	require.Equal(t, rules.EliminatedByOutOfBounds, final.Snakes[0].EliminatedCause)
+	require.Equal(t, rules.NotEliminated, final.Snakes[1].EliminatedCause)
+	require.NotEqual(t, rules.Point{X: 5, Y: 5}, final.Snakes[1].Body[0])
+
+	// Bots with an unknown policy stop the game
+	gameMap.bot.Policy = "wander"
+	game = engine.NewGame(ruleset, gameMap, rules.BoardSizeMedium, rules.BoardSizeMedium, []engine.SnakeIO{one})
+	_, _, err = game.Start(context.Background())
+	require.NoError(t, err)
+	_, _, err = game.Step(context.Background())
+	require.EqualError(t, err, "error moving bot snakes: bot bot: unknown bot policy 'wander'")
+}
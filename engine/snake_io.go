@@ -0,0 +1,49 @@
+package engine
+
+import (
+	"context"
+
+	"github.com/Pikle2/rules"
+)
+
+// SnakeIO connects a Game to a single snake, e.g. over HTTP or to an in-process bot.
+//
+// Move may be called concurrently for different snakes, but is never called concurrently for the same snake.
+// The board states passed to a SnakeIO must not be modified.
+type SnakeIO interface {
+	// ID is the ID of the snake on the board.
+	ID() string
+
+	// Start is called once the initial board has been set up.
+	Start(ctx context.Context, boardState *rules.BoardState) error
+
+	// Move is called every turn the snake is alive, and returns one of the rules.Move* constants.
+	// If it returns an error, or a move that isn't valid, the ruleset applies its default move.
+	Move(ctx context.Context, boardState *rules.BoardState) (string, error)
+
+	// End is called once the game is over, with the final board state.
+	End(ctx context.Context, boardState *rules.BoardState) error
+}
+
+// SnakeFunc is a SnakeIO that calls a function for each move, and ignores Start and End.
+// It is useful for bots and tests.
+type SnakeFunc struct {
+	SnakeID  string
+	MoveFunc func(ctx context.Context, boardState *rules.BoardState) (string, error)
+}
+
+func (s SnakeFunc) ID() string {
+	return s.SnakeID
+}
+
+func (SnakeFunc) Start(context.Context, *rules.BoardState) error {
+	return nil
+}
+
+func (s SnakeFunc) Move(ctx context.Context, boardState *rules.BoardState) (string, error) {
+	return s.MoveFunc(ctx, boardState)
+}
+
+func (SnakeFunc) End(context.Context, *rules.BoardState) error {
+	return nil
+}
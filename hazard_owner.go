@@ -0,0 +1,48 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+)
+
+// hazardOwnerKeyPrefix is the GameState key prefix used to record which snake owns the hazards on a square.
+const hazardOwnerKeyPrefix = "hazardOwner."
+
+// SetHazardOwner records the snake that owns the hazards on a square, such as the snake that left a trail of hazards
+// behind it. Snakes eliminated by those hazards are eliminated by the owner. Owners apply to every hazard stacked on the
+// square, and an empty snake ID removes the owner. The owner is stored in the board's GameState, so that it persists
+// between turns, until it is changed or the square has no hazards left and PruneHazardOwners is called.
+func SetHazardOwner(b *BoardState, p Point, snakeID string) {
+	key := hazardOwnerKey(p)
+	if snakeID == "" {
+		delete(b.GameState, key)
+		return
+	}
+	if b.GameState == nil {
+		b.GameState = map[string]string{}
+	}
+	b.GameState[key] = snakeID
+}
+
+// GetHazardOwner returns the snake that owns the hazards on a square, or an empty string if they have no owner.
+func GetHazardOwner(b *BoardState, p Point) string {
+	return b.GameState[hazardOwnerKey(p)]
+}
+
+func hazardOwnerKey(p Point) string {
+	return fmt.Sprintf("%s%d,%d", hazardOwnerKeyPrefix, p.X, p.Y)
+}
+
+// PruneHazardOwners removes the owners of squares that don't have any hazards, so that owners don't build up in the
+// board's GameState once their hazards are gone.
+func PruneHazardOwners(b *BoardState) {
+	hazards := make(map[string]bool, len(b.Hazards))
+	for _, p := range b.Hazards {
+		hazards[hazardOwnerKey(p)] = true
+	}
+	for key := range b.GameState {
+		if strings.HasPrefix(key, hazardOwnerKeyPrefix) && !hazards[key] {
+			delete(b.GameState, key)
+		}
+	}
+}
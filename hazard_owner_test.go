@@ -0,0 +1,67 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetHazardOwner(t *testing.T) {
+	b := &BoardState{}
+	require.Equal(t, "", GetHazardOwner(b, Point{X: 1, Y: 2}))
+
+	SetHazardOwner(b, Point{X: 1, Y: 2}, "snail")
+	require.Equal(t, "snail", GetHazardOwner(b, Point{X: 1, Y: 2}))
+	require.Equal(t, "", GetHazardOwner(b, Point{X: 2, Y: 1}))
+
+	// Owners are kept when the board is cloned
+	require.Equal(t, "snail", GetHazardOwner(b.Clone(), Point{X: 1, Y: 2}))
+
+	SetHazardOwner(b, Point{X: 1, Y: 2}, "")
+	require.Equal(t, "", GetHazardOwner(b, Point{X: 1, Y: 2}))
+	require.Empty(t, b.GameState)
+}
+
+func TestDamageHazardsAttributesOwner(t *testing.T) {
+	b := NewBoardState(11, 11).WithSnakes([]Snake{
+		{ID: "one", Health: 10, Body: []Point{{X: 1, Y: 1}, {X: 1, Y: 0}}},
+		{ID: "two", Health: 10, Body: []Point{{X: 5, Y: 5}, {X: 5, Y: 4}}},
+		{ID: "three", Health: 100, Body: []Point{{X: 8, Y: 8}, {X: 8, Y: 7}}},
+	}).WithHazards([]Point{{X: 1, Y: 1}, {X: 5, Y: 5}, {X: 8, Y: 8}})
+	b.Turn = 4
+	SetHazardOwner(b, Point{X: 1, Y: 1}, "three")
+	SetHazardOwner(b, Point{X: 8, Y: 8}, "one")
+
+	_, err := DamageHazardsStandard(b, NewSettingsWithParams(ParamHazardDamagePerTurn, "15"), mockSnakeMoves())
+	require.NoError(t, err)
+
+	// Snakes eliminated by owned hazards are eliminated by the owner
+	require.Equal(t, EliminatedByHazard, b.Snakes[0].EliminatedCause)
+	require.Equal(t, "three", b.Snakes[0].EliminatedBy)
+	require.Equal(t, 5, b.Snakes[0].EliminatedOnTurn)
+	require.Equal(t, EliminatedByHazard, b.Snakes[1].EliminatedCause)
+	require.Equal(t, "", b.Snakes[1].EliminatedBy)
+	require.Equal(t, NotEliminated, b.Snakes[2].EliminatedCause)
+	require.Equal(t, 85, b.Snakes[2].Health)
+
+	// Snakes eliminated by their own hazards aren't credited with eliminating themselves
+	b = NewBoardState(11, 11).WithSnakes([]Snake{
+		{ID: "one", Health: 10, Body: []Point{{X: 1, Y: 1}, {X: 1, Y: 0}}},
+	}).WithHazards([]Point{{X: 1, Y: 1}})
+	SetHazardOwner(b, Point{X: 1, Y: 1}, "one")
+	_, err = DamageHazardsStandard(b, NewSettingsWithParams(ParamHazardDamagePerTurn, "15"), mockSnakeMoves())
+	require.NoError(t, err)
+	require.Equal(t, EliminatedByHazard, b.Snakes[0].EliminatedCause)
+	require.Equal(t, "", b.Snakes[0].EliminatedBy)
+}
+
+func TestPruneHazardOwners(t *testing.T) {
+	b := NewBoardState(11, 11).WithHazards([]Point{{X: 1, Y: 1}, {X: 1, Y: 1}}).WithGameState(map[string]string{"other": "kept"})
+	SetHazardOwner(b, Point{X: 1, Y: 1}, "one")
+	SetHazardOwner(b, Point{X: 2, Y: 2}, "two")
+
+	PruneHazardOwners(b)
+	require.Equal(t, "one", GetHazardOwner(b, Point{X: 1, Y: 1}))
+	require.Equal(t, "", GetHazardOwner(b, Point{X: 2, Y: 2}))
+	require.Len(t, b.GameState, 2)
+}
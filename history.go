@@ -0,0 +1,135 @@
+package rules
+
+import "fmt"
+
+// DefaultKeyframeInterval is the number of positions between full copies of the board state
+// stored by a GameHistory created with a non-positive interval.
+const DefaultKeyframeInterval = 50
+
+// GameHistory stores a sequence of board states compactly, as deltas between consecutive states with
+// a full keyframe every few positions, and supports seeking to any earlier state as well as undo and redo.
+//
+// Position 0 is the initial state. Each call to Push adds the next state after the current position,
+// discarding any states that were undone.
+type GameHistory struct {
+	keyframeInterval int
+	// keyframes holds a full copy of the state at every position that is a multiple of keyframeInterval
+	keyframes map[int]*BoardState
+	// deltas[i] transforms the state at position i into the state at position i+1
+	deltas []*BoardStateDelta
+
+	position int
+	current  *BoardState
+}
+
+// NewGameHistory creates a history starting from initial.
+// A full copy of the state is kept every keyframeInterval positions, which bounds the number of deltas
+// applied when seeking; if keyframeInterval is not positive DefaultKeyframeInterval is used.
+func NewGameHistory(initial *BoardState, keyframeInterval int) *GameHistory {
+	if keyframeInterval <= 0 {
+		keyframeInterval = DefaultKeyframeInterval
+	}
+	return &GameHistory{
+		keyframeInterval: keyframeInterval,
+		keyframes:        map[int]*BoardState{0: initial.Clone()},
+		current:          initial.Clone(),
+	}
+}
+
+// Len returns the number of states in the history, including any that can be redone.
+func (h *GameHistory) Len() int {
+	return len(h.deltas) + 1
+}
+
+// Position returns the position of the current state.
+func (h *GameHistory) Position() int {
+	return h.position
+}
+
+// Current returns a copy of the current state.
+func (h *GameHistory) Current() *BoardState {
+	return h.current.Clone()
+}
+
+// Delta returns the delta that transforms the state at position i into the state at position i+1.
+func (h *GameHistory) Delta(i int) (*BoardStateDelta, error) {
+	if i < 0 || i >= len(h.deltas) {
+		return nil, fmt.Errorf("%w: no delta after position %d of %d", ErrorHistoryOutOfRange, i, h.Len())
+	}
+	return h.deltas[i], nil
+}
+
+// Push records state as the position after the current one and makes it current.
+// Any states after the current position, i.e. ones that were undone, are discarded.
+func (h *GameHistory) Push(state *BoardState) {
+	for i := h.position + 1; i < h.Len(); i++ {
+		delete(h.keyframes, i)
+	}
+	h.deltas = append(h.deltas[:h.position], DiffBoardStates(h.current, state))
+	h.position++
+	h.current = state.Clone()
+	if h.position%h.keyframeInterval == 0 {
+		h.keyframes[h.position] = state.Clone()
+	}
+}
+
+// At returns a copy of the state at position i without changing the current position.
+func (h *GameHistory) At(i int) (*BoardState, error) {
+	if i < 0 || i >= h.Len() {
+		return nil, fmt.Errorf("%w: position %d of %d", ErrorHistoryOutOfRange, i, h.Len())
+	}
+
+	// Start from whichever known state needs the fewest deltas: the current state or the nearest keyframe
+	// on either side of i. Later keyframes are reached by reverting deltas.
+	start, state := h.position, h.current
+	below := i - i%h.keyframeInterval
+	if absInt(i-below) < absInt(i-start) {
+		start, state = below, h.keyframes[below]
+	}
+	if above := below + h.keyframeInterval; above < h.Len() && above-i < absInt(i-start) {
+		start, state = above, h.keyframes[above]
+	}
+
+	var err error
+	if start == i {
+		return state.Clone(), nil
+	}
+	for ; start < i; start++ {
+		if state, err = h.deltas[start].Apply(state); err != nil {
+			return nil, err
+		}
+	}
+	for ; start > i; start-- {
+		if state, err = h.deltas[start-1].Revert(state); err != nil {
+			return nil, err
+		}
+	}
+	return state, nil
+}
+
+// Seek moves the current position to i and returns a copy of the state there.
+func (h *GameHistory) Seek(i int) (*BoardState, error) {
+	state, err := h.At(i)
+	if err != nil {
+		return nil, err
+	}
+	h.position = i
+	h.current = state
+	return state.Clone(), nil
+}
+
+// Undo moves back one position and returns a copy of the state there.
+func (h *GameHistory) Undo() (*BoardState, error) {
+	if h.position == 0 {
+		return nil, fmt.Errorf("%w: nothing to undo", ErrorHistoryOutOfRange)
+	}
+	return h.Seek(h.position - 1)
+}
+
+// Redo moves forward one position after an Undo or Seek and returns a copy of the state there.
+func (h *GameHistory) Redo() (*BoardState, error) {
+	if h.position == h.Len()-1 {
+		return nil, fmt.Errorf("%w: nothing to redo", ErrorHistoryOutOfRange)
+	}
+	return h.Seek(h.position + 1)
+}
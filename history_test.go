@@ -0,0 +1,103 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// playHistory plays a short standard game, recording every state in a history and returning the states
+func playHistory(t *testing.T, turns, keyframeInterval int) (*GameHistory, []*BoardState) {
+	state, err := CreateDefaultBoardState(MaxRand, BoardSizeMedium, BoardSizeMedium, []string{"1", "2"})
+	require.NoError(t, err)
+
+	history := NewGameHistory(state, keyframeInterval)
+	states := []*BoardState{state.Clone()}
+
+	r := NewRulesetBuilder().WithSeed(1).NamedRuleset(GameTypeStandard)
+	moves := []string{MoveUp, MoveUp, MoveRight, MoveRight, MoveDown, MoveDown, MoveLeft, MoveLeft}
+	for i := 0; i < turns; i++ {
+		_, state, err = r.Execute(state, []SnakeMove{
+			{ID: "1", Move: moves[i%len(moves)]},
+			{ID: "2", Move: moves[i%len(moves)]},
+		})
+		require.NoError(t, err)
+		history.Push(state)
+		states = append(states, state.Clone())
+	}
+	return history, states
+}
+
+func TestGameHistorySeek(t *testing.T) {
+	history, states := playHistory(t, 20, 4)
+	require.Equal(t, 21, history.Len())
+	require.Equal(t, 20, history.Position())
+	require.Equal(t, states[20], history.Current())
+
+	// Seek in an order that exercises going forwards, backwards, and starting from keyframes
+	for _, i := range []int{0, 7, 3, 20, 13, 12, 9, 1, 19, 4} {
+		state, err := history.Seek(i)
+		require.NoError(t, err)
+		require.Equal(t, states[i], state, "position %d", i)
+		require.Equal(t, i, history.Position())
+
+		at, err := history.At(20 - i)
+		require.NoError(t, err)
+		require.Equal(t, states[20-i], at, "position %d", 20-i)
+		require.Equal(t, i, history.Position())
+	}
+
+	_, err := history.Seek(21)
+	require.ErrorIs(t, err, ErrorHistoryOutOfRange)
+	_, err = history.At(-1)
+	require.ErrorIs(t, err, ErrorHistoryOutOfRange)
+}
+
+func TestGameHistoryUndoRedo(t *testing.T) {
+	history, states := playHistory(t, 3, 0)
+
+	state, err := history.Redo()
+	require.ErrorIs(t, err, ErrorHistoryOutOfRange)
+	require.Nil(t, state)
+
+	for i := 2; i >= 0; i-- {
+		state, err := history.Undo()
+		require.NoError(t, err)
+		require.Equal(t, states[i], state)
+	}
+	_, err = history.Undo()
+	require.ErrorIs(t, err, ErrorHistoryOutOfRange)
+
+	state, err = history.Redo()
+	require.NoError(t, err)
+	require.Equal(t, states[1], state)
+
+	// Pushing after an undo discards the states that could have been redone
+	branch := state.Clone().WithTurn(99)
+	history.Push(branch)
+	require.Equal(t, 3, history.Len())
+	require.Equal(t, branch, history.Current())
+	_, err = history.Redo()
+	require.ErrorIs(t, err, ErrorHistoryOutOfRange)
+
+	state, err = history.Undo()
+	require.NoError(t, err)
+	require.Equal(t, states[1], state)
+
+	delta, err := history.Delta(1)
+	require.NoError(t, err)
+	require.Equal(t, &IntChange{Old: states[1].Turn, New: 99}, delta.Turn)
+	_, err = history.Delta(2)
+	require.ErrorIs(t, err, ErrorHistoryOutOfRange)
+}
+
+func TestGameHistoryCopiesStates(t *testing.T) {
+	state := NewBoardState(5, 5)
+	history := NewGameHistory(state, 1)
+
+	state.Turn = 10
+	require.Equal(t, 0, history.Current().Turn)
+
+	history.Current().Turn = 10
+	require.Equal(t, 0, history.Current().Turn)
+}
@@ -4,10 +4,30 @@ import (
 	"github.com/Pikle2/rules"
 )
 
-type ArcadeMazeMap struct{}
+type ArcadeMazeMap struct {
+	// chanceOnlyFood spawns food at the fixed food positions using only the food spawn chance, like version 1 of the map did.
+	chanceOnlyFood bool
+}
 
 func init() {
 	globalRegistry.RegisterMap("arcade_maze", ArcadeMazeMap{})
+	globalRegistry.RegisterMapVersion("arcade_maze", ArcadeMazeMap{chanceOnlyFood: true})
+}
+
+// ArcadeMazeFoodSpawns are the fixed positions that food spawns at in the arcade maze.
+var ArcadeMazeFoodSpawns = []rules.Point{
+	{X: 1, Y: 1},
+	{X: 3, Y: 11},
+	{X: 4, Y: 7},
+	{X: 4, Y: 17},
+	{X: 9, Y: 1},
+	{X: 9, Y: 5},
+	{X: 9, Y: 11},
+	{X: 9, Y: 17},
+	{X: 14, Y: 7},
+	{X: 14, Y: 17},
+	{X: 15, Y: 11},
+	{X: 17, Y: 1},
 }
 
 func (m ArcadeMazeMap) ID() string {
@@ -15,15 +35,36 @@ func (m ArcadeMazeMap) ID() string {
 }
 
 func (m ArcadeMazeMap) Meta() Metadata {
+	version := 2
+	minimumFoodDescription := "Minimum food to keep on the board at the fixed food positions. When above 0, a food is also placed in the center of the maze at the start of the game"
+	if m.chanceOnlyFood {
+		version = 1
+		minimumFoodDescription = "When above 0, a food is placed in the center of the maze at the start of the game"
+	}
 	return Metadata{
 		Name:        "Arcade Maze",
 		Description: "Generic arcade maze map with deadly hazard walls.",
 		Author:      "Battlesnake",
-		Version:     1,
+		Version:     version,
 		MinPlayers:  1,
 		MaxPlayers:  6,
 		BoardSizes:  FixedSizes(Dimensions{19, 21}),
 		Tags:        []string{TAG_FOOD_PLACEMENT, TAG_HAZARD_PLACEMENT, TAG_SNAKE_PLACEMENT},
+		Parameters: []Parameter{
+			hazardDamageParameter,
+			{
+				Name:        rules.ParamMinimumFood,
+				Type:        ParamTypeInt,
+				Default:     "0",
+				Description: minimumFoodDescription,
+			},
+			{
+				Name:        rules.ParamFoodSpawnChance,
+				Type:        ParamTypeInt,
+				Default:     "0",
+				Description: "Percent chance each turn that a food spawns at one of the fixed food positions",
+			},
+		},
 	}
 }
 
@@ -76,6 +117,10 @@ func (m ArcadeMazeMap) PreUpdateBoard(lastBoardState *rules.BoardState, settings
 }
 
 func (m ArcadeMazeMap) PostUpdateBoard(lastBoardState *rules.BoardState, settings rules.Settings, editor Editor) error {
+	if !m.chanceOnlyFood {
+		return FixedFoodSpawner{Positions: ArcadeMazeFoodSpawns}.SpawnFood(lastBoardState, settings, editor)
+	}
+
 	rand := settings.GetRand(lastBoardState.Turn)
 
 	// Respect FoodSpawnChance setting
@@ -84,20 +129,7 @@ func (m ArcadeMazeMap) PostUpdateBoard(lastBoardState *rules.BoardState, setting
 		return nil
 	}
 
-	foodPositions := []rules.Point{
-		{X: 1, Y: 1},
-		{X: 3, Y: 11},
-		{X: 4, Y: 7},
-		{X: 4, Y: 17},
-		{X: 9, Y: 1},
-		{X: 9, Y: 5},
-		{X: 9, Y: 11},
-		{X: 9, Y: 17},
-		{X: 14, Y: 7},
-		{X: 14, Y: 17},
-		{X: 15, Y: 11},
-		{X: 17, Y: 1},
-	}
+	foodPositions := append([]rules.Point{}, ArcadeMazeFoodSpawns...)
 
 	rand.Shuffle(len(foodPositions), func(i int, j int) {
 		foodPositions[i], foodPositions[j] = foodPositions[j], foodPositions[i]
@@ -0,0 +1,221 @@
+package maps
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// The ASCII map format is a way of drawing a map instead of listing its points.
+// It compiles to a MapDefinition, so ASCII maps behave exactly like JSON and YAML map files.
+//
+// A map starts with "key: value" header lines, followed by one grid per supported
+// board size. Each grid starts with a "[WxH]" line, and has one character per square,
+// with the top row of the board (y = H-1) first:
+//
+//	id: tiny_rivers
+//	name: Tiny Rivers
+//	author: Battlesnake
+//	maxPlayers: 4
+//	initialFood: fixed
+//
+//	[7x7]
+//	...#...
+//	.C.#.D.
+//	...#...
+//	###.###
+//	...#...
+//	.A.#.B.
+//	...#...
+//
+// Grid characters:
+//
+//	.    empty square
+//	#    hazard
+//	2-9  a stack of that many hazards
+//	X    wall
+//	*    food placed when the game starts
+//	+    food spawn point
+//	@    snake start
+//	A-D  snake start in one of four start quadrants, which must all have the same number of starts
+//
+// Header keys:
+//
+//	id, name, author, description   map metadata
+//	version, minPlayers, maxPlayers map metadata, as numbers
+//	tags                            comma separated tags
+//	food                            food placement, standard or none
+//	initialFood                     layout or fixed
+//	minimumFood, spawnChance        food settings overrides, as numbers
+//	foodOnHazards                   true to allow food to spawn on hazards
+//
+// Blank lines and lines starting with "//" are ignored. Spaces between grid characters
+// are allowed, so that grids can be drawn with square-looking cells.
+
+// ParseASCIIMap parses a map in the ASCII map format.
+func ParseASCIIMap(text string) (*FileMap, error) {
+	definition, err := ParseASCIIMapDefinition(text)
+	if err != nil {
+		return nil, err
+	}
+	return NewFileMap(definition)
+}
+
+// ParseASCIIMapDefinition compiles a map in the ASCII map format into a MapDefinition, without checking it.
+func ParseASCIIMapDefinition(text string) (MapDefinition, error) {
+	var definition MapDefinition
+	var layout *MapLayout
+	var rows []string
+	layoutLine := 0
+
+	finishLayout := func() error {
+		if layout == nil {
+			return nil
+		}
+		if err := layout.fillFromASCII(rows); err != nil {
+			return fmt.Errorf("ascii map line %d: [%s]: %w", layoutLine, layout.Size, err)
+		}
+		definition.Layouts = append(definition.Layouts, *layout)
+		return nil
+	}
+
+	for lineNumber, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "//") {
+			continue
+		}
+		lineErr := func(format string, args ...interface{}) error {
+			return fmt.Errorf("ascii map line %d: %s", lineNumber+1, fmt.Sprintf(format, args...))
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			if err := finishLayout(); err != nil {
+				return definition, err
+			}
+			layout = &MapLayout{Size: strings.TrimSpace(line[1 : len(line)-1])}
+			rows = nil
+			layoutLine = lineNumber + 1
+			continue
+		}
+
+		if layout != nil {
+			rows = append(rows, strings.ReplaceAll(line, " ", ""))
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return definition, lineErr("expected a 'key: value' header or a [WxH] grid")
+		}
+		if err := definition.setASCIIHeader(strings.TrimSpace(key), strings.TrimSpace(value)); err != nil {
+			return definition, lineErr("%s", err)
+		}
+	}
+	if err := finishLayout(); err != nil {
+		return definition, err
+	}
+
+	return definition, nil
+}
+
+func (definition *MapDefinition) setASCIIHeader(key, value string) error {
+	intValue := func() (int, error) {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return 0, fmt.Errorf("%s must be a number, not %q", key, value)
+		}
+		return n, nil
+	}
+
+	var err error
+	switch key {
+	case "id":
+		definition.ID = value
+	case "name":
+		definition.Meta.Name = value
+	case "author":
+		definition.Meta.Author = value
+	case "description":
+		definition.Meta.Description = value
+	case "version":
+		definition.Meta.Version, err = intValue()
+	case "minPlayers":
+		definition.Meta.MinPlayers, err = intValue()
+	case "maxPlayers":
+		definition.Meta.MaxPlayers, err = intValue()
+	case "tags":
+		definition.Meta.Tags = nil
+		for _, tag := range strings.Split(value, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				definition.Meta.Tags = append(definition.Meta.Tags, tag)
+			}
+		}
+	case "food":
+		definition.Food.Placement = value
+	case "initialFood":
+		definition.Food.Initial = value
+	case "minimumFood":
+		var n int
+		n, err = intValue()
+		definition.Food.MinimumFood = &n
+	case "spawnChance":
+		var n int
+		n, err = intValue()
+		definition.Food.SpawnChance = &n
+	case "foodOnHazards":
+		definition.Food.OnHazards, err = strconv.ParseBool(value)
+	default:
+		return fmt.Errorf("unknown header %q", key)
+	}
+	return err
+}
+
+// fillFromASCII adds the points drawn in a grid to the layout.
+func (layout *MapLayout) fillFromASCII(rows []string) error {
+	size, err := ParseDimensions(layout.Size)
+	if err != nil {
+		return err
+	}
+	if len(rows) != size.Height {
+		return fmt.Errorf("expected %d rows, found %d", size.Height, len(rows))
+	}
+
+	var quadrants [4][]MapPoint
+	hasQuadrants := false
+	for i, row := range rows {
+		y := size.Height - 1 - i
+		if len(row) != size.Width {
+			return fmt.Errorf("row %d should have %d squares, found %d", i+1, size.Width, len(row))
+		}
+		for x, c := range row {
+			p := MapPoint{x, y}
+			switch {
+			case c == '.':
+			case c == '#':
+				layout.Hazards = append(layout.Hazards, p)
+			case c >= '2' && c <= '9':
+				for n := '0'; n < c; n++ {
+					layout.Hazards = append(layout.Hazards, p)
+				}
+			case c == 'X':
+				layout.Walls = append(layout.Walls, p)
+			case c == '*':
+				layout.Food = append(layout.Food, p)
+			case c == '+':
+				layout.FoodSpawns = append(layout.FoodSpawns, p)
+			case c == '@':
+				layout.SnakeStarts = append(layout.SnakeStarts, p)
+			case c >= 'A' && c <= 'D':
+				quadrants[c-'A'] = append(quadrants[c-'A'], p)
+				hasQuadrants = true
+			default:
+				return fmt.Errorf("unknown character %q at [%d, %d]", c, x, y)
+			}
+		}
+	}
+
+	if hasQuadrants {
+		layout.StartQuadrants = quadrants[:]
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package maps
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Pikle2/rules"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseASCIIMapDefinition(t *testing.T) {
+	definition, err := ParseASCIIMapDefinition(`
+id: tiny
+name: Tiny
+version: 2
+maxPlayers: 4
+tags: hazard-placement, food-placement
+food: none
+minimumFood: 3
+
+[3x4]
+A . B
+2 X #
+* + @
+C . D
+
+// layouts for other sizes follow
+[1x1]
+.
+`)
+	require.NoError(t, err)
+
+	minimumFood := 3
+	require.Equal(t, MapDefinition{
+		ID: "tiny",
+		Meta: MapFileMeta{
+			Name:       "Tiny",
+			Version:    2,
+			MaxPlayers: 4,
+			Tags:       []string{TAG_HAZARD_PLACEMENT, TAG_FOOD_PLACEMENT},
+		},
+		Food: MapFoodRules{
+			Placement:   FoodPlacementNone,
+			MinimumFood: &minimumFood,
+		},
+		Layouts: []MapLayout{
+			{
+				Size:        "3x4",
+				SnakeStarts: []MapPoint{{2, 1}},
+				StartQuadrants: [][]MapPoint{
+					{{0, 3}},
+					{{2, 3}},
+					{{0, 0}},
+					{{2, 0}},
+				},
+				Food:       []MapPoint{{0, 1}},
+				Hazards:    []MapPoint{{0, 2}, {0, 2}, {2, 2}},
+				Walls:      []MapPoint{{1, 2}},
+				FoodSpawns: []MapPoint{{1, 1}},
+			},
+			{Size: "1x1"},
+		},
+	}, definition)
+
+	// Starts and quadrants can't be mixed
+	_, err = NewFileMap(definition)
+	require.EqualError(t, err, "map tiny: layouts[0]: snakeStarts and startQuadrants can't be used together")
+}
+
+func TestParseASCIIMapErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		err  string
+	}{
+		{"not a header", "id: x\nhello", "ascii map line 2: expected a 'key: value' header or a [WxH] grid"},
+		{"unknown header", "colour: red", `ascii map line 1: unknown header "colour"`},
+		{"number", "id: x\nversion: one", `ascii map line 2: version must be a number, not "one"`},
+		{"bool", "foodOnHazards: maybe", `ascii map line 1: strconv.ParseBool: parsing "maybe": invalid syntax`},
+		{"size", "id: x\n\n[3]\n...", `ascii map line 3: [3]: invalid board size "3", expected WIDTHxHEIGHT`},
+		{"rows", "id: x\n[3x2]\n...", "ascii map line 2: [3x2]: expected 2 rows, found 1"},
+		{"row width", "id: x\n[3x2]\n...\n..", "ascii map line 2: [3x2]: row 2 should have 3 squares, found 2"},
+		{"character", "id: x\n[3x2]\n...\n.?.", `ascii map line 2: [3x2]: unknown character '?' at [1, 0]`},
+		{"quadrants", "id: x\n[3x2]\nA.B\nC..", "map x: layouts[0]: startQuadrants must all have the same number of positions"},
+		{"initial food", "id: x\ninitialFood: lots\n[1x1]\n.", `map x: unknown initial food "lots"`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParseASCIIMap(test.text)
+			require.EqualError(t, err, test.err)
+		})
+	}
+}
+
+func TestASCIIRiversAndBridges(t *testing.T) {
+	m, err := LoadMapFile("testdata/rivers_and_bridges.txt")
+	require.NoError(t, err)
+	require.Equal(t, "ascii_rivers_bridges", m.ID())
+	require.Equal(t, RiverAndBridgesMediumHazardsMap{}.Meta().BoardSizes, m.Meta().BoardSizes)
+	require.Equal(t, 8, m.Meta().MaxPlayers)
+
+	// The drawn map has the same hazards and start positions as the Go one
+	layout := m.Definition().Layouts[0]
+	require.ElementsMatch(t, riversAndBridgesMediumHazards, mapPoints(layout.Hazards))
+	require.Len(t, layout.StartQuadrants, 4)
+	for i, quadrant := range riversAndBridgesMediumStartPositions {
+		require.ElementsMatch(t, quadrant, mapPoints(layout.StartQuadrants[i]))
+	}
+
+	initialBoardState := rules.NewBoardState(11, 11)
+	for _, id := range []string{"1", "2", "3", "4", "5"} {
+		initialBoardState.Snakes = append(initialBoardState.Snakes, rules.Snake{ID: id})
+	}
+	editor := NewBoardStateEditor(initialBoardState.Clone())
+	require.NoError(t, m.SetupBoard(initialBoardState, rules.Settings{}.WithSeed(3), editor))
+	boardState := editor.boardState
+
+	starts := map[rules.Point]bool{}
+	for _, quadrant := range riversAndBridgesMediumStartPositions {
+		for _, p := range quadrant {
+			starts[p] = true
+		}
+	}
+	for _, snake := range boardState.Snakes {
+		require.True(t, starts[snake.Body[0]])
+	}
+	// One food near each snake, but not in the centre because it's a hazard
+	require.Len(t, boardState.Food, 5)
+	require.NotContains(t, boardState.Food, rules.Point{X: 5, Y: 5})
+	for _, food := range boardState.Food {
+		require.NotContains(t, boardState.Hazards, food)
+	}
+}
+
+func TestASCIIMapFoodOnHazards(t *testing.T) {
+	for _, onHazards := range []bool{false, true} {
+		m, err := ParseASCIIMap(fmt.Sprintf("id: x\nminimumFood: 4\nfoodOnHazards: %t\n[2x2]\n#.\nX.", onHazards))
+		require.NoError(t, err)
+
+		boardState := rules.NewBoardState(2, 2)
+		boardState.Hazards = []rules.Point{{X: 0, Y: 1}, {X: 0, Y: 0}, {X: 0, Y: 0}}
+		editor := NewBoardStateEditor(boardState.Clone())
+		require.NoError(t, m.PostUpdateBoard(boardState, rules.Settings{}.WithSeed(1), editor))
+
+		// Food never spawns on walls, and only spawns on other hazards if allowed
+		expected := []rules.Point{{X: 1, Y: 1}, {X: 1, Y: 0}}
+		if onHazards {
+			expected = append(expected, rules.Point{X: 0, Y: 1})
+		}
+		require.ElementsMatch(t, expected, editor.boardState.Food)
+	}
+}
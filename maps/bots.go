@@ -0,0 +1,272 @@
+package maps
+
+import (
+	"fmt"
+
+	"github.com/Pikle2/rules"
+)
+
+// Built-in movement policies for bot snakes.
+const (
+	BotPolicyPatrol    = "patrol"    // keeps moving in the same direction, turning right when blocked
+	BotPolicyChaseFood = "chaseFood" // moves toward the closest reachable food
+	BotPolicyChaseHead = "chaseHead" // moves toward the closest reachable head of a snake that isn't a bot
+)
+
+// BotPolicy chooses the moves of bot snakes, which are added to the board by maps with EditorPlaceBot.
+type BotPolicy interface {
+	// Move returns the move for a bot snake. Any randomness must come from rand so that games can be replayed.
+	Move(boardState *rules.BoardState, snakeID string, rand rules.Rand) string
+}
+
+// BotPolicyFunc adapts a function to a BotPolicy.
+type BotPolicyFunc func(boardState *rules.BoardState, snakeID string, rand rules.Rand) string
+
+func (f BotPolicyFunc) Move(boardState *rules.BoardState, snakeID string, rand rules.Rand) string {
+	return f(boardState, snakeID, rand)
+}
+
+var botPolicies = map[string]BotPolicy{
+	BotPolicyPatrol:    BotPolicyFunc(patrolBotMove),
+	BotPolicyChaseFood: BotPolicyFunc(chaseFoodBotMove),
+	BotPolicyChaseHead: BotPolicyFunc(chaseHeadBotMove),
+}
+
+// RegisterBotPolicy adds a bot movement policy.
+// If a policy has already been registered with the same name this will panic.
+func RegisterBotPolicy(name string, policy BotPolicy) {
+	if err := RegisterBotPolicyError(name, policy); err != nil {
+		panic(err.Error())
+	}
+}
+
+// RegisterBotPolicyError adds a bot movement policy.
+// If a policy has already been registered with the same name an error will be returned.
+func RegisterBotPolicyError(name string, policy BotPolicy) error {
+	if _, ok := botPolicies[name]; ok {
+		return rules.RulesetError(fmt.Sprintf("bot policy '%s' has already been registered", name))
+	}
+	botPolicies[name] = policy
+	return nil
+}
+
+// GetBotPolicy returns the bot movement policy registered with the given name.
+func GetBotPolicy(name string) (BotPolicy, error) {
+	policy, ok := botPolicies[name]
+	if !ok {
+		return nil, rules.RulesetError(fmt.Sprintf("unknown bot policy '%s'", name))
+	}
+	return policy, nil
+}
+
+// BotMoves returns the moves of every bot snake that is still alive, to be executed along with the moves of the other snakes.
+func BotMoves(boardState *rules.BoardState, settings rules.Settings) ([]rules.SnakeMove, error) {
+	var moves []rules.SnakeMove
+	rand := settings.GetRand(boardState.Turn)
+	for _, snake := range boardState.Snakes {
+		if snake.EliminatedCause != rules.NotEliminated {
+			continue
+		}
+		bot, ok := rules.GetBot(boardState, snake.ID)
+		if !ok {
+			continue
+		}
+		policy, err := GetBotPolicy(bot.Policy)
+		if err != nil {
+			return nil, fmt.Errorf("bot %s: %w", snake.ID, err)
+		}
+		moves = append(moves, rules.SnakeMove{ID: snake.ID, Move: policy.Move(boardState, snake.ID, rand)})
+	}
+	return moves, nil
+}
+
+// botMoves lists the moves in clockwise order.
+var botMoves = []string{rules.MoveUp, rules.MoveRight, rules.MoveDown, rules.MoveLeft}
+
+func movePoint(p rules.Point, move string) rules.Point {
+	switch move {
+	case rules.MoveUp:
+		return rules.Point{X: p.X, Y: p.Y + 1}
+	case rules.MoveDown:
+		return rules.Point{X: p.X, Y: p.Y - 1}
+	case rules.MoveLeft:
+		return rules.Point{X: p.X - 1, Y: p.Y}
+	default:
+		return rules.Point{X: p.X + 1, Y: p.Y}
+	}
+}
+
+// turnRight returns the moves starting with the given move and turning clockwise.
+func turnRight(move string) []string {
+	for i, m := range botMoves {
+		if m == move {
+			return append(append([]string{}, botMoves[i:]...), botMoves[:i]...)
+		}
+	}
+	return append([]string{}, botMoves...)
+}
+
+// botHeading returns the direction a snake last moved in, or up if it hasn't moved yet.
+func botHeading(body []rules.Point) string {
+	if len(body) < 2 {
+		return rules.MoveUp
+	}
+	for _, move := range botMoves {
+		if movePoint(body[1], move) == body[0] {
+			return move
+		}
+	}
+	return rules.MoveUp
+}
+
+// botState is what a bot needs to know about the board to choose a move.
+type botState struct {
+	board   *rules.BoardState
+	snake   rules.Snake
+	blocked map[rules.Point]bool
+	hazards map[rules.Point]bool
+}
+
+func newBotState(boardState *rules.BoardState, snakeID string) (botState, bool) {
+	state := botState{
+		board:   boardState,
+		blocked: map[rules.Point]bool{},
+		hazards: map[rules.Point]bool{},
+	}
+	found := false
+	for _, snake := range boardState.Snakes {
+		if snake.EliminatedCause != rules.NotEliminated || len(snake.Body) == 0 {
+			continue
+		}
+		if snake.ID == snakeID {
+			state.snake = snake
+			found = true
+		}
+		// Tails move out of the way, unless the snake just ate and its tail is stacked
+		for _, p := range snake.Body[:len(snake.Body)-1] {
+			state.blocked[p] = true
+		}
+	}
+	for _, p := range boardState.Hazards {
+		state.hazards[p] = true
+	}
+	return state, found
+}
+
+func (state botState) open(p rules.Point) bool {
+	return isOnBoard(state.board.Width, state.board.Height, p.X, p.Y) && !state.blocked[p]
+}
+
+// safety scores a square to move onto: squares that would eliminate the bot score 0,
+// hazards score 1 and every other square scores 2.
+func (state botState) safety(p rules.Point) int {
+	if !state.open(p) {
+		return 0
+	}
+	if state.hazards[p] {
+		return 1
+	}
+	return 2
+}
+
+// bestMove returns the first of the preferred moves with the highest safety.
+func (state botState) bestMove(preferred []string) string {
+	best, bestSafety := preferred[0], -1
+	for _, move := range preferred {
+		if safety := state.safety(movePoint(state.snake.Body[0], move)); safety > bestSafety {
+			best, bestSafety = move, safety
+		}
+	}
+	return best
+}
+
+// patrolMoves prefers going straight, then turning right, then left.
+func (state botState) patrolMoves() []string {
+	moves := turnRight(botHeading(state.snake.Body))
+	return []string{moves[0], moves[1], moves[3], moves[2]}
+}
+
+// stepToward searches outward from the bot's head and returns the first move of a shortest path to any of the targets.
+// The targets themselves don't need to be open squares.
+func (state botState) stepToward(targets map[rules.Point]bool, rand rules.Rand) (string, bool) {
+	order := append([]string{}, botMoves...)
+	rand.Shuffle(len(order), func(i, j int) {
+		order[i], order[j] = order[j], order[i]
+	})
+
+	type step struct {
+		p     rules.Point
+		first string
+	}
+	head := state.snake.Body[0]
+	seen := map[rules.Point]bool{head: true}
+	var queue []step
+	for _, move := range order {
+		queue = append(queue, step{movePoint(head, move), move})
+	}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if seen[current.p] {
+			continue
+		}
+		seen[current.p] = true
+		if targets[current.p] {
+			return current.first, true
+		}
+		if !state.open(current.p) {
+			continue
+		}
+		for _, move := range order {
+			queue = append(queue, step{movePoint(current.p, move), current.first})
+		}
+	}
+	return "", false
+}
+
+// chaseMoves prefers the first step toward the closest target, then patrols if no target can be reached.
+func (state botState) chaseMoves(targets map[rules.Point]bool, rand rules.Rand) []string {
+	patrol := state.patrolMoves()
+	move, ok := state.stepToward(targets, rand)
+	if !ok {
+		return patrol
+	}
+	return append([]string{move}, patrol...)
+}
+
+func patrolBotMove(boardState *rules.BoardState, snakeID string, rand rules.Rand) string {
+	state, ok := newBotState(boardState, snakeID)
+	if !ok {
+		return rules.MoveUp
+	}
+	return state.bestMove(state.patrolMoves())
+}
+
+func chaseFoodBotMove(boardState *rules.BoardState, snakeID string, rand rules.Rand) string {
+	state, ok := newBotState(boardState, snakeID)
+	if !ok {
+		return rules.MoveUp
+	}
+	food := map[rules.Point]bool{}
+	for _, p := range boardState.Food {
+		food[p] = true
+	}
+	return state.bestMove(state.chaseMoves(food, rand))
+}
+
+func chaseHeadBotMove(boardState *rules.BoardState, snakeID string, rand rules.Rand) string {
+	state, ok := newBotState(boardState, snakeID)
+	if !ok {
+		return rules.MoveUp
+	}
+	heads := map[rules.Point]bool{}
+	for _, snake := range boardState.Snakes {
+		if snake.ID == snakeID || snake.EliminatedCause != rules.NotEliminated || len(snake.Body) == 0 {
+			continue
+		}
+		if _, isBot := rules.GetBot(boardState, snake.ID); !isBot {
+			heads[snake.Body[0]] = true
+		}
+	}
+	return state.bestMove(state.chaseMoves(heads, rand))
+}
@@ -0,0 +1,137 @@
+package maps
+
+import (
+	"testing"
+
+	"github.com/Pikle2/rules"
+	"github.com/stretchr/testify/require"
+)
+
+func newBotBoard(bot rules.Snake, policy string, others ...rules.Snake) *rules.BoardState {
+	b := rules.NewBoardState(7, 7).WithSnakes(append([]rules.Snake{bot}, others...))
+	rules.SetBot(b, bot.ID, rules.Bot{Policy: policy})
+	return b
+}
+
+func TestPatrolBot(t *testing.T) {
+	policy, err := GetBotPolicy(BotPolicyPatrol)
+	require.NoError(t, err)
+	rand := rules.MinRand
+
+	// Keeps going in the same direction
+	bot := rules.Snake{ID: "bot", Body: []rules.Point{{X: 3, Y: 3}, {X: 2, Y: 3}, {X: 1, Y: 3}}}
+	require.Equal(t, rules.MoveRight, policy.Move(newBotBoard(bot, BotPolicyPatrol), "bot", rand))
+
+	// Turns right at walls and left when the right is blocked
+	bot.Body = []rules.Point{{X: 6, Y: 3}, {X: 5, Y: 3}, {X: 4, Y: 3}}
+	require.Equal(t, rules.MoveDown, policy.Move(newBotBoard(bot, BotPolicyPatrol), "bot", rand))
+	other := rules.Snake{ID: "other", Body: []rules.Point{{X: 5, Y: 1}, {X: 5, Y: 2}, {X: 6, Y: 2}, {X: 6, Y: 1}}}
+	require.Equal(t, rules.MoveUp, policy.Move(newBotBoard(bot, BotPolicyPatrol, other), "bot", rand))
+
+	// Avoids hazards when there's another way
+	b := newBotBoard(bot, BotPolicyPatrol)
+	b.Hazards = []rules.Point{{X: 6, Y: 2}}
+	require.Equal(t, rules.MoveUp, policy.Move(b, "bot", rand))
+
+	// Snakes that haven't moved yet go up
+	bot.Body = []rules.Point{{X: 3, Y: 3}, {X: 3, Y: 3}, {X: 3, Y: 3}}
+	require.Equal(t, rules.MoveUp, policy.Move(newBotBoard(bot, BotPolicyPatrol), "bot", rand))
+}
+
+func TestChaseBots(t *testing.T) {
+	rand := rules.MinRand
+	bot := rules.Snake{ID: "bot", Body: []rules.Point{{X: 3, Y: 3}, {X: 3, Y: 2}, {X: 3, Y: 1}}}
+
+	chaseFood, err := GetBotPolicy(BotPolicyChaseFood)
+	require.NoError(t, err)
+	b := newBotBoard(bot, BotPolicyChaseFood)
+	b.Food = []rules.Point{{X: 0, Y: 3}, {X: 6, Y: 6}}
+	require.Equal(t, rules.MoveLeft, chaseFood.Move(b, "bot", rand))
+
+	// The path goes around snake bodies
+	wall := rules.Snake{ID: "wall", Body: []rules.Point{{X: 2, Y: 4}, {X: 2, Y: 3}, {X: 2, Y: 2}, {X: 2, Y: 1}, {X: 2, Y: 0}}}
+	b = newBotBoard(bot, BotPolicyChaseFood, wall)
+	b.Food = []rules.Point{{X: 0, Y: 3}}
+	require.Equal(t, rules.MoveUp, chaseFood.Move(b, "bot", rand))
+
+	// Without food it patrols
+	require.Equal(t, rules.MoveUp, chaseFood.Move(newBotBoard(bot, BotPolicyChaseFood), "bot", rand))
+
+	// Chases the closest snake that isn't a bot
+	chaseHead, err := GetBotPolicy(BotPolicyChaseHead)
+	require.NoError(t, err)
+	player := rules.Snake{ID: "player", Body: []rules.Point{{X: 6, Y: 3}, {X: 6, Y: 2}}}
+	otherBot := rules.Snake{ID: "otherBot", Body: []rules.Point{{X: 3, Y: 5}, {X: 3, Y: 6}}}
+	b = newBotBoard(bot, BotPolicyChaseHead, player, otherBot)
+	rules.SetBot(b, "otherBot", rules.Bot{Policy: BotPolicyPatrol})
+	require.Equal(t, rules.MoveRight, chaseHead.Move(b, "bot", rand))
+}
+
+func TestBotMoves(t *testing.T) {
+	bot := rules.Snake{ID: "bot", Body: []rules.Point{{X: 3, Y: 3}, {X: 2, Y: 3}}}
+	dead := rules.Snake{ID: "dead", Body: []rules.Point{{X: 0, Y: 0}}, EliminatedCause: rules.EliminatedByCollision}
+	player := rules.Snake{ID: "player", Body: []rules.Point{{X: 5, Y: 5}}}
+	b := newBotBoard(bot, BotPolicyPatrol, dead, player)
+	rules.SetBot(b, "dead", rules.Bot{Policy: BotPolicyPatrol})
+
+	// Only bots that are still alive are moved
+	moves, err := BotMoves(b, rules.Settings{}.WithSeed(1))
+	require.NoError(t, err)
+	require.Equal(t, []rules.SnakeMove{{ID: "bot", Move: rules.MoveRight}}, moves)
+
+	rules.SetBot(b, "bot", rules.Bot{Policy: "wander"})
+	_, err = BotMoves(b, rules.Settings{}.WithSeed(1))
+	require.EqualError(t, err, "bot bot: unknown bot policy 'wander'")
+}
+
+func TestRegisterBotPolicy(t *testing.T) {
+	standStill := BotPolicyFunc(func(boardState *rules.BoardState, snakeID string, rand rules.Rand) string {
+		return rules.MoveDown
+	})
+	require.NoError(t, RegisterBotPolicyError("test_standStill", standStill))
+	defer delete(botPolicies, "test_standStill")
+
+	policy, err := GetBotPolicy("test_standStill")
+	require.NoError(t, err)
+	require.Equal(t, rules.MoveDown, policy.Move(nil, "bot", rules.MinRand))
+
+	require.EqualError(t, RegisterBotPolicyError(BotPolicyPatrol, standStill), "bot policy 'patrol' has already been registered")
+	require.Panics(t, func() { RegisterBotPolicy(BotPolicyPatrol, standStill) })
+}
+
+func TestSnakeBotsMap(t *testing.T) {
+	settings := rules.NewSettingsWithParams(ParamBotCount, "3", ParamBotPolicy, BotPolicyChaseHead).WithSeed(1)
+	boardState, err := SetupBoard("snake_bots", settings, 11, 11, []string{"1", "2"})
+	require.NoError(t, err)
+	require.Len(t, boardState.Snakes, 5)
+
+	for _, snake := range boardState.Snakes[2:] {
+		bot, ok := rules.GetBot(boardState, snake.ID)
+		require.True(t, ok)
+		require.Equal(t, rules.Bot{Policy: BotPolicyChaseHead, Name: "Bot " + snake.ID[len("bot-"):], ExcludeFromWin: true}, bot)
+		for _, other := range boardState.Snakes {
+			if other.ID != snake.ID {
+				require.GreaterOrEqual(t, manhattanDistance(snake.Body[0], other.Body[0]), minBotDistance)
+			}
+		}
+	}
+
+	// Bots are fed every turn, players aren't
+	boardState.Snakes[0].Health = 50
+	boardState.Snakes[2].Health = 50
+	next, err := PostUpdateBoard(SnakeBotsMap{}, boardState, settings)
+	require.NoError(t, err)
+	require.Equal(t, 50, next.Snakes[0].Health)
+	require.Equal(t, rules.SnakeMaxHealth, next.Snakes[2].Health)
+	require.Equal(t, boardState.Snakes[2].Body, next.Snakes[2].Body)
+
+	boardState, err = SetupBoard("snake_bots", rules.NewSettingsWithParams(ParamBotsCanWin, "true"), 11, 11, []string{"1"})
+	require.NoError(t, err)
+	bot, _ := rules.GetBot(boardState, "bot-1")
+	require.Equal(t, rules.Bot{Policy: BotPolicyChaseFood, Name: "Bot 1"}, bot)
+
+	_, err = SetupBoard("snake_bots", rules.NewSettingsWithParams(ParamBotCount, "9"), 11, 11, []string{"1"})
+	require.EqualError(t, err, "bot count must be between 0 and 8")
+	_, err = SetupBoard("snake_bots", rules.NewSettingsWithParams(ParamBotPolicy, "wander"), 11, 11, []string{"1"})
+	require.EqualError(t, err, "unknown bot policy 'wander'")
+}
@@ -119,6 +119,7 @@ func (m CastleWallMediumHazardsMap) Meta() Metadata {
 		MaxPlayers:  8,
 		BoardSizes:  FixedSizes(Dimensions{11, 11}),
 		Tags:        []string{TAG_FOOD_PLACEMENT, TAG_HAZARD_PLACEMENT, TAG_SNAKE_PLACEMENT},
+		Parameters:  []Parameter{hazardDamageParameter},
 	}
 }
 
@@ -215,6 +216,7 @@ func (m CastleWallLargeHazardsMap) Meta() Metadata {
 		MaxPlayers:  8,
 		BoardSizes:  FixedSizes(Dimensions{19, 19}),
 		Tags:        []string{TAG_FOOD_PLACEMENT, TAG_HAZARD_PLACEMENT, TAG_SNAKE_PLACEMENT},
+		Parameters:  []Parameter{hazardDamageParameter},
 	}
 }
 
@@ -407,6 +409,7 @@ func (m CastleWallExtraLargeHazardsMap) Meta() Metadata {
 		MaxPlayers:  12,
 		BoardSizes:  FixedSizes(Dimensions{25, 25}),
 		Tags:        []string{TAG_FOOD_PLACEMENT, TAG_HAZARD_PLACEMENT, TAG_SNAKE_PLACEMENT},
+		Parameters:  []Parameter{hazardDamageParameter},
 	}
 }
 
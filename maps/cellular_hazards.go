@@ -0,0 +1,425 @@
+package maps
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Pikle2/rules"
+)
+
+// Settings for maps with hazards that evolve like a cellular automaton.
+const (
+	ParamAutomatonRule     = "automatonRule"     // birth/survival rule, e.g. B3/S23
+	ParamAutomatonDensity  = "automatonDensity"  // percent of the board seeded with hazards at the start
+	ParamEvolveEveryNTurns = "evolveEveryNTurns" // turns between each generation
+)
+
+const (
+	// automatonCellsKey is the MapState key for the live cells of the automaton.
+	automatonCellsKey = "cells"
+	// automatonSpawnsKey is the MapState key for the starting heads of the snakes.
+	automatonSpawnsKey = "spawns"
+
+	// spawnClearance is the distance around each starting head, in any direction, that is kept free of hazards
+	// until spawnProtectionTurns.
+	spawnClearance       = 2
+	spawnProtectionTurns = 10
+
+	// automatonSeedAttempts is the number of times the board is seeded before giving up on seeding any hazards,
+	// if every seed would seal a snake into too small an area.
+	automatonSeedAttempts = 10
+)
+
+func init() {
+	globalRegistry.RegisterMap("hz_life", CellularHazardsMap{
+		MapID:       "hz_life",
+		Name:        "Game of Life",
+		Description: "Hazards are born, survive and die each turn following Conway's Game of Life",
+		Rule:        "B3/S23",
+		Density:     35,
+		Every:       1,
+	})
+	globalRegistry.RegisterMap("hz_coral", CellularHazardsMap{
+		MapID:       "hz_coral",
+		Name:        "Coral",
+		Description: "Hazards slowly grow into branching coral that rarely dies back",
+		Rule:        "B3/S45678",
+		Density:     20,
+		Every:       2,
+	})
+	globalRegistry.RegisterMap("hz_lava", CellularHazardsMap{
+		MapID:       "hz_lava",
+		Name:        "Lava Flow",
+		Description: "Lava wells up from vents and flows towards the most open space on the board",
+		Rule:        "B1/S012345678",
+		Vents:       2,
+		Flow:        1,
+		Every:       1,
+	})
+}
+
+// AutomatonRule is a birth/survival rule for a cellular automaton, where each square of the board is a cell
+// and its neighbours are the 8 squares around it.
+type AutomatonRule struct {
+	// Birth is indexed by the number of live neighbours, and reports whether a dead cell comes to life.
+	Birth [9]bool
+	// Survival is indexed by the number of live neighbours, and reports whether a live cell stays alive.
+	Survival [9]bool
+}
+
+// ParseAutomatonRule parses a rule in B/S notation, such as B3/S23 for Conway's Game of Life.
+func ParseAutomatonRule(value string) (AutomatonRule, error) {
+	var rule AutomatonRule
+	parts := strings.Split(strings.ToUpper(value), "/")
+	if len(parts) != 2 || !strings.HasPrefix(parts[0], "B") || !strings.HasPrefix(parts[1], "S") {
+		return rule, rules.RulesetError(fmt.Sprintf("invalid automaton rule '%s', expected a rule like B3/S23", value))
+	}
+	for i, counts := range []*[9]bool{&rule.Birth, &rule.Survival} {
+		for _, c := range parts[i][1:] {
+			if c < '0' || c > '8' {
+				return rule, rules.RulesetError(fmt.Sprintf("invalid automaton rule '%s', neighbour counts must be 0 to 8", value))
+			}
+			counts[c-'0'] = true
+		}
+	}
+	return rule, nil
+}
+
+// String returns the rule in B/S notation.
+func (rule AutomatonRule) String() string {
+	var birth, survival strings.Builder
+	for n := 0; n <= 8; n++ {
+		if rule.Birth[n] {
+			birth.WriteByte(byte('0' + n))
+		}
+		if rule.Survival[n] {
+			survival.WriteByte(byte('0' + n))
+		}
+	}
+	return "B" + birth.String() + "/S" + survival.String()
+}
+
+// CellularHazardsMap is a family of maps where every hazard square is a live cell of a cellular automaton,
+// and the hazards evolve every few turns following a birth/survival rule.
+// The rule, density and speed can be changed with settings.
+type CellularHazardsMap struct {
+	MapID       string
+	Name        string
+	Description string
+	// Rule is the default birth/survival rule.
+	Rule string
+	// Density is the default percent of free squares that start as hazards.
+	Density int
+	// Vents is the number of single hazards to start with instead of seeding by density.
+	Vents int
+	// Flow limits the number of cells born each generation, preferring the cells with the most open space around them,
+	// so that hazards flow towards open space. If 0 every cell that the rule allows is born.
+	Flow int
+	// Every is the default number of turns between each generation.
+	Every int
+}
+
+func (m CellularHazardsMap) ID() string {
+	return m.MapID
+}
+
+func (m CellularHazardsMap) Meta() Metadata {
+	params := []Parameter{
+		hazardDamageParameter,
+		foodSpawnerParameter,
+		{
+			Name:        ParamAutomatonRule,
+			Type:        ParamTypeString,
+			Default:     m.Rule,
+			Description: "Birth/survival rule for hazards, as the numbers of neighbouring hazards that create or keep a hazard",
+		},
+		{
+			Name:        ParamEvolveEveryNTurns,
+			Type:        ParamTypeInt,
+			Default:     fmt.Sprint(m.Every),
+			Description: "Turns between each generation of hazards",
+		},
+	}
+	if m.Vents == 0 {
+		params = append(params, Parameter{
+			Name:        ParamAutomatonDensity,
+			Type:        ParamTypeInt,
+			Default:     fmt.Sprint(m.Density),
+			Description: "Percent of the board that starts as hazards",
+		})
+	}
+	return Metadata{
+		Name:        m.Name,
+		Description: m.Description,
+		Author:      "Battlesnake",
+		Version:     1,
+		MinPlayers:  1,
+		MaxPlayers:  8,
+		BoardSizes:  OddSizes(rules.BoardSizeSmall, rules.BoardSizeXXLarge),
+		Tags:        []string{TAG_EXPERIMENTAL, TAG_HAZARD_PLACEMENT},
+		Parameters:  params,
+	}
+}
+
+// settings reads the rule, speed and starting density of the automaton.
+func (m CellularHazardsMap) settings(settings rules.Settings) (AutomatonRule, int, int, error) {
+	value := settings.Params()[ParamAutomatonRule]
+	if value == "" {
+		value = m.Rule
+	}
+	rule, err := ParseAutomatonRule(value)
+	if err != nil {
+		return rule, 0, 0, err
+	}
+	every := settings.Int(ParamEvolveEveryNTurns, m.Every)
+	if every < 1 {
+		return rule, 0, 0, rules.RulesetError(fmt.Sprintf("%s must be at least 1", ParamEvolveEveryNTurns))
+	}
+	density := settings.Int(ParamAutomatonDensity, m.Density)
+	if density < 0 || density > 100 {
+		return rule, 0, 0, rules.RulesetError(fmt.Sprintf("%s must be between 0 and 100", ParamAutomatonDensity))
+	}
+	return rule, every, density, nil
+}
+
+func (m CellularHazardsMap) SetupBoard(initialBoardState *rules.BoardState, settings rules.Settings, editor Editor) error {
+	if err := m.Meta().Validate(initialBoardState); err != nil {
+		return err
+	}
+	_, _, density, err := m.settings(settings)
+	if err != nil {
+		return err
+	}
+	if err := (StandardMap{}).SetupBoard(initialBoardState, settings, editor); err != nil {
+		return err
+	}
+
+	var spawns []rules.Point
+	for _, body := range editor.SnakeBodies() {
+		if len(body) > 0 {
+			spawns = append(spawns, body[0])
+		}
+	}
+	sortPoints(spawns)
+
+	cells := m.seed(settings.GetRand(0), initialBoardState.Width, initialBoardState.Height, density, spawns)
+	for _, p := range cells {
+		editor.AddHazard(p)
+	}
+	removeFoodUnderHazards(editor)
+
+	state := EditorMapState(editor, m.ID())
+	state.SetPoints(automatonCellsKey, cells)
+	state.SetPoints(automatonSpawnsKey, spawns)
+	return nil
+}
+
+// seed picks the starting cells, away from the snakes' starting positions. Seeds that would leave any snake
+// with less than half of the board to move around in without crossing hazards are thrown away.
+func (m CellularHazardsMap) seed(rand rules.Rand, width, height, density int, spawns []rules.Point) []rules.Point {
+	var free []rules.Point
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			p := rules.Point{X: x, Y: y}
+			if !nearSpawn(p, spawns) {
+				free = append(free, p)
+			}
+		}
+	}
+
+	count := m.Vents
+	if count == 0 {
+		count = len(free) * density / 100
+	}
+	if count > len(free) {
+		count = len(free)
+	}
+
+	for attempt := 0; attempt < automatonSeedAttempts; attempt++ {
+		rand.Shuffle(len(free), func(i, j int) {
+			free[i], free[j] = free[j], free[i]
+		})
+		cells := append([]rules.Point{}, free[:count]...)
+		sortPoints(cells)
+		if !sealsSpawns(cells, width, height, spawns) {
+			return cells
+		}
+	}
+	return []rules.Point{}
+}
+
+func (m CellularHazardsMap) PreUpdateBoard(lastBoardState *rules.BoardState, settings rules.Settings, editor Editor) error {
+	return nil
+}
+
+func (m CellularHazardsMap) PostUpdateBoard(lastBoardState *rules.BoardState, settings rules.Settings, editor Editor) error {
+	rule, every, _, err := m.settings(settings)
+	if err != nil {
+		return err
+	}
+	spawner, err := FoodSpawnerFromSettings(settings, UniformFoodSpawner{AvoidHazards: true})
+	if err != nil {
+		return err
+	}
+	if err := spawner.SpawnFood(lastBoardState, settings, editor); err != nil {
+		return err
+	}
+
+	if lastBoardState.Turn > 0 && lastBoardState.Turn%every == 0 {
+		if err := m.evolveHazards(lastBoardState, settings, editor, rule); err != nil {
+			return err
+		}
+	}
+	// Food can't be left under hazards that were just born, or placed there by a spawner that doesn't avoid hazards
+	removeFoodUnderHazards(editor)
+	return nil
+}
+
+// evolveHazards replaces the hazards with the next generation of cells.
+func (m CellularHazardsMap) evolveHazards(lastBoardState *rules.BoardState, settings rules.Settings, editor Editor, rule AutomatonRule) error {
+	state := EditorMapState(editor, m.ID())
+	cells, err := state.Points(automatonCellsKey)
+	if err != nil {
+		return err
+	}
+	var spawns []rules.Point
+	if lastBoardState.Turn < spawnProtectionTurns {
+		if spawns, err = state.Points(automatonSpawnsKey); err != nil {
+			return err
+		}
+	}
+
+	next := m.evolve(settings.GetRand(lastBoardState.Turn), rule, cells, lastBoardState.Width, lastBoardState.Height, spawns)
+	for _, p := range cells {
+		editor.RemoveHazard(p)
+	}
+	for _, p := range next {
+		editor.AddHazard(p)
+	}
+	state.SetPoints(automatonCellsKey, next)
+	return nil
+}
+
+// evolve returns the next generation of cells. No cells are born near the given spawns.
+func (m CellularHazardsMap) evolve(rand rules.Rand, rule AutomatonRule, cells []rules.Point, width, height int, spawns []rules.Point) []rules.Point {
+	live := make(map[rules.Point]bool, len(cells))
+	for _, p := range cells {
+		live[p] = true
+	}
+
+	var next, births []rules.Point
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			p := rules.Point{X: x, Y: y}
+			n := liveNeighbours(p, live)
+			if live[p] {
+				if rule.Survival[n] {
+					next = append(next, p)
+				}
+			} else if rule.Birth[n] && !nearSpawn(p, spawns) {
+				births = append(births, p)
+			}
+		}
+	}
+
+	if m.Flow > 0 && len(births) > m.Flow {
+		// Lava flows towards the births with the most open squares around them, picking randomly between equally open squares
+		rand.Shuffle(len(births), func(i, j int) {
+			births[i], births[j] = births[j], births[i]
+		})
+		open := make(map[rules.Point]int, len(births))
+		for _, p := range births {
+			open[p] = openNeighbours(p, live, width, height)
+		}
+		sort.SliceStable(births, func(i, j int) bool {
+			return open[births[i]] > open[births[j]]
+		})
+		births = births[:m.Flow]
+	}
+
+	next = append(next, births...)
+	sortPoints(next)
+	if next == nil {
+		return []rules.Point{}
+	}
+	return next
+}
+
+// neighbours returns the 8 squares around a point, including squares off the board.
+func neighbours(p rules.Point) []rules.Point {
+	points := make([]rules.Point, 0, 8)
+	for dx := -1; dx <= 1; dx++ {
+		for dy := -1; dy <= 1; dy++ {
+			if dx != 0 || dy != 0 {
+				points = append(points, rules.Point{X: p.X + dx, Y: p.Y + dy})
+			}
+		}
+	}
+	return points
+}
+
+func liveNeighbours(p rules.Point, live map[rules.Point]bool) int {
+	n := 0
+	for _, q := range neighbours(p) {
+		if live[q] {
+			n++
+		}
+	}
+	return n
+}
+
+func openNeighbours(p rules.Point, live map[rules.Point]bool, width, height int) int {
+	n := 0
+	for _, q := range neighbours(p) {
+		if !outOfBounds(q, width, height) && !live[q] {
+			n++
+		}
+	}
+	return n
+}
+
+// nearSpawn reports whether a point is within spawnClearance squares of a starting head, in any direction.
+func nearSpawn(p rules.Point, spawns []rules.Point) bool {
+	for _, s := range spawns {
+		if abs(p.X-s.X) <= spawnClearance && abs(p.Y-s.Y) <= spawnClearance {
+			return true
+		}
+	}
+	return false
+}
+
+// sealsSpawns reports whether any starting head can reach less than half of the board without crossing hazards.
+func sealsSpawns(cells []rules.Point, width, height int, spawns []rules.Point) bool {
+	blocked := make(map[rules.Point]bool, len(cells))
+	for _, p := range cells {
+		blocked[p] = true
+	}
+	for _, s := range spawns {
+		reached := map[rules.Point]bool{s: true}
+		queue := []rules.Point{s}
+		for len(queue) > 0 {
+			p := queue[0]
+			queue = queue[1:]
+			for _, q := range []rules.Point{{X: p.X + 1, Y: p.Y}, {X: p.X - 1, Y: p.Y}, {X: p.X, Y: p.Y + 1}, {X: p.X, Y: p.Y - 1}} {
+				if !outOfBounds(q, width, height) && !blocked[q] && !reached[q] {
+					reached[q] = true
+					queue = append(queue, q)
+				}
+			}
+		}
+		if len(reached)*2 < width*height {
+			return true
+		}
+	}
+	return false
+}
+
+func sortPoints(points []rules.Point) {
+	sort.Slice(points, func(i, j int) bool {
+		if points[i].X != points[j].X {
+			return points[i].X < points[j].X
+		}
+		return points[i].Y < points[j].Y
+	})
+}
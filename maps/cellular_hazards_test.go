@@ -0,0 +1,153 @@
+package maps
+
+import (
+	"testing"
+
+	"github.com/Pikle2/rules"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAutomatonRule(t *testing.T) {
+	rule, err := ParseAutomatonRule("B3/S23")
+	require.NoError(t, err)
+	require.Equal(t, [9]bool{3: true}, rule.Birth)
+	require.Equal(t, [9]bool{2: true, 3: true}, rule.Survival)
+	require.Equal(t, "B3/S23", rule.String())
+
+	rule, err = ParseAutomatonRule("b1/s")
+	require.NoError(t, err)
+	require.Equal(t, "B1/S", rule.String())
+
+	_, err = ParseAutomatonRule("23/3")
+	require.EqualError(t, err, "invalid automaton rule '23/3', expected a rule like B3/S23")
+	_, err = ParseAutomatonRule("B39/S23")
+	require.EqualError(t, err, "invalid automaton rule 'B39/S23', neighbour counts must be 0 to 8")
+}
+
+func TestCellularHazardsEvolve(t *testing.T) {
+	life := CellularHazardsMap{}
+	rule, err := ParseAutomatonRule("B3/S23")
+	require.NoError(t, err)
+
+	// A blinker flips between a row and a column
+	row := []rules.Point{{X: 1, Y: 2}, {X: 2, Y: 2}, {X: 3, Y: 2}}
+	column := []rules.Point{{X: 2, Y: 1}, {X: 2, Y: 2}, {X: 2, Y: 3}}
+	require.Equal(t, column, life.evolve(rules.MinRand, rule, row, 5, 5, nil))
+	require.Equal(t, row, life.evolve(rules.MinRand, rule, column, 5, 5, nil))
+
+	// Cells off the board are always dead, and no cells are born near spawns
+	require.Equal(t, []rules.Point{}, life.evolve(rules.MinRand, rule, []rules.Point{{X: 0, Y: 0}}, 5, 5, nil))
+	require.Equal(t, []rules.Point{{X: 2, Y: 2}}, life.evolve(rules.MinRand, rule, column, 5, 5, []rules.Point{{X: 2, Y: 0}}))
+
+	// Lava only flows into the most open squares
+	lava := CellularHazardsMap{Flow: 2}
+	rule, err = ParseAutomatonRule("B1/S012345678")
+	require.NoError(t, err)
+	corner := []rules.Point{{X: 0, Y: 0}}
+	require.Equal(t, []rules.Point{{X: 0, Y: 0}, {X: 1, Y: 1}}, CellularHazardsMap{Flow: 1}.evolve(rules.MinRand, rule, corner, 5, 5, nil))
+	next := lava.evolve(rules.MinRand, rule, corner, 5, 5, nil)
+	require.Len(t, next, 3)
+	require.Contains(t, next, rules.Point{X: 1, Y: 1})
+}
+
+func TestCellularHazardsMap(t *testing.T) {
+	settings := rules.NewSettingsWithParams(rules.ParamMinimumFood, "1").WithSeed(7)
+	snakeIDs := []string{"1", "2", "3", "4"}
+
+	for _, id := range []string{"hz_life", "hz_coral", "hz_lava"} {
+		t.Run(id, func(t *testing.T) {
+			gameMap, err := GetMap(id)
+			require.NoError(t, err)
+			m := gameMap.(CellularHazardsMap)
+
+			boardState, err := SetupBoard(id, settings, 11, 11, snakeIDs)
+			require.NoError(t, err)
+			require.NotEmpty(t, boardState.Hazards)
+			if m.Vents > 0 {
+				require.Len(t, boardState.Hazards, m.Vents)
+			}
+
+			// Hazards start away from the snakes, and never seal a snake in
+			var spawns []rules.Point
+			for _, snake := range boardState.Snakes {
+				spawns = append(spawns, snake.Body[0])
+			}
+			for _, p := range boardState.Hazards {
+				require.False(t, nearSpawn(p, spawns), "hazard %v is too close to a snake", p)
+			}
+			require.False(t, sealsSpawns(boardState.Hazards, 11, 11, spawns))
+
+			// The same seed gives the same board
+			again, err := SetupBoard(id, settings, 11, 11, snakeIDs)
+			require.NoError(t, err)
+			require.Equal(t, boardState.Hazards, again.Hazards)
+
+			// Each generation replaces the hazards with the next cells, and keeps them in the map state
+			rule, err := ParseAutomatonRule(m.Rule)
+			require.NoError(t, err)
+			for turn := 1; turn <= 3; turn++ {
+				cells := append([]rules.Point{}, boardState.Hazards...)
+				sortPoints(cells)
+				boardState.Turn = turn
+				boardState, err = PostUpdateBoard(gameMap, boardState, settings)
+				require.NoError(t, err)
+
+				expected := cells
+				if turn%m.Every == 0 {
+					expected = m.evolve(settings.GetRand(turn), rule, cells, 11, 11, spawns)
+				}
+				hazards := append([]rules.Point{}, boardState.Hazards...)
+				sortPoints(hazards)
+				require.Equal(t, expected, hazards)
+				stored, err := NewMapState(boardState.GameState, id).Points(automatonCellsKey)
+				require.NoError(t, err)
+				require.Equal(t, expected, stored)
+				for _, food := range boardState.Food {
+					require.NotContains(t, hazards, food, "food was left under a hazard")
+				}
+			}
+		})
+	}
+}
+
+func TestCellularHazardsMapSettings(t *testing.T) {
+	gameMap := CellularHazardsMap{MapID: "test", Rule: "B3/S23", Density: 100, Every: 1}
+
+	// Seeding every square away from the snakes would seal them in, so nothing is seeded
+	boardState, err := SetupBoardWithMap(gameMap, rules.NewSettingsWithParams(ParamAutomatonRule, "B36/S23"), 11, 11, []string{"1", "2"})
+	require.NoError(t, err)
+	require.Empty(t, boardState.Hazards, "a full board would seal the snakes in")
+
+	boardState, err = SetupBoardWithMap(gameMap, rules.NewSettingsWithParams(ParamAutomatonDensity, "10"), 11, 11, []string{"1", "2"})
+	require.NoError(t, err)
+	// Snakes start in the corners, which leaves 89 squares to seed
+	require.Len(t, boardState.Hazards, 8)
+
+	_, err = SetupBoardWithMap(gameMap, rules.NewSettingsWithParams(ParamAutomatonRule, "life"), 11, 11, []string{"1"})
+	require.EqualError(t, err, "invalid automaton rule 'life', expected a rule like B3/S23")
+	_, err = SetupBoardWithMap(gameMap, rules.NewSettingsWithParams(ParamEvolveEveryNTurns, "0"), 11, 11, []string{"1"})
+	require.EqualError(t, err, "evolveEveryNTurns must be at least 1")
+	for _, density := range []string{"-1", "101"} {
+		_, err = SetupBoardWithMap(gameMap, rules.NewSettingsWithParams(ParamAutomatonDensity, density), 11, 11, []string{"1"})
+		require.EqualError(t, err, "automatonDensity must be between 0 and 100")
+	}
+}
+
+func TestCellularHazardsRemoveFoodUnderNewHazards(t *testing.T) {
+	gameMap := CellularHazardsMap{MapID: "test", Rule: "B3/S23", Every: 1}
+	settings := rules.Settings{}
+
+	// A blinker turns from a row into a column, so the food above and below the middle ends up under hazards
+	row := []rules.Point{{X: 4, Y: 5}, {X: 5, Y: 5}, {X: 6, Y: 5}}
+	boardState := rules.NewBoardState(11, 11).
+		WithTurn(1).
+		WithHazards(row).
+		WithFood([]rules.Point{{X: 5, Y: 4}, {X: 5, Y: 6}, {X: 0, Y: 0}}).
+		WithGameState(map[string]string{})
+	NewMapState(boardState.GameState, gameMap.ID()).SetPoints(automatonCellsKey, row)
+
+	boardState, err := PostUpdateBoard(gameMap, boardState, settings)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []rules.Point{{X: 5, Y: 4}, {X: 5, Y: 5}, {X: 5, Y: 6}}, boardState.Hazards)
+	require.Equal(t, []rules.Point{{X: 0, Y: 0}}, boardState.Food)
+}
@@ -0,0 +1,244 @@
+package maps
+
+import (
+	"fmt"
+
+	"github.com/Pikle2/rules"
+)
+
+// ParamScoreLimit is the score that wins a game on maps where snakes score points.
+const ParamScoreLimit = "scoreLimit"
+
+const (
+	defaultScoreLimit = 50
+
+	// controlZonesMapID is the ID of the control zones map, whose MapState holds the scores and zones.
+	controlZonesMapID = "control_zones"
+	// scoresKey is the MapState key for the score of each snake, keyed by snake ID.
+	scoresKey = "scores"
+	// zoneControllersKey is the MapState key for the snake controlling each zone, in order of zone ID.
+	zoneControllersKey = "zoneControllers"
+)
+
+func init() {
+	globalRegistry.RegisterMap(controlZonesMapID, ControlZonesMap{})
+}
+
+// ControlZone is an area of the board that scores a point each turn for the snake that controls it.
+// The squares of each zone are marked in the board's PointState with the zone ID.
+type ControlZone struct {
+	ID     int
+	Points []rules.Point
+	// Controller is the ID of the snake that scored the zone on the last turn, or empty if no snake did.
+	Controller string
+}
+
+// ControlZones returns the zones set up by the control zones map, in order of ID.
+// Boards from other maps return an empty list.
+func ControlZones(b *rules.BoardState) []ControlZone {
+	zones := []ControlZone{}
+	if b.GameState == nil {
+		return zones
+	}
+	var controllers []string
+	if _, err := NewMapState(b.GameState, controlZonesMapID).JSON(zoneControllersKey, &controllers); err != nil {
+		return zones
+	}
+	for i, controller := range controllers {
+		zones = append(zones, ControlZone{ID: i + 1, Points: []rules.Point{}, Controller: controller})
+	}
+
+	for p, id := range b.PointState {
+		if id >= 1 && id <= len(zones) {
+			zones[id-1].Points = append(zones[id-1].Points, p)
+		}
+	}
+	for _, zone := range zones {
+		sortPoints(zone.Points)
+	}
+	return zones
+}
+
+// Scores returns the score of each snake on boards from the control zones map, keyed by snake ID.
+// Boards from other maps have no scores.
+func Scores(b *rules.BoardState) map[string]int {
+	scores := map[string]int{}
+	if b.GameState == nil {
+		return scores
+	}
+	if _, err := NewMapState(b.GameState, controlZonesMapID).JSON(scoresKey, &scores); err != nil {
+		return map[string]int{}
+	}
+	return scores
+}
+
+// setScores stores the score of each snake, keyed by snake ID.
+func setScores(editor Editor, scores map[string]int) error {
+	return EditorMapState(editor, controlZonesMapID).SetJSON(scoresKey, scores)
+}
+
+// ControlZonesMap is a king of the hill map. Each turn a zone is controlled by the only snake occupying it,
+// with its head or with more than half of its body, and that snake scores a point. Zones occupied by more than one
+// snake are contested and don't score. The first snake to reach the score limit with the highest score wins,
+// and the other snakes are eliminated.
+type ControlZonesMap struct{}
+
+func (m ControlZonesMap) ID() string {
+	return controlZonesMapID
+}
+
+func (m ControlZonesMap) Meta() Metadata {
+	return Metadata{
+		Name:        "Control Zones",
+		Description: "Snakes score points for each turn they hold a zone on their own, and the first to the score limit wins",
+		Author:      "Battlesnake",
+		Version:     1,
+		MinPlayers:  2,
+		MaxPlayers:  8,
+		BoardSizes:  OddSizes(rules.BoardSizeSmall, rules.BoardSizeXXLarge),
+		Tags:        []string{TAG_EXPERIMENTAL},
+		Parameters: []Parameter{
+			foodSpawnerParameter,
+			{
+				Name:        ParamScoreLimit,
+				Type:        ParamTypeInt,
+				Default:     fmt.Sprint(defaultScoreLimit),
+				Description: "Score that wins the game",
+			},
+		},
+	}
+}
+
+// zones returns the squares of each zone: a 3x3 hill in the center of the board, and on large boards
+// four more hills halfway between the center and the corners.
+func (m ControlZonesMap) zones(width, height int) [][]rules.Point {
+	centers := []rules.Point{{X: (width - 1) / 2, Y: (height - 1) / 2}}
+	if width >= rules.BoardSizeLarge && height >= rules.BoardSizeLarge {
+		left, bottom, right, top := (width-1)/4, (height-1)/4, width-1-(width-1)/4, height-1-(height-1)/4
+		centers = append(centers,
+			rules.Point{X: left, Y: bottom},
+			rules.Point{X: left, Y: top},
+			rules.Point{X: right, Y: bottom},
+			rules.Point{X: right, Y: top},
+		)
+	}
+
+	zones := make([][]rules.Point, len(centers))
+	for i, center := range centers {
+		for x := center.X - 1; x <= center.X+1; x++ {
+			for y := center.Y - 1; y <= center.Y+1; y++ {
+				zones[i] = append(zones[i], rules.Point{X: x, Y: y})
+			}
+		}
+	}
+	return zones
+}
+
+func (m ControlZonesMap) SetupBoard(initialBoardState *rules.BoardState, settings rules.Settings, editor Editor) error {
+	if err := m.Meta().Validate(initialBoardState); err != nil {
+		return err
+	}
+	if settings.Int(ParamScoreLimit, defaultScoreLimit) < 1 {
+		return rules.RulesetError(fmt.Sprintf("%s must be at least 1", ParamScoreLimit))
+	}
+	if err := (StandardMap{}).SetupBoard(initialBoardState, settings, editor); err != nil {
+		return err
+	}
+
+	zones := m.zones(initialBoardState.Width, initialBoardState.Height)
+	for i, zone := range zones {
+		for _, p := range zone {
+			editor.PointState()[p] = i + 1
+		}
+	}
+	if err := EditorMapState(editor, m.ID()).SetJSON(zoneControllersKey, make([]string, len(zones))); err != nil {
+		return err
+	}
+	scores := make(map[string]int, len(initialBoardState.Snakes))
+	for _, snake := range initialBoardState.Snakes {
+		scores[snake.ID] = 0
+	}
+	return setScores(editor, scores)
+}
+
+func (m ControlZonesMap) PreUpdateBoard(lastBoardState *rules.BoardState, settings rules.Settings, editor Editor) error {
+	return nil
+}
+
+func (m ControlZonesMap) PostUpdateBoard(lastBoardState *rules.BoardState, settings rules.Settings, editor Editor) error {
+	if err := (StandardMap{}).PostUpdateBoard(lastBoardState, settings, editor); err != nil {
+		return err
+	}
+
+	scores := Scores(lastBoardState)
+	zones := ControlZones(lastBoardState)
+	controllers := make([]string, len(zones))
+	for i, zone := range zones {
+		controllers[i] = zoneController(zone, lastBoardState.Snakes)
+		if controllers[i] != "" {
+			scores[controllers[i]]++
+		}
+	}
+	if err := EditorMapState(editor, m.ID()).SetJSON(zoneControllersKey, controllers); err != nil {
+		return err
+	}
+	if err := setScores(editor, scores); err != nil {
+		return err
+	}
+
+	// The game is won once a single snake has the highest score and has reached the limit.
+	// Snakes tied for the lead keep playing until one of them pulls ahead.
+	leader, leaderScore, tied := "", 0, false
+	for _, snake := range lastBoardState.Snakes {
+		if snake.EliminatedCause != rules.NotEliminated {
+			continue
+		}
+		score := scores[snake.ID]
+		if leader == "" || score > leaderScore {
+			leader, leaderScore, tied = snake.ID, score, false
+		} else if score == leaderScore {
+			tied = true
+		}
+	}
+	if leader == "" || tied || leaderScore < settings.Int(ParamScoreLimit, defaultScoreLimit) {
+		return nil
+	}
+	for _, snake := range lastBoardState.Snakes {
+		if snake.ID != leader && snake.EliminatedCause == rules.NotEliminated {
+			if err := EditorEliminateSnake(editor, snake.ID, rules.EliminatedByScoreLimit, leader); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// zoneController returns the only snake occupying a zone with its head or more than half of its body,
+// or an empty string if the zone is empty or contested.
+func zoneController(zone ControlZone, snakes []rules.Snake) string {
+	inZone := make(map[rules.Point]bool, len(zone.Points))
+	for _, p := range zone.Points {
+		inZone[p] = true
+	}
+
+	controller := ""
+	for _, snake := range snakes {
+		if snake.EliminatedCause != rules.NotEliminated || len(snake.Body) == 0 {
+			continue
+		}
+		segments := 0
+		for _, p := range snake.Body {
+			if inZone[p] {
+				segments++
+			}
+		}
+		if !inZone[snake.Body[0]] && segments*2 <= len(snake.Body) {
+			continue
+		}
+		if controller != "" {
+			return ""
+		}
+		controller = snake.ID
+	}
+	return controller
+}
@@ -0,0 +1,122 @@
+package maps_test
+
+import (
+	"testing"
+
+	"github.com/Pikle2/rules"
+	"github.com/Pikle2/rules/maps"
+	"github.com/stretchr/testify/require"
+)
+
+func TestControlZonesSetup(t *testing.T) {
+	boardState, err := maps.SetupBoard("control_zones", rules.Settings{}, 11, 11, []string{"1", "2"})
+	require.NoError(t, err)
+
+	zones := maps.ControlZones(boardState)
+	require.Len(t, zones, 1)
+	require.Equal(t, 1, zones[0].ID)
+	require.Equal(t, "", zones[0].Controller)
+	require.Equal(t, []rules.Point{
+		{X: 4, Y: 4}, {X: 4, Y: 5}, {X: 4, Y: 6},
+		{X: 5, Y: 4}, {X: 5, Y: 5}, {X: 5, Y: 6},
+		{X: 6, Y: 4}, {X: 6, Y: 5}, {X: 6, Y: 6},
+	}, zones[0].Points)
+	require.Equal(t, map[string]int{"1": 0, "2": 0}, maps.Scores(boardState))
+
+	// Large boards have a hill between the center and each corner
+	boardState, err = maps.SetupBoard("control_zones", rules.Settings{}, 19, 19, []string{"1", "2"})
+	require.NoError(t, err)
+	zones = maps.ControlZones(boardState)
+	require.Len(t, zones, 5)
+	require.Contains(t, zones[1].Points, rules.Point{X: 4, Y: 4})
+	require.Contains(t, zones[4].Points, rules.Point{X: 14, Y: 14})
+
+	// Boards without zones have no zones or scores
+	require.Empty(t, maps.ControlZones(rules.NewBoardState(11, 11)))
+	require.Empty(t, maps.Scores(rules.NewBoardState(11, 11)))
+
+	_, err = maps.SetupBoard("control_zones", rules.NewSettingsWithParams(maps.ParamScoreLimit, "0"), 11, 11, []string{"1", "2"})
+	require.EqualError(t, err, "scoreLimit must be at least 1")
+
+	// A solo snake could never be beaten to the score limit
+	_, err = maps.SetupBoard("control_zones", rules.Settings{}, 11, 11, []string{"1"})
+	require.EqualError(t, err, "This map can only be played with 2-8 players")
+}
+
+func TestControlZonesIgnoreOtherMapsGameState(t *testing.T) {
+	// GameState keys from other maps aren't read as scores or zones
+	boardState := rules.NewBoardState(11, 11).
+		WithPointState(map[rules.Point]int{{X: 5, Y: 5}: 1}).
+		WithGameState(map[string]string{"score.1": "4", "zone.1.controller": "1"})
+	require.Empty(t, maps.Scores(boardState))
+	require.Empty(t, maps.ControlZones(boardState))
+}
+
+func TestControlZonesScoring(t *testing.T) {
+	settings := rules.NewSettingsWithParams(maps.ParamScoreLimit, "3")
+	gameMap, err := maps.GetMap("control_zones")
+	require.NoError(t, err)
+	boardState, err := maps.SetupBoardWithMap(gameMap, settings, 11, 11, []string{"1", "2", "3"})
+	require.NoError(t, err)
+
+	// The head of snake 1 is in the zone, and most of snake 2 is outside it
+	boardState.Snakes[0].Body = []rules.Point{{X: 4, Y: 4}, {X: 3, Y: 4}, {X: 2, Y: 4}}
+	boardState.Snakes[1].Body = []rules.Point{{X: 7, Y: 7}, {X: 6, Y: 7}, {X: 6, Y: 6}}
+	boardState.Snakes[2].Body = []rules.Point{{X: 0, Y: 0}, {X: 0, Y: 1}, {X: 0, Y: 2}}
+	boardState, err = maps.PostUpdateBoard(gameMap, boardState, settings)
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{"1": 1, "2": 0, "3": 0}, maps.Scores(boardState))
+	require.Equal(t, "1", maps.ControlZones(boardState)[0].Controller)
+
+	// Snake 2 moves most of its body into the zone, which contests it
+	boardState.Snakes[1].Body = []rules.Point{{X: 7, Y: 6}, {X: 6, Y: 6}, {X: 6, Y: 5}}
+	boardState, err = maps.PostUpdateBoard(gameMap, boardState, settings)
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{"1": 1, "2": 0, "3": 0}, maps.Scores(boardState))
+	require.Equal(t, "", maps.ControlZones(boardState)[0].Controller)
+
+	// Snake 1 leaves, so snake 2 scores until it reaches the limit
+	boardState.Snakes[0].Body = []rules.Point{{X: 2, Y: 4}, {X: 1, Y: 4}, {X: 0, Y: 4}}
+	for turn := 0; turn < 2; turn++ {
+		boardState, err = maps.PostUpdateBoard(gameMap, boardState, settings)
+		require.NoError(t, err)
+	}
+	require.Equal(t, map[string]int{"1": 1, "2": 2, "3": 0}, maps.Scores(boardState))
+	for _, snake := range boardState.Snakes {
+		require.Equal(t, rules.NotEliminated, snake.EliminatedCause)
+	}
+
+	boardState.Turn = 9
+	boardState, err = maps.PostUpdateBoard(gameMap, boardState, settings)
+	require.NoError(t, err)
+	require.Equal(t, 3, maps.Scores(boardState)["2"])
+	require.Equal(t, rules.NotEliminated, boardState.Snakes[1].EliminatedCause)
+	for _, i := range []int{0, 2} {
+		require.Equal(t, rules.EliminatedByScoreLimit, boardState.Snakes[i].EliminatedCause)
+		require.Equal(t, "2", boardState.Snakes[i].EliminatedBy)
+		require.Equal(t, 10, boardState.Snakes[i].EliminatedOnTurn)
+	}
+}
+
+func TestControlZonesTiedLeaders(t *testing.T) {
+	settings := rules.NewSettingsWithParams(maps.ParamScoreLimit, "2")
+	gameMap, err := maps.GetMap("control_zones")
+	require.NoError(t, err)
+	boardState, err := maps.SetupBoardWithMap(gameMap, settings, 11, 11, []string{"1", "2"})
+	require.NoError(t, err)
+	require.NoError(t, maps.NewMapState(boardState.GameState, "control_zones").SetJSON("scores", map[string]int{"1": 2, "2": 2}))
+
+	// Both snakes are past the limit, but neither is ahead
+	boardState, err = maps.PostUpdateBoard(gameMap, boardState, settings)
+	require.NoError(t, err)
+	for _, snake := range boardState.Snakes {
+		require.Equal(t, rules.NotEliminated, snake.EliminatedCause)
+	}
+
+	// Eliminated snakes don't count as leaders
+	boardState.Snakes[1].EliminatedCause = rules.EliminatedByCollision
+	boardState, err = maps.PostUpdateBoard(gameMap, boardState, settings)
+	require.NoError(t, err)
+	require.Equal(t, rules.NotEliminated, boardState.Snakes[0].EliminatedCause)
+	require.Equal(t, rules.EliminatedByCollision, boardState.Snakes[1].EliminatedCause)
+}
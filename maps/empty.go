@@ -24,6 +24,7 @@ func (m EmptyMap) Meta() Metadata {
 		MaxPlayers:  16,
 		BoardSizes:  OddSizes(rules.BoardSizeSmall, rules.BoardSizeXXLarge),
 		Tags:        []string{},
+		Parameters:  []Parameter{snakePlacementParameter, startBodyParameter},
 	}
 }
 
@@ -40,7 +41,7 @@ func (m EmptyMap) SetupBoard(initialBoardState *rules.BoardState, settings rules
 	}
 
 	tempBoardState := rules.NewBoardState(initialBoardState.Width, initialBoardState.Height)
-	err := rules.PlaceSnakesAutomatically(rand, tempBoardState, snakeIDs)
+	err := PlaceSnakesFromSettings(rand, settings, tempBoardState, snakeIDs)
 	if err != nil {
 		return err
 	}
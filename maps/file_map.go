@@ -0,0 +1,522 @@
+package maps
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Pikle2/rules"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// FoodPlacementStandard spawns food like the standard map, at the layout's food spawn points if it has any.
+	FoodPlacementStandard = "standard"
+	// FoodPlacementNone never spawns food after the initial layout.
+	FoodPlacementNone = "none"
+
+	// InitialFoodLayout only places the layout's food when the game starts.
+	InitialFoodLayout = "layout"
+	// InitialFoodFixed also places food near each snake and in the centre, like the standard map.
+	InitialFoodFixed = "fixed"
+)
+
+// MapDefinition describes a map in a JSON or YAML file, so that maps can be designed without writing Go.
+// Use NewFileMap or LoadMapFile to turn a definition into a GameMap.
+//
+// A minimal YAML map looks like:
+//
+//	id: my_map
+//	meta:
+//	  name: My Map
+//	  author: me
+//	layouts:
+//	  - size: 11x11
+//	    snakeStarts: [[1, 1], [9, 9], [1, 9], [9, 1]]
+//	    walls: [[5, 4], [5, 5], [5, 6]]
+//	    food: [[5, 8], [5, 2]]
+//	    schedule:
+//	      - turn: 50
+//	        every: 50
+//	        hazards: [[0, 0], [10, 10]]
+type MapDefinition struct {
+	ID      string       `json:"id" yaml:"id"`
+	Meta    MapFileMeta  `json:"meta" yaml:"meta"`
+	Food    MapFoodRules `json:"food" yaml:"food"`
+	Layouts []MapLayout  `json:"layouts" yaml:"layouts"`
+}
+
+// MapFileMeta is the metadata of a map file. The supported board sizes are taken from the layouts.
+type MapFileMeta struct {
+	Name        string   `json:"name" yaml:"name"`
+	Author      string   `json:"author" yaml:"author"`
+	Description string   `json:"description" yaml:"description"`
+	Version     int      `json:"version" yaml:"version"`
+	MinPlayers  int      `json:"minPlayers" yaml:"minPlayers"`
+	MaxPlayers  int      `json:"maxPlayers" yaml:"maxPlayers"`
+	Tags        []string `json:"tags" yaml:"tags"`
+	// Parameters declares the game settings that the map reads.
+	Parameters []Parameter `json:"parameters" yaml:"parameters"`
+}
+
+// MapFoodRules controls how food is spawned after the initial layout.
+type MapFoodRules struct {
+	// Placement is FoodPlacementStandard (the default) or FoodPlacementNone.
+	Placement string `json:"placement" yaml:"placement"`
+	// MinimumFood and SpawnChance override the game settings when set.
+	MinimumFood *int `json:"minimumFood" yaml:"minimumFood"`
+	SpawnChance *int `json:"spawnChance" yaml:"spawnChance"`
+	// Initial is InitialFoodLayout (the default) or InitialFoodFixed.
+	Initial string `json:"initial" yaml:"initial"`
+	// OnHazards allows food to spawn on hazards. Food never spawns on walls.
+	OnHazards bool `json:"onHazards" yaml:"onHazards"`
+}
+
+// MapLayout holds the positions for one board size.
+type MapLayout struct {
+	// Size is the board size this layout is for, e.g. "11x11".
+	Size string `json:"size" yaml:"size"`
+	// SnakeStarts are the possible head positions for snakes, which are assigned randomly.
+	// If empty, snakes are placed like the standard map.
+	SnakeStarts []MapPoint `json:"snakeStarts" yaml:"snakeStarts"`
+	// StartQuadrants are four groups of head positions with the same number of positions in each.
+	// Snakes are spread evenly across the groups, starting from a random one. Can't be used with SnakeStarts.
+	StartQuadrants [][]MapPoint `json:"startQuadrants" yaml:"startQuadrants"`
+	// Food is placed on the board when the game starts.
+	Food []MapPoint `json:"food" yaml:"food"`
+	// Hazards are placed on the board when the game starts. Points can be repeated to stack hazards.
+	Hazards []MapPoint `json:"hazards" yaml:"hazards"`
+	// Walls are hazards stacked high enough to eliminate any snake that moves onto them.
+	// Food is never spawned on walls.
+	Walls []MapPoint `json:"walls" yaml:"walls"`
+	// FoodSpawns limits where new food can spawn. If empty, food can spawn on any free square.
+	FoodSpawns []MapPoint `json:"foodSpawns" yaml:"foodSpawns"`
+	// Schedule lists hazard changes that happen during the game, in the order they are applied.
+	Schedule []HazardChange `json:"schedule" yaml:"schedule"`
+}
+
+// HazardChange changes the hazards on the board on a given turn, and optionally repeats.
+type HazardChange struct {
+	// Turn is the first turn the change is applied to.
+	Turn int `json:"turn" yaml:"turn"`
+	// Every repeats the change every N turns after Turn, if set.
+	Every int `json:"every" yaml:"every"`
+	// Until is the last turn the change can be applied to, if set.
+	Until int `json:"until" yaml:"until"`
+	// Clear removes all hazards except walls before adding new ones.
+	Clear bool `json:"clear" yaml:"clear"`
+	// Remove removes all hazards on these squares, including walls.
+	Remove []MapPoint `json:"remove" yaml:"remove"`
+	// Hazards are added to the board. Points can be repeated to stack hazards.
+	Hazards []MapPoint `json:"hazards" yaml:"hazards"`
+}
+
+// MapPoint is an [x, y] position in a map file.
+type MapPoint [2]int
+
+func (p MapPoint) Point() rules.Point {
+	return rules.Point{X: p[0], Y: p[1]}
+}
+
+// FileMap is a GameMap built from a MapDefinition.
+type FileMap struct {
+	definition MapDefinition
+	layouts    map[Dimensions]MapLayout
+	sizes      sizes
+}
+
+// NewFileMap checks a map definition and builds a GameMap from it.
+func NewFileMap(definition MapDefinition) (*FileMap, error) {
+	if definition.ID == "" {
+		return nil, rules.RulesetError("map file has no id")
+	}
+	switch definition.Food.Placement {
+	case "", FoodPlacementStandard, FoodPlacementNone:
+	default:
+		return nil, fmt.Errorf("map %s: unknown food placement %q", definition.ID, definition.Food.Placement)
+	}
+	switch definition.Food.Initial {
+	case "", InitialFoodLayout, InitialFoodFixed:
+	default:
+		return nil, fmt.Errorf("map %s: unknown initial food %q", definition.ID, definition.Food.Initial)
+	}
+	if len(definition.Layouts) == 0 {
+		return nil, fmt.Errorf("map %s: at least one layout is required", definition.ID)
+	}
+	if err := checkParameters(definition.Meta.Parameters); err != nil {
+		return nil, fmt.Errorf("map %s: %w", definition.ID, err)
+	}
+
+	m := &FileMap{
+		definition: definition,
+		layouts:    make(map[Dimensions]MapLayout, len(definition.Layouts)),
+	}
+	for i, layout := range definition.Layouts {
+		size, err := ParseDimensions(layout.Size)
+		if err != nil {
+			return nil, fmt.Errorf("map %s: layouts[%d]: %w", definition.ID, i, err)
+		}
+		if _, ok := m.layouts[size]; ok {
+			return nil, fmt.Errorf("map %s: layouts[%d]: there is already a layout for %s", definition.ID, i, layout.Size)
+		}
+		if err := layout.checkPoints(size); err != nil {
+			return nil, fmt.Errorf("map %s: layouts[%d]: %w", definition.ID, i, err)
+		}
+		if err := layout.checkQuadrants(); err != nil {
+			return nil, fmt.Errorf("map %s: layouts[%d]: %w", definition.ID, i, err)
+		}
+		for j, change := range layout.Schedule {
+			if change.Turn < 1 || change.Every < 0 || (change.Until != 0 && change.Until < change.Turn) {
+				return nil, fmt.Errorf("map %s: layouts[%d].schedule[%d]: turn must be at least 1, every can't be negative, and until can't be before turn", definition.ID, i, j)
+			}
+		}
+		m.layouts[size] = layout
+		m.sizes = append(m.sizes, size)
+	}
+
+	return m, nil
+}
+
+// ParseMapFile parses a map definition in JSON, YAML or the ASCII map format ("txt").
+// Unknown fields are rejected to catch typos.
+func ParseMapFile(data []byte, format string) (*FileMap, error) {
+	var definition MapDefinition
+	switch format {
+	case "json":
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&definition); err != nil {
+			return nil, fmt.Errorf("invalid JSON map file: %w", err)
+		}
+	case "yaml", "yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&definition); err != nil {
+			return nil, fmt.Errorf("invalid YAML map file: %w", err)
+		}
+	case "txt":
+		return ParseASCIIMap(string(data))
+	default:
+		return nil, fmt.Errorf("unknown map file format %q, expected json, yaml or txt", format)
+	}
+	return NewFileMap(definition)
+}
+
+// LoadMapFile reads a map definition from a .json, .yaml, .yml or .txt (ASCII map) file.
+func LoadMapFile(path string) (*FileMap, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	m, err := ParseMapFile(data, format)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return m, nil
+}
+
+// RegisterMapFile loads a map file and adds it to the registry, returning an error if its ID is already taken.
+func (registry MapRegistry) RegisterMapFile(path string) (GameMap, error) {
+	m, err := LoadMapFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := registry.RegisterMapError(m.ID(), m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// RegisterMapFile loads a map file and adds it to the global registry.
+func RegisterMapFile(path string) (GameMap, error) {
+	return globalRegistry.RegisterMapFile(path)
+}
+
+func (m *FileMap) ID() string {
+	return m.definition.ID
+}
+
+func (m *FileMap) Meta() Metadata {
+	meta := m.definition.Meta
+	tags := append([]string{}, meta.Tags...)
+	params := append([]Parameter{}, meta.Parameters...)
+	if _, ok := (Metadata{Parameters: params}).Parameter(rules.ParamHazardDamagePerTurn); !ok && m.hasWalls() {
+		params = append(params, Parameter{
+			Name:        rules.ParamHazardDamagePerTurn,
+			Type:        ParamTypeInt,
+			Description: "Health lost for each hazard square, walls are stacked high enough to eliminate a snake at full health",
+		})
+	}
+	if m.hasAutomaticStarts() {
+		for _, p := range []Parameter{snakePlacementParameter, startBodyParameter} {
+			if _, ok := (Metadata{Parameters: params}).Parameter(p.Name); !ok {
+				params = append(params, p)
+			}
+		}
+	}
+	return Metadata{
+		Name:        meta.Name,
+		Author:      meta.Author,
+		Description: meta.Description,
+		Version:     meta.Version,
+		MinPlayers:  meta.MinPlayers,
+		MaxPlayers:  meta.MaxPlayers,
+		BoardSizes:  m.sizes,
+		Tags:        tags,
+		Parameters:  params,
+	}
+}
+
+// hasWalls reports whether any layout has walls, which need a hazard damage setting.
+func (m *FileMap) hasWalls() bool {
+	for _, layout := range m.layouts {
+		if len(layout.Walls) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// hasAutomaticStarts reports whether any layout leaves snake placement to PlaceSnakesFromSettings.
+func (m *FileMap) hasAutomaticStarts() bool {
+	for _, layout := range m.layouts {
+		if len(layout.StartQuadrants) == 0 && len(layout.SnakeStarts) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Definition returns a copy of the definition the map was built from.
+func (m *FileMap) Definition() MapDefinition {
+	return m.definition
+}
+
+func (m *FileMap) SetupBoard(initialBoardState *rules.BoardState, settings rules.Settings, editor Editor) error {
+	if err := m.Meta().Validate(initialBoardState); err != nil {
+		return err
+	}
+	layout := m.layouts[Dimensions{initialBoardState.Width, initialBoardState.Height}]
+
+	rand := settings.GetRand(0)
+
+	if len(layout.StartQuadrants) > 0 {
+		quadrants := make([][]rules.Point, 0, len(layout.StartQuadrants))
+		for _, quadrant := range layout.StartQuadrants {
+			quadrants = append(quadrants, mapPoints(quadrant))
+		}
+		if err := PlaceSnakesInQuadrants(rand, editor, initialBoardState.Snakes, quadrants); err != nil {
+			return err
+		}
+	} else if len(layout.SnakeStarts) > 0 {
+		starts := mapPoints(layout.SnakeStarts)
+		if err := editor.PlaceSnakesRandomlyAtPositions(rand, initialBoardState.Snakes, starts, rules.SnakeStartSize); err != nil {
+			return err
+		}
+	} else {
+		tempBoardState := rules.NewBoardState(initialBoardState.Width, initialBoardState.Height)
+		snakeIDs := make([]string, 0, len(initialBoardState.Snakes))
+		for _, snake := range initialBoardState.Snakes {
+			snakeIDs = append(snakeIDs, snake.ID)
+		}
+		if err := PlaceSnakesFromSettings(rand, settings, tempBoardState, snakeIDs); err != nil {
+			return err
+		}
+		for _, snake := range tempBoardState.Snakes {
+			editor.PlaceSnake(snake.ID, snake.Body, snake.Health)
+		}
+	}
+
+	for _, p := range layout.Food {
+		editor.AddFood(p.Point())
+	}
+	for _, p := range layout.Hazards {
+		editor.AddHazard(p.Point())
+	}
+
+	if err := addWalls(layout, settings, editor); err != nil {
+		return err
+	}
+
+	if m.definition.Food.Initial == InitialFoodFixed {
+		return PlaceFoodFixed(rand, initialBoardState, editor)
+	}
+	return nil
+}
+
+func (m *FileMap) PreUpdateBoard(lastBoardState *rules.BoardState, settings rules.Settings, editor Editor) error {
+	return nil
+}
+
+func (m *FileMap) PostUpdateBoard(lastBoardState *rules.BoardState, settings rules.Settings, editor Editor) error {
+	layout, ok := m.layouts[Dimensions{lastBoardState.Width, lastBoardState.Height}]
+	if !ok {
+		return m.Meta().Validate(lastBoardState)
+	}
+
+	turn := lastBoardState.Turn + 1
+	for _, change := range layout.Schedule {
+		if !change.appliesTo(turn) {
+			continue
+		}
+		if change.Clear {
+			editor.ClearHazards()
+			if err := addWalls(layout, settings, editor); err != nil {
+				return err
+			}
+		}
+		for _, p := range change.Remove {
+			editor.RemoveHazard(p.Point())
+		}
+		for _, p := range change.Hazards {
+			editor.AddHazard(p.Point())
+		}
+	}
+
+	if m.definition.Food.Placement == FoodPlacementNone {
+		return nil
+	}
+
+	rand := settings.GetRand(lastBoardState.Turn)
+	foodSettings := settings
+	if m.definition.Food.MinimumFood != nil {
+		foodSettings = foodSettings.WithParam(rules.ParamMinimumFood, fmt.Sprint(*m.definition.Food.MinimumFood))
+	}
+	if m.definition.Food.SpawnChance != nil {
+		foodSettings = foodSettings.WithParam(rules.ParamFoodSpawnChance, fmt.Sprint(*m.definition.Food.SpawnChance))
+	}
+	foodNeeded := FoodNeeded(rand, foodSettings, lastBoardState)
+	if foodNeeded == 0 {
+		return nil
+	}
+
+	walls := make(map[rules.Point]bool, len(layout.Walls))
+	for _, p := range layout.Walls {
+		walls[p.Point()] = true
+	}
+	var positions []rules.Point
+	if len(layout.FoodSpawns) > 0 {
+		positions = editor.FilterUnoccupiedPoints(mapPoints(layout.FoodSpawns), true, !m.definition.Food.OnHazards, true)
+	} else {
+		positions = rules.GetUnoccupiedPoints(lastBoardState, false, !m.definition.Food.OnHazards)
+	}
+	freePositions := positions[:0]
+	for _, p := range positions {
+		if !walls[p] {
+			freePositions = append(freePositions, p)
+		}
+	}
+	placeFoodRandomlyAtPositions(rand, lastBoardState, editor, foodNeeded, freePositions)
+
+	return nil
+}
+
+func (change HazardChange) appliesTo(turn int) bool {
+	if turn < change.Turn || (change.Until != 0 && turn > change.Until) {
+		return false
+	}
+	if change.Every == 0 {
+		return turn == change.Turn
+	}
+	return (turn-change.Turn)%change.Every == 0
+}
+
+func (layout MapLayout) checkPoints(size Dimensions) error {
+	lists := map[string][]MapPoint{
+		"snakeStarts": layout.SnakeStarts,
+		"food":        layout.Food,
+		"hazards":     layout.Hazards,
+		"walls":       layout.Walls,
+		"foodSpawns":  layout.FoodSpawns,
+	}
+	for i, quadrant := range layout.StartQuadrants {
+		lists[fmt.Sprintf("startQuadrants[%d]", i)] = quadrant
+	}
+	for i, change := range layout.Schedule {
+		lists[fmt.Sprintf("schedule[%d].remove", i)] = change.Remove
+		lists[fmt.Sprintf("schedule[%d].hazards", i)] = change.Hazards
+	}
+
+	names := make([]string, 0, len(lists))
+	for name := range lists {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, p := range lists[name] {
+			if !isOnBoard(size.Width, size.Height, p[0], p[1]) {
+				return fmt.Errorf("%s: [%d, %d] is not on a %s board", name, p[0], p[1], layout.Size)
+			}
+		}
+	}
+	return nil
+}
+
+func (layout MapLayout) checkQuadrants() error {
+	if len(layout.StartQuadrants) == 0 {
+		return nil
+	}
+	if len(layout.SnakeStarts) > 0 {
+		return rules.RulesetError("snakeStarts and startQuadrants can't be used together")
+	}
+	if len(layout.StartQuadrants) != 4 {
+		return fmt.Errorf("startQuadrants must have 4 quadrants, not %d", len(layout.StartQuadrants))
+	}
+	for _, quadrant := range layout.StartQuadrants[1:] {
+		if len(quadrant) != len(layout.StartQuadrants[0]) {
+			return rules.RulesetError("startQuadrants must all have the same number of positions")
+		}
+	}
+	return nil
+}
+
+func mapPoints(points []MapPoint) []rules.Point {
+	result := make([]rules.Point, 0, len(points))
+	for _, p := range points {
+		result = append(result, p.Point())
+	}
+	return result
+}
+
+// addWalls adds the layout's walls to the board.
+func addWalls(layout MapLayout, settings rules.Settings, editor Editor) error {
+	if len(layout.Walls) == 0 {
+		return nil
+	}
+	stack, err := wallStackSize(settings)
+	if err != nil {
+		return err
+	}
+	for _, p := range layout.Walls {
+		for i := 0; i < stack; i++ {
+			editor.AddHazard(p.Point())
+		}
+	}
+	return nil
+}
+
+// wallStackSize returns how many hazards need to be stacked for a wall to eliminate a snake at full health.
+func wallStackSize(settings rules.Settings) (int, error) {
+	damage := settings.Int(rules.ParamHazardDamagePerTurn, 0)
+	if damage <= 0 {
+		return 0, rules.RulesetError("map walls need a positive hazard damage per turn")
+	}
+	return (rules.SnakeMaxHealth + damage - 1) / damage, nil
+}
+
+// ParseDimensions parses a board size like "11x11".
+func ParseDimensions(s string) (Dimensions, error) {
+	var d Dimensions
+	if n, err := fmt.Sscanf(s, "%dx%d", &d.Width, &d.Height); err != nil || n != 2 || fmt.Sprintf("%dx%d", d.Width, d.Height) != s {
+		return d, fmt.Errorf("invalid board size %q, expected WIDTHxHEIGHT", s)
+	}
+	if d.Width <= 0 || d.Height <= 0 {
+		return d, fmt.Errorf("invalid board size %q, width and height must be positive", s)
+	}
+	return d, nil
+}
@@ -0,0 +1,220 @@
+package maps
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Pikle2/rules"
+	"github.com/stretchr/testify/require"
+)
+
+func countHazards(boardState *rules.BoardState, p rules.Point) int {
+	n := 0
+	for _, h := range boardState.Hazards {
+		if h == p {
+			n++
+		}
+	}
+	return n
+}
+
+func TestLoadMapFile(t *testing.T) {
+	crossroads, err := LoadMapFile("testdata/crossroads.yaml")
+	require.NoError(t, err)
+	require.Equal(t, "crossroads", crossroads.ID())
+	meta := crossroads.Meta()
+	require.Equal(t, "Crossroads", meta.Name)
+	require.Equal(t, 4, meta.MaxPlayers)
+	require.Equal(t, FixedSizes(Dimensions{11, 11}), meta.BoardSizes)
+	require.Equal(t, 2, *crossroads.Definition().Food.MinimumFood)
+
+	pillars, err := LoadMapFile("testdata/pillars.json")
+	require.NoError(t, err)
+	require.Equal(t, "pillars", pillars.ID())
+	require.Equal(t, FixedSizes(Dimensions{7, 7}, Dimensions{11, 11}), pillars.Meta().BoardSizes)
+	require.Equal(t, FoodPlacementNone, pillars.Definition().Food.Placement)
+
+	_, err = LoadMapFile("testdata/missing.yaml")
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestParseMapFileErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		data   string
+		err    string
+	}{
+		{"format", "toml", `id = "x"`, `unknown map file format "toml", expected json, yaml or txt`},
+		{"unknown field", "yaml", "id: x\nlayuots: []", "invalid YAML map file: yaml: unmarshal errors:\n  line 2: field layuots not found in type maps.MapDefinition"},
+		{"unknown JSON field", "json", `{"id": "x", "walls": []}`, `invalid JSON map file: json: unknown field "walls"`},
+		{"no id", "yaml", "layouts: [{size: 7x7}]", "map file has no id"},
+		{"no layouts", "yaml", "id: x", "map x: at least one layout is required"},
+		{"food placement", "yaml", "id: x\nfood: {placement: everywhere}\nlayouts: [{size: 7x7}]", `map x: unknown food placement "everywhere"`},
+		{"size", "yaml", "id: x\nlayouts: [{size: 7by7}]", `map x: layouts[0]: invalid board size "7by7", expected WIDTHxHEIGHT`},
+		{"zero size", "yaml", "id: x\nlayouts: [{size: 0x7}]", `map x: layouts[0]: invalid board size "0x7", width and height must be positive`},
+		{"duplicate size", "yaml", "id: x\nlayouts: [{size: 7x7}, {size: 7x7}]", "map x: layouts[1]: there is already a layout for 7x7"},
+		{"off board", "yaml", "id: x\nlayouts: [{size: 7x7, walls: [[1, 1], [7, 1]]}]", "map x: layouts[0]: walls: [7, 1] is not on a 7x7 board"},
+		{"off board schedule", "yaml", "id: x\nlayouts: [{size: 7x7, schedule: [{turn: 1, hazards: [[-1, 0]]}]}]", "map x: layouts[0]: schedule[0].hazards: [-1, 0] is not on a 7x7 board"},
+		{"schedule turn", "yaml", "id: x\nlayouts: [{size: 7x7, schedule: [{turn: 0}]}]", "map x: layouts[0].schedule[0]: turn must be at least 1, every can't be negative, and until can't be before turn"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParseMapFile([]byte(test.data), test.format)
+			require.EqualError(t, err, test.err)
+		})
+	}
+}
+
+func TestFileMapSetupBoard(t *testing.T) {
+	m, err := LoadMapFile("testdata/crossroads.yaml")
+	require.NoError(t, err)
+
+	initialBoardState := rules.NewBoardState(11, 11)
+	initialBoardState.Snakes = []rules.Snake{{ID: "1"}, {ID: "2"}, {ID: "3"}}
+	settings := rules.NewSettingsWithParams(rules.ParamHazardDamagePerTurn, "14").WithSeed(1)
+	editor := NewBoardStateEditor(initialBoardState.Clone())
+	require.NoError(t, m.SetupBoard(initialBoardState, settings, editor))
+	boardState := editor.boardState
+
+	starts := map[rules.Point]bool{{X: 1, Y: 1}: true, {X: 9, Y: 9}: true, {X: 1, Y: 9}: true, {X: 9, Y: 1}: true}
+	for _, snake := range boardState.Snakes {
+		require.Len(t, snake.Body, rules.SnakeStartSize)
+		require.True(t, starts[snake.Body[0]], "snake %s should start at a snake start", snake.ID)
+		delete(starts, snake.Body[0])
+	}
+	require.ElementsMatch(t, []rules.Point{{X: 3, Y: 3}, {X: 7, Y: 7}, {X: 3, Y: 7}, {X: 7, Y: 3}}, boardState.Food)
+
+	// 8 stacked hazards of 14 damage are enough to eliminate a snake at full health
+	require.Len(t, boardState.Hazards, 16*8)
+	require.Equal(t, 8, countHazards(boardState, rules.Point{X: 5, Y: 0}))
+	require.Equal(t, 0, countHazards(boardState, rules.Point{X: 5, Y: 2}))
+
+	// Too many snakes for the map
+	initialBoardState.Snakes = []rules.Snake{{ID: "1"}, {ID: "2"}, {ID: "3"}, {ID: "4"}, {ID: "5"}}
+	err = m.SetupBoard(initialBoardState, settings, NewBoardStateEditor(initialBoardState.Clone()))
+	require.Error(t, err)
+
+	// Walls need hazard damage
+	initialBoardState.Snakes = []rules.Snake{{ID: "1"}}
+	err = m.SetupBoard(initialBoardState, rules.Settings{}, NewBoardStateEditor(initialBoardState.Clone()))
+	require.EqualError(t, err, "map walls need a positive hazard damage per turn")
+}
+
+func TestFileMapSetupBoardAutomaticPlacement(t *testing.T) {
+	m, err := LoadMapFile("testdata/pillars.json")
+	require.NoError(t, err)
+
+	initialBoardState := rules.NewBoardState(7, 7)
+	initialBoardState.Snakes = []rules.Snake{{ID: "1"}, {ID: "2"}}
+	settings := rules.NewSettingsWithParams(rules.ParamHazardDamagePerTurn, "50").WithSeed(1)
+	editor := NewBoardStateEditor(initialBoardState.Clone())
+	require.NoError(t, m.SetupBoard(initialBoardState, settings, editor))
+	boardState := editor.boardState
+
+	require.Len(t, boardState.Snakes, 2)
+	for _, snake := range boardState.Snakes {
+		require.Len(t, snake.Body, rules.SnakeStartSize)
+	}
+	require.Equal(t, 2, countHazards(boardState, rules.Point{X: 2, Y: 2}))
+	require.Equal(t, 2, countHazards(boardState, rules.Point{X: 3, Y: 3}))
+}
+
+func TestFileMapSchedule(t *testing.T) {
+	m, err := LoadMapFile("testdata/crossroads.yaml")
+	require.NoError(t, err)
+	settings := rules.NewSettingsWithParams(rules.ParamHazardDamagePerTurn, "14").WithSeed(1)
+
+	boardState := rules.NewBoardState(11, 11)
+	boardState.Snakes = []rules.Snake{{ID: "1"}}
+	editor := NewBoardStateEditor(boardState.Clone())
+	require.NoError(t, m.SetupBoard(boardState, settings, editor))
+	boardState = editor.boardState
+	walls := len(boardState.Hazards)
+	centre := rules.Point{X: 5, Y: 5}
+
+	tests := []struct {
+		turn    int
+		hazards int
+	}{
+		{10, 0},
+		{24, 1},
+		{30, 1},
+		{34, 0},
+		{49, 1},
+		{59, 0},
+		{74, 1},
+		{84, 0},
+	}
+	for _, test := range tests {
+		boardState.Turn = test.turn
+		editor := NewBoardStateEditor(boardState.Clone())
+		require.NoError(t, m.PostUpdateBoard(boardState, settings, editor))
+		next := editor.boardState
+		require.Equal(t, test.hazards, countHazards(next, centre), "turn %d", test.turn+1)
+		require.Equal(t, walls+5*test.hazards, len(next.Hazards), "walls should remain on turn %d", test.turn+1)
+		boardState = next
+	}
+}
+
+func TestHazardChangeAppliesTo(t *testing.T) {
+	once := HazardChange{Turn: 5}
+	require.False(t, once.appliesTo(4))
+	require.True(t, once.appliesTo(5))
+	require.False(t, once.appliesTo(10))
+
+	repeating := HazardChange{Turn: 5, Every: 3, Until: 11}
+	require.False(t, repeating.appliesTo(4))
+	require.True(t, repeating.appliesTo(5))
+	require.False(t, repeating.appliesTo(6))
+	require.True(t, repeating.appliesTo(8))
+	require.True(t, repeating.appliesTo(11))
+	require.False(t, repeating.appliesTo(14))
+}
+
+func TestFileMapFoodSpawning(t *testing.T) {
+	m, err := ParseMapFile([]byte(`
+id: walled
+food:
+  minimumFood: 5
+layouts:
+  - size: 3x3
+    walls: [[0, 0], [1, 1], [2, 2]]
+    foodSpawns: [[0, 0], [1, 1], [2, 2], [0, 2], [2, 0], [1, 0]]
+`), "yaml")
+	require.NoError(t, err)
+	settings := rules.NewSettingsWithParams(rules.ParamHazardDamagePerTurn, "100").WithSeed(1)
+
+	boardState := rules.NewBoardState(3, 3)
+	boardState.Snakes = []rules.Snake{{ID: "1", Body: []rules.Point{{X: 0, Y: 1}}, Health: 100}}
+	editor := NewBoardStateEditor(boardState.Clone())
+	require.NoError(t, m.PostUpdateBoard(boardState, settings, editor))
+
+	// Only the spawn points that aren't walls can have food, even though more is needed
+	require.ElementsMatch(t, []rules.Point{{X: 0, Y: 2}, {X: 2, Y: 0}, {X: 1, Y: 0}}, editor.boardState.Food)
+
+	// No food is spawned with placement "none"
+	m.definition.Food.Placement = FoodPlacementNone
+	editor = NewBoardStateEditor(boardState.Clone())
+	require.NoError(t, m.PostUpdateBoard(boardState, settings, editor))
+	require.Empty(t, editor.boardState.Food)
+}
+
+func TestRegisterMapFile(t *testing.T) {
+	registry := MapRegistry{}
+	m, err := registry.RegisterMapFile("testdata/crossroads.yaml")
+	require.NoError(t, err)
+	require.Equal(t, "crossroads", m.ID())
+
+	got, err := registry.GetMap("crossroads")
+	require.NoError(t, err)
+	require.Same(t, m, got)
+
+	_, err = registry.RegisterMapFile("testdata/crossroads.yaml")
+	require.EqualError(t, err, "map 'crossroads' has already been registered")
+
+	_, err = registry.RegisterMapFile(filepath.Join(t.TempDir(), "map.txt"))
+	require.Error(t, err)
+	require.Equal(t, []string{"crossroads"}, registry.List())
+}
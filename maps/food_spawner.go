@@ -0,0 +1,331 @@
+package maps
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/Pikle2/rules"
+)
+
+// ParamFoodSpawner is the setting that chooses a registered FoodSpawner for maps that support it.
+const ParamFoodSpawner = "foodSpawner"
+
+// Built-in food spawners that can be chosen with the foodSpawner setting.
+const (
+	FoodSpawnerUniform      = "uniform"      // spawns food on any free square
+	FoodSpawnerDistanceFair = "distanceFair" // spawns food as close as possible to equidistant from the heads of all snakes
+	FoodSpawnerSymmetric    = "symmetric"    // spawns food with 180 degree rotational symmetry
+	FoodSpawnerMirrored     = "mirrored"     // spawns food mirrored across both axes of the board
+	FoodSpawnerClustered    = "clustered"    // spawns food in small clusters
+)
+
+const (
+	defaultClusterRadius = 1
+	defaultClusterSize   = 3
+)
+
+// FoodSpawner decides where new food spawns after each turn.
+// Spawners should place FoodNeeded food, so that they all respect the minimumFood and foodSpawnChance settings.
+// Spawners that place food in groups, like SymmetricFoodSpawner and ClusteredFoodSpawner, can place more food
+// than needed to complete the last group.
+type FoodSpawner interface {
+	// SpawnFood places new food on the board. Any randomness must come from the settings, so that games can be replayed.
+	SpawnFood(lastBoardState *rules.BoardState, settings rules.Settings, editor Editor) error
+}
+
+var foodSpawners = map[string]FoodSpawner{
+	FoodSpawnerUniform:      UniformFoodSpawner{},
+	FoodSpawnerDistanceFair: DistanceFairFoodSpawner{},
+	FoodSpawnerSymmetric:    SymmetricFoodSpawner{Symmetry: SymmetryRotational},
+	FoodSpawnerMirrored:     SymmetricFoodSpawner{Symmetry: SymmetryMirrored},
+	FoodSpawnerClustered:    ClusteredFoodSpawner{},
+}
+
+// RegisterFoodSpawner adds a food spawner that can be chosen with the foodSpawner setting.
+// If a spawner has already been registered with the same name this will panic.
+func RegisterFoodSpawner(name string, spawner FoodSpawner) {
+	if err := RegisterFoodSpawnerError(name, spawner); err != nil {
+		panic(err.Error())
+	}
+}
+
+// RegisterFoodSpawnerError adds a food spawner that can be chosen with the foodSpawner setting.
+// If a spawner has already been registered with the same name an error will be returned.
+func RegisterFoodSpawnerError(name string, spawner FoodSpawner) error {
+	if _, ok := foodSpawners[name]; ok {
+		return rules.RulesetError(fmt.Sprintf("food spawner '%s' has already been registered", name))
+	}
+	foodSpawners[name] = spawner
+	return nil
+}
+
+// GetFoodSpawner returns the food spawner registered with the given name.
+func GetFoodSpawner(name string) (FoodSpawner, error) {
+	spawner, ok := foodSpawners[name]
+	if !ok {
+		return nil, rules.RulesetError(fmt.Sprintf("unknown food spawner '%s'", name))
+	}
+	return spawner, nil
+}
+
+// FoodSpawnerFromSettings returns the food spawner chosen with the foodSpawner setting, or defaultSpawner if there isn't one.
+func FoodSpawnerFromSettings(settings rules.Settings, defaultSpawner FoodSpawner) (FoodSpawner, error) {
+	name := settings.Params()[ParamFoodSpawner]
+	if name == "" {
+		return defaultSpawner, nil
+	}
+	return GetFoodSpawner(name)
+}
+
+// FoodNeeded returns how much food to spawn this turn: enough to bring the board up to minimumFood,
+// or otherwise one food with a foodSpawnChance percent chance.
+func FoodNeeded(rand rules.Rand, settings rules.Settings, state *rules.BoardState) int {
+	minFood := settings.Int(rules.ParamMinimumFood, 0)
+	foodSpawnChance := settings.Int(rules.ParamFoodSpawnChance, 0)
+	numCurrentFood := len(state.Food)
+
+	if numCurrentFood < minFood {
+		return minFood - numCurrentFood
+	}
+	if foodSpawnChance > 0 && (100-rand.Intn(100)) < foodSpawnChance {
+		return 1
+	}
+
+	return 0
+}
+
+// UniformFoodSpawner spawns food on random free squares, like the standard map.
+type UniformFoodSpawner struct {
+	// AvoidHazards stops food from spawning on hazards.
+	AvoidHazards bool
+}
+
+func (s UniformFoodSpawner) SpawnFood(lastBoardState *rules.BoardState, settings rules.Settings, editor Editor) error {
+	rand := settings.GetRand(lastBoardState.Turn)
+
+	foodNeeded := FoodNeeded(rand, settings, lastBoardState)
+	if foodNeeded > 0 {
+		positions := rules.GetUnoccupiedPoints(lastBoardState, false, s.AvoidHazards)
+		placeFoodRandomlyAtPositions(rand, lastBoardState, editor, foodNeeded, positions)
+	}
+	return nil
+}
+
+// FixedFoodSpawner spawns food on random free squares from a fixed list of spawn points.
+type FixedFoodSpawner struct {
+	Positions []rules.Point
+	// AvoidHazards stops food from spawning on spawn points covered by hazards.
+	AvoidHazards bool
+}
+
+func (s FixedFoodSpawner) SpawnFood(lastBoardState *rules.BoardState, settings rules.Settings, editor Editor) error {
+	rand := settings.GetRand(lastBoardState.Turn)
+
+	foodNeeded := FoodNeeded(rand, settings, lastBoardState)
+	if foodNeeded > 0 {
+		positions := editor.FilterUnoccupiedPoints(s.Positions, true, s.AvoidHazards, true)
+		placeFoodRandomlyAtPositions(rand, lastBoardState, editor, foodNeeded, positions)
+	}
+	return nil
+}
+
+// DistanceFairFoodSpawner spawns food on the free squares that are closest to equidistant from the heads of all snakes,
+// so that no snake is closer to new food than the others. Ties are broken randomly.
+type DistanceFairFoodSpawner struct {
+	// AvoidHazards stops food from spawning on hazards.
+	AvoidHazards bool
+}
+
+func (s DistanceFairFoodSpawner) SpawnFood(lastBoardState *rules.BoardState, settings rules.Settings, editor Editor) error {
+	rand := settings.GetRand(lastBoardState.Turn)
+
+	foodNeeded := FoodNeeded(rand, settings, lastBoardState)
+	if foodNeeded == 0 {
+		return nil
+	}
+
+	var heads []rules.Point
+	for _, snake := range lastBoardState.Snakes {
+		if snake.EliminatedCause == rules.NotEliminated && len(snake.Body) > 0 {
+			heads = append(heads, snake.Body[0])
+		}
+	}
+
+	positions := rules.GetUnoccupiedPoints(lastBoardState, false, s.AvoidHazards)
+	rand.Shuffle(len(positions), func(i int, j int) {
+		positions[i], positions[j] = positions[j], positions[i]
+	})
+	spread := make(map[rules.Point]int, len(positions))
+	for _, p := range positions {
+		spread[p] = distanceSpread(p, heads)
+	}
+	sort.SliceStable(positions, func(i, j int) bool {
+		return spread[positions[i]] < spread[positions[j]]
+	})
+
+	for i := 0; i < foodNeeded && i < len(positions); i++ {
+		editor.AddFood(positions[i])
+	}
+	return nil
+}
+
+// distanceSpread returns the difference between the distances from a point to the closest and furthest heads.
+func distanceSpread(p rules.Point, heads []rules.Point) int {
+	if len(heads) == 0 {
+		return 0
+	}
+	closest, furthest := manhattanDistance(p, heads[0]), manhattanDistance(p, heads[0])
+	for _, head := range heads[1:] {
+		d := manhattanDistance(p, head)
+		if d < closest {
+			closest = d
+		}
+		if d > furthest {
+			furthest = d
+		}
+	}
+	return furthest - closest
+}
+
+// Symmetries of the food placed by a SymmetricFoodSpawner.
+const (
+	SymmetryRotational = "rotational" // food and its 180 degree rotation around the center of the board
+	SymmetryHorizontal = "horizontal" // food and its reflection from left to right
+	SymmetryVertical   = "vertical"   // food and its reflection from top to bottom
+	SymmetryMirrored   = "mirrored"   // food and its reflections across both axes
+)
+
+// SymmetricFoodSpawner spawns food in symmetric groups, so that snakes starting in symmetric positions have the same food.
+// Each group counts toward the food needed, so a turn can spawn more food than needed to complete a group.
+// Only groups where every square is free are spawned.
+type SymmetricFoodSpawner struct {
+	Symmetry string
+	// AvoidHazards stops food from spawning on hazards.
+	AvoidHazards bool
+}
+
+func (s SymmetricFoodSpawner) SpawnFood(lastBoardState *rules.BoardState, settings rules.Settings, editor Editor) error {
+	rand := settings.GetRand(lastBoardState.Turn)
+
+	foodNeeded := FoodNeeded(rand, settings, lastBoardState)
+	if foodNeeded == 0 {
+		return nil
+	}
+
+	positions := rules.GetUnoccupiedPoints(lastBoardState, false, s.AvoidHazards)
+	free := make(map[rules.Point]bool, len(positions))
+	for _, p := range positions {
+		free[p] = true
+	}
+	rand.Shuffle(len(positions), func(i int, j int) {
+		positions[i], positions[j] = positions[j], positions[i]
+	})
+
+	placed := 0
+	for _, p := range positions {
+		if placed >= foodNeeded {
+			break
+		}
+		group, err := s.group(p, lastBoardState.Width, lastBoardState.Height)
+		if err != nil {
+			return err
+		}
+		if !allPoints(group, free) {
+			continue
+		}
+		for _, food := range group {
+			editor.AddFood(food)
+			free[food] = false
+		}
+		placed += len(group)
+	}
+	return nil
+}
+
+// group returns a point and its symmetric points, without duplicates.
+func (s SymmetricFoodSpawner) group(p rules.Point, width, height int) ([]rules.Point, error) {
+	rotated := rules.Point{X: width - 1 - p.X, Y: height - 1 - p.Y}
+	horizontal := rules.Point{X: width - 1 - p.X, Y: p.Y}
+	vertical := rules.Point{X: p.X, Y: height - 1 - p.Y}
+
+	var points []rules.Point
+	switch s.Symmetry {
+	case SymmetryRotational:
+		points = []rules.Point{p, rotated}
+	case SymmetryHorizontal:
+		points = []rules.Point{p, horizontal}
+	case SymmetryVertical:
+		points = []rules.Point{p, vertical}
+	case SymmetryMirrored:
+		points = []rules.Point{p, horizontal, vertical, rotated}
+	default:
+		return nil, rules.RulesetError(fmt.Sprintf("unknown food symmetry '%s'", s.Symmetry))
+	}
+	return removeDuplicateValues(points), nil
+}
+
+func allPoints(points []rules.Point, set map[rules.Point]bool) bool {
+	for _, p := range points {
+		if !set[p] {
+			return false
+		}
+	}
+	return true
+}
+
+// ClusteredFoodSpawner spawns food in clusters of up to Size food, on free squares within Radius moves of a random free square.
+// Each cluster counts toward the food needed, so a turn can spawn more food than needed to complete a cluster.
+type ClusteredFoodSpawner struct {
+	// Radius is the furthest distance from the center of a cluster that its food can spawn, 1 if not set.
+	Radius int
+	// Size is the number of food in a cluster, 3 if not set.
+	Size int
+	// AvoidHazards stops food from spawning on hazards.
+	AvoidHazards bool
+}
+
+func (s ClusteredFoodSpawner) SpawnFood(lastBoardState *rules.BoardState, settings rules.Settings, editor Editor) error {
+	rand := settings.GetRand(lastBoardState.Turn)
+
+	foodNeeded := FoodNeeded(rand, settings, lastBoardState)
+	if foodNeeded == 0 {
+		return nil
+	}
+
+	radius, size := s.Radius, s.Size
+	if radius < 1 {
+		radius = defaultClusterRadius
+	}
+	if size < 1 {
+		size = defaultClusterSize
+	}
+
+	positions := rules.GetUnoccupiedPoints(lastBoardState, false, s.AvoidHazards)
+	rand.Shuffle(len(positions), func(i int, j int) {
+		positions[i], positions[j] = positions[j], positions[i]
+	})
+	placedFood := make(map[rules.Point]bool)
+
+	placed := 0
+	for _, center := range positions {
+		if placed >= foodNeeded {
+			break
+		}
+		if placedFood[center] {
+			continue
+		}
+		cluster := 0
+		for _, p := range positions {
+			if cluster >= size {
+				break
+			}
+			if !placedFood[p] && manhattanDistance(p, center) <= radius {
+				editor.AddFood(p)
+				placedFood[p] = true
+				cluster++
+			}
+		}
+		placed += cluster
+	}
+	return nil
+}
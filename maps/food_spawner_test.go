@@ -0,0 +1,175 @@
+package maps
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Pikle2/rules"
+	"github.com/stretchr/testify/require"
+)
+
+func foodSpawnerTestBoard() *rules.BoardState {
+	boardState := rules.NewBoardState(11, 11)
+	boardState.Snakes = []rules.Snake{
+		{ID: "1", Health: 100, Body: []rules.Point{{X: 1, Y: 5}, {X: 1, Y: 4}, {X: 1, Y: 3}}},
+		{ID: "2", Health: 100, Body: []rules.Point{{X: 9, Y: 5}, {X: 9, Y: 6}, {X: 9, Y: 7}}},
+	}
+	return boardState
+}
+
+func spawnFood(t *testing.T, spawner FoodSpawner, boardState *rules.BoardState, settings rules.Settings) []rules.Point {
+	nextBoardState := boardState.Clone()
+	require.NoError(t, spawner.SpawnFood(boardState, settings, NewBoardStateEditor(nextBoardState)))
+	return nextBoardState.Food
+}
+
+func TestFoodNeeded(t *testing.T) {
+	boardState := rules.NewBoardState(11, 11).WithFood([]rules.Point{{X: 1, Y: 1}})
+
+	require.Equal(t, 2, FoodNeeded(rules.MinRand, rules.NewSettingsWithParams(rules.ParamMinimumFood, "3"), boardState))
+	require.Equal(t, 0, FoodNeeded(rules.MinRand, rules.NewSettingsWithParams(rules.ParamMinimumFood, "1"), boardState))
+	require.Equal(t, 1, FoodNeeded(rules.MaxRand, rules.NewSettingsWithParams(rules.ParamFoodSpawnChance, "50"), boardState))
+	require.Equal(t, 0, FoodNeeded(rules.MinRand, rules.NewSettingsWithParams(rules.ParamFoodSpawnChance, "50"), boardState))
+	require.Equal(t, 0, FoodNeeded(rules.MaxRand, rules.NewSettingsWithParams(rules.ParamFoodSpawnChance, "0"), boardState))
+}
+
+func TestFoodSpawnersRespectSettings(t *testing.T) {
+	spawners := map[string]FoodSpawner{
+		"fixed": FixedFoodSpawner{Positions: []rules.Point{{X: 0, Y: 0}, {X: 10, Y: 10}, {X: 0, Y: 10}}},
+	}
+	for name, spawner := range foodSpawners {
+		spawners[name] = spawner
+	}
+
+	for name, spawner := range spawners {
+		t.Run(name, func(t *testing.T) {
+			settings := rules.NewSettingsWithParams(rules.ParamMinimumFood, "0", rules.ParamFoodSpawnChance, "0").WithSeed(42)
+			require.Empty(t, spawnFood(t, spawner, foodSpawnerTestBoard(), settings))
+
+			settings = rules.NewSettingsWithParams(rules.ParamMinimumFood, "2", rules.ParamFoodSpawnChance, "0").WithSeed(42)
+			food := spawnFood(t, spawner, foodSpawnerTestBoard(), settings)
+			require.GreaterOrEqual(t, len(food), 2)
+			require.Equal(t, food, spawnFood(t, spawner, foodSpawnerTestBoard(), settings), "spawning must be deterministic")
+			for _, p := range food {
+				require.False(t, NewBoardStateEditor(foodSpawnerTestBoard()).IsOccupied(p, true, false, false), "food can't spawn on snakes")
+			}
+
+			settings = rules.NewSettingsWithParams(rules.ParamMinimumFood, "0", rules.ParamFoodSpawnChance, "100").WithSeed(42)
+			require.NotEmpty(t, spawnFood(t, spawner, foodSpawnerTestBoard(), settings))
+		})
+	}
+}
+
+func TestFixedFoodSpawner(t *testing.T) {
+	boardState := foodSpawnerTestBoard()
+	boardState.Hazards = []rules.Point{{X: 10, Y: 10}}
+	positions := []rules.Point{{X: 1, Y: 5}, {X: 10, Y: 10}, {X: 0, Y: 10}}
+	settings := rules.NewSettingsWithParams(rules.ParamMinimumFood, "3")
+
+	require.ElementsMatch(t, []rules.Point{{X: 10, Y: 10}, {X: 0, Y: 10}}, spawnFood(t, FixedFoodSpawner{Positions: positions}, boardState, settings))
+	require.Equal(t, []rules.Point{{X: 0, Y: 10}}, spawnFood(t, FixedFoodSpawner{Positions: positions, AvoidHazards: true}, boardState, settings))
+	require.Equal(t, []rules.Point{{X: 1, Y: 5}, {X: 10, Y: 10}, {X: 0, Y: 10}}, positions, "spawn points must not be reordered")
+}
+
+func TestDistanceFairFoodSpawner(t *testing.T) {
+	settings := rules.NewSettingsWithParams(rules.ParamMinimumFood, "4").WithSeed(7)
+	food := spawnFood(t, DistanceFairFoodSpawner{}, foodSpawnerTestBoard(), settings)
+	require.Len(t, food, 4)
+	for _, p := range food {
+		require.Equal(t, 5, p.X, "food must be equidistant from both heads")
+	}
+
+	// Eliminated snakes are ignored
+	boardState := foodSpawnerTestBoard()
+	boardState.Snakes[1].EliminatedCause = rules.EliminatedByOutOfHealth
+	food = spawnFood(t, DistanceFairFoodSpawner{}, boardState, settings)
+	require.Len(t, food, 4)
+}
+
+func TestSymmetricFoodSpawner(t *testing.T) {
+	settings := rules.NewSettingsWithParams(rules.ParamMinimumFood, "1").WithSeed(3)
+
+	// Groups are always completed, so more food than needed can spawn
+	require.Equal(t, 1, FoodNeeded(settings.GetRand(0), settings, foodSpawnerTestBoard()))
+	food := spawnFood(t, SymmetricFoodSpawner{Symmetry: SymmetryRotational}, foodSpawnerTestBoard(), settings)
+	require.Len(t, food, 2)
+	require.Equal(t, rules.Point{X: 10 - food[0].X, Y: 10 - food[0].Y}, food[1])
+
+	food = spawnFood(t, SymmetricFoodSpawner{Symmetry: SymmetryMirrored}, foodSpawnerTestBoard(), settings)
+	require.Len(t, food, 4)
+	for _, p := range food {
+		require.Contains(t, food, rules.Point{X: 10 - p.X, Y: p.Y})
+		require.Contains(t, food, rules.Point{X: p.X, Y: 10 - p.Y})
+	}
+
+	boardState := foodSpawnerTestBoard()
+	err := SymmetricFoodSpawner{Symmetry: "spiral"}.SpawnFood(boardState, settings, NewBoardStateEditor(boardState.Clone()))
+	require.EqualError(t, err, "unknown food symmetry 'spiral'")
+}
+
+func TestClusteredFoodSpawner(t *testing.T) {
+	settings := rules.NewSettingsWithParams(rules.ParamMinimumFood, "1").WithSeed(5)
+
+	// Clusters are always completed, so more food than needed can spawn
+	require.Equal(t, 1, FoodNeeded(settings.GetRand(0), settings, foodSpawnerTestBoard()))
+	food := spawnFood(t, ClusteredFoodSpawner{}, foodSpawnerTestBoard(), settings)
+	require.Len(t, food, defaultClusterSize)
+	for _, a := range food {
+		for _, b := range food {
+			require.LessOrEqual(t, manhattanDistance(a, b), 2*defaultClusterRadius)
+		}
+	}
+
+	food = spawnFood(t, ClusteredFoodSpawner{Radius: 2, Size: 5}, foodSpawnerTestBoard(), settings)
+	require.Len(t, food, 5)
+}
+
+func TestFoodSpawnerFromSettings(t *testing.T) {
+	spawner, err := FoodSpawnerFromSettings(rules.Settings{}, UniformFoodSpawner{AvoidHazards: true})
+	require.NoError(t, err)
+	require.Equal(t, UniformFoodSpawner{AvoidHazards: true}, spawner)
+
+	spawner, err = FoodSpawnerFromSettings(rules.NewSettingsWithParams(ParamFoodSpawner, FoodSpawnerDistanceFair), UniformFoodSpawner{})
+	require.NoError(t, err)
+	require.Equal(t, DistanceFairFoodSpawner{}, spawner)
+
+	_, err = FoodSpawnerFromSettings(rules.NewSettingsWithParams(ParamFoodSpawner, "everywhere"), UniformFoodSpawner{})
+	require.EqualError(t, err, "unknown food spawner 'everywhere'")
+
+	require.EqualError(t, RegisterFoodSpawnerError(FoodSpawnerUniform, UniformFoodSpawner{}), "food spawner 'uniform' has already been registered")
+
+	// The standard map uses the spawner from the settings
+	settings := rules.NewSettingsWithParams(ParamFoodSpawner, FoodSpawnerDistanceFair, rules.ParamMinimumFood, "3").WithSeed(1)
+	boardState := foodSpawnerTestBoard()
+	nextBoardState := boardState.Clone()
+	require.NoError(t, StandardMap{}.PostUpdateBoard(boardState, settings, NewBoardStateEditor(nextBoardState)))
+	require.Len(t, nextBoardState.Food, 3)
+	for _, p := range nextBoardState.Food {
+		require.Equal(t, 5, p.X)
+	}
+}
+
+func TestMapsThatSpawnFoodDeclareFoodSpawner(t *testing.T) {
+	settings := rules.NewSettingsWithParams(ParamFoodSpawner, "nowhere", rules.ParamMinimumFood, "1", rules.ParamHazardDamagePerTurn, "14")
+	for _, id := range List() {
+		gameMap, err := GetMap(id)
+		require.NoError(t, err)
+		meta := gameMap.Meta()
+		width, height := rules.BoardSizeMedium, rules.BoardSizeMedium
+		if !meta.BoardSizes.IsUnlimited() && !meta.BoardSizes.IsAllowable(width, height) {
+			width, height = meta.BoardSizes[0].Width, meta.BoardSizes[0].Height
+		}
+		snakeIDs := []string{"1", "2"}
+		if meta.MaxPlayers == 1 {
+			snakeIDs = snakeIDs[:1]
+		}
+		boardState, err := SetupBoardWithMap(gameMap, rules.Settings{}.WithSeed(1), width, height, snakeIDs)
+		require.NoError(t, err, id)
+
+		// Maps that read the foodSpawner setting fail with an unknown spawner, and must declare it
+		_, err = PostUpdateBoard(gameMap, boardState, settings.WithSeed(1))
+		readsSpawner := err != nil && strings.Contains(err.Error(), "unknown food spawner")
+		_, declared := meta.Parameter(ParamFoodSpawner)
+		require.Equal(t, readsSpawner, declared, id)
+	}
+}
@@ -59,6 +59,8 @@ type Metadata struct {
 	BoardSizes sizes
 	// Tags is a list of strings use to categorize the map.
 	Tags []string
+	// Parameters is a list of the game settings that the map reads.
+	Parameters []Parameter
 }
 
 func (meta Metadata) Validate(boardState *rules.BoardState) error {
@@ -204,6 +206,79 @@ type Editor interface {
 	ShufflePoints(rules.Rand, []rules.Point)
 }
 
+// Editors can also implement the optional interfaces below. They were added after Editor, so they're optional to keep
+// existing Editor implementations working. Maps use them through the EditorPlaceBot, EditorEliminateSnake,
+// EditorSetHazardOwner and EditorMapState functions.
+
+// BotEditor is an Editor that can place bots.
+type BotEditor interface {
+	// Places a snake that is controlled by the game instead of a snake server, and moved by a BotPolicy.
+	PlaceBot(id string, body []rules.Point, health int, bot rules.Bot)
+}
+
+// EliminationEditor is an Editor that can eliminate snakes.
+type EliminationEditor interface {
+	// Eliminates a snake on the next turn, for maps that decide when snakes lose, such as by reaching a score limit.
+	EliminateSnake(id, cause, by string)
+}
+
+// HazardOwnerEditor is an Editor that can give hazards an owner.
+type HazardOwnerEditor interface {
+	// Sets the snake that owns the hazards on a tile, which is credited with eliminating snakes in them.
+	// An empty ID removes the owner. Owners of tiles left without hazards are removed after each map update.
+	SetHazardOwner(p rules.Point, id string)
+}
+
+// MapStateEditor is an Editor that stores the private state of maps.
+type MapStateEditor interface {
+	// Get the private state of a map, which is kept between turns but never sent to snakes.
+	MapState(mapID string) MapState
+}
+
+// errorEditorUnsupported returns the error for an Editor that doesn't implement an optional interface.
+func errorEditorUnsupported(feature string) error {
+	return rules.RulesetError(fmt.Sprintf("this map editor doesn't support %s", feature))
+}
+
+// EditorPlaceBot places a bot with an Editor, or returns an error if the Editor isn't a BotEditor.
+func EditorPlaceBot(editor Editor, id string, body []rules.Point, health int, bot rules.Bot) error {
+	botEditor, ok := editor.(BotEditor)
+	if !ok {
+		return errorEditorUnsupported("bots")
+	}
+	botEditor.PlaceBot(id, body, health, bot)
+	return nil
+}
+
+// EditorEliminateSnake eliminates a snake with an Editor, or returns an error if the Editor isn't an EliminationEditor.
+func EditorEliminateSnake(editor Editor, id, cause, by string) error {
+	eliminationEditor, ok := editor.(EliminationEditor)
+	if !ok {
+		return errorEditorUnsupported("eliminating snakes")
+	}
+	eliminationEditor.EliminateSnake(id, cause, by)
+	return nil
+}
+
+// EditorSetHazardOwner sets the owner of the hazards on a tile with an Editor,
+// or returns an error if the Editor isn't a HazardOwnerEditor.
+func EditorSetHazardOwner(editor Editor, p rules.Point, id string) error {
+	ownerEditor, ok := editor.(HazardOwnerEditor)
+	if !ok {
+		return errorEditorUnsupported("hazard owners")
+	}
+	ownerEditor.SetHazardOwner(p, id)
+	return nil
+}
+
+// EditorMapState returns the private state of a map. Editors that aren't a MapStateEditor keep it in their GameState.
+func EditorMapState(editor Editor, mapID string) MapState {
+	if stateEditor, ok := editor.(MapStateEditor); ok {
+		return stateEditor.MapState(mapID)
+	}
+	return NewMapState(editor.GameState(), mapID)
+}
+
 // An Editor backed by a BoardState.
 type BoardStateEditor struct {
 	boardState *rules.BoardState
@@ -261,6 +336,10 @@ func (editor *BoardStateEditor) Hazards() []rules.Point {
 	return append([]rules.Point(nil), editor.boardState.Hazards...)
 }
 
+func (editor *BoardStateEditor) SetHazardOwner(p rules.Point, id string) {
+	rules.SetHazardOwner(editor.boardState, p, id)
+}
+
 func (editor *BoardStateEditor) PlaceSnake(id string, body []rules.Point, health int) {
 	for index, snake := range editor.boardState.Snakes {
 		if snake.ID == id {
@@ -277,6 +356,20 @@ func (editor *BoardStateEditor) PlaceSnake(id string, body []rules.Point, health
 	})
 }
 
+func (editor *BoardStateEditor) PlaceBot(id string, body []rules.Point, health int, bot rules.Bot) {
+	editor.PlaceSnake(id, body, health)
+	rules.SetBot(editor.boardState, id, bot)
+}
+
+func (editor *BoardStateEditor) EliminateSnake(id, cause, by string) {
+	for index, snake := range editor.boardState.Snakes {
+		if snake.ID == id {
+			rules.EliminateSnake(&editor.boardState.Snakes[index], cause, by, editor.boardState.Turn+1)
+			return
+		}
+	}
+}
+
 // Get the bodies of all non-eliminated snakes currently on the board.
 // Note: the return value is read-only.
 func (editor *BoardStateEditor) SnakeBodies() map[string][]rules.Point {
@@ -294,6 +387,14 @@ func (editor *BoardStateEditor) GameState() map[string]string {
 	return editor.boardState.GameState
 }
 
+// Get the private state of a map, which is kept in the BoardState's GameState field
+func (editor *BoardStateEditor) MapState(mapID string) MapState {
+	if editor.boardState.GameState == nil {
+		editor.boardState.GameState = map[string]string{}
+	}
+	return NewMapState(editor.boardState.GameState, mapID)
+}
+
 // Get an editable reference to the BoardState's PointState field
 func (editor *BoardStateEditor) PointState() map[rules.Point]int {
 	return editor.boardState.PointState
@@ -113,6 +113,10 @@ func TestMapSizes(t *testing.T) {
 
 func TestBoardStateEditorInterface(t *testing.T) {
 	var _ Editor = (*BoardStateEditor)(nil)
+	var _ BotEditor = (*BoardStateEditor)(nil)
+	var _ EliminationEditor = (*BoardStateEditor)(nil)
+	var _ HazardOwnerEditor = (*BoardStateEditor)(nil)
+	var _ MapStateEditor = (*BoardStateEditor)(nil)
 }
 
 func TestBoardStateEditor(t *testing.T) {
@@ -499,3 +503,33 @@ func TestBoardStateEditorShufflePoints(t *testing.T) {
 
 	require.Equal(t, expected, points)
 }
+
+// basicEditor is an Editor that only has the required methods, like Editors written before the optional ones were added.
+type basicEditor struct {
+	Editor
+}
+
+func TestOptionalEditorInterfaces(t *testing.T) {
+	boardState := rules.NewBoardState(11, 11).WithSnakes([]rules.Snake{{ID: "one", Body: []rules.Point{{X: 1, Y: 1}}}})
+	boardState.GameState = map[string]string{}
+	editor := basicEditor{NewBoardStateEditor(boardState)}
+
+	// Map state falls back to the GameState, so it's the same as with a BoardStateEditor
+	EditorMapState(editor, "test").SetInt("level", 2)
+	require.Equal(t, 2, NewBoardStateEditor(boardState).MapState("test").Int("level", 0))
+
+	require.EqualError(t, EditorPlaceBot(editor, "bot", []rules.Point{{X: 5, Y: 5}}, 100, rules.Bot{}), "this map editor doesn't support bots")
+	require.EqualError(t, EditorEliminateSnake(editor, "one", rules.EliminatedByScoreLimit, ""), "this map editor doesn't support eliminating snakes")
+	require.EqualError(t, EditorSetHazardOwner(editor, rules.Point{X: 1, Y: 1}, "one"), "this map editor doesn't support hazard owners")
+	err := applyPluginOperations(editor, []PluginOperation{{Op: PluginOpSetHazardOwner, Point: &rules.Point{X: 1, Y: 1}, ID: "one"}})
+	require.EqualError(t, err, "operations[0]: this map editor doesn't support hazard owners")
+	require.Len(t, boardState.Snakes, 1)
+	require.Equal(t, rules.NotEliminated, boardState.Snakes[0].EliminatedCause)
+
+	// Layered maps pass on the errors of their layers
+	bots, err := NewLayeredMap(SnakeBotsMap{}, StandardMap{})
+	require.NoError(t, err)
+	initialBoardState := rules.NewBoardState(11, 11).WithSnakes([]rules.Snake{{ID: "one"}, {ID: "two"}})
+	err = bots.SetupBoard(initialBoardState, rules.Settings{}, basicEditor{NewBoardStateEditor(initialBoardState.Clone())})
+	require.EqualError(t, err, "this map editor doesn't support bots")
+}
@@ -24,6 +24,15 @@ func (m HazardPitsMap) Meta() Metadata {
 		MaxPlayers:  len(hazardPitStartPositions),
 		BoardSizes:  FixedSizes(Dimensions{11, 11}),
 		Tags:        []string{TAG_FOOD_PLACEMENT, TAG_HAZARD_PLACEMENT, TAG_SNAKE_PLACEMENT},
+		Parameters: []Parameter{
+			foodSpawnerParameter,
+			hazardDamageParameter,
+			{
+				Name:        rules.ParamShrinkEveryNTurns,
+				Type:        ParamTypeInt,
+				Description: "Number of turns between each time a layer of hazard pits is added or the pits are cleared",
+			},
+		},
 	}
 }
 
@@ -33,6 +33,7 @@ func (m InnerBorderHazardsMap) Meta() Metadata {
 		MaxPlayers:  16,
 		BoardSizes:  OddSizes(rules.BoardSizeSmall, rules.BoardSizeXXLarge),
 		Tags:        []string{TAG_HAZARD_PLACEMENT},
+		Parameters:  []Parameter{foodSpawnerParameter, hazardDamageParameter},
 	}
 }
 
@@ -78,6 +79,7 @@ func (m ConcentricRingsHazardsMap) Meta() Metadata {
 		MaxPlayers:  16,
 		BoardSizes:  OddSizes(rules.BoardSizeSmall, rules.BoardSizeXXLarge),
 		Tags:        []string{TAG_HAZARD_PLACEMENT},
+		Parameters:  []Parameter{foodSpawnerParameter, hazardDamageParameter},
 	}
 }
 
@@ -124,6 +126,7 @@ func (m ColumnsHazardsMap) Meta() Metadata {
 		MaxPlayers:  16,
 		BoardSizes:  OddSizes(rules.BoardSizeSmall, rules.BoardSizeXXLarge),
 		Tags:        []string{TAG_HAZARD_PLACEMENT},
+		Parameters:  []Parameter{foodSpawnerParameter, hazardDamageParameter},
 	}
 }
 
@@ -168,6 +171,7 @@ func (m SpiralHazardsMap) Meta() Metadata {
 		MaxPlayers: 16,
 		BoardSizes: OddSizes(rules.BoardSizeSmall, rules.BoardSizeXXLarge),
 		Tags:       []string{TAG_HAZARD_PLACEMENT},
+		Parameters: []Parameter{foodSpawnerParameter, hazardDamageParameter},
 	}
 }
 
@@ -265,6 +269,7 @@ func (m ScatterFillMap) Meta() Metadata {
 		MaxPlayers:  16,
 		BoardSizes:  OddSizes(rules.BoardSizeSmall, rules.BoardSizeXXLarge),
 		Tags:        []string{TAG_HAZARD_PLACEMENT},
+		Parameters:  []Parameter{foodSpawnerParameter, hazardDamageParameter},
 	}
 }
 
@@ -321,6 +326,7 @@ func (m DirectionalExpandingBoxMap) Meta() Metadata {
 		MaxPlayers:  16,
 		BoardSizes:  OddSizes(rules.BoardSizeSmall, rules.BoardSizeXXLarge),
 		Tags:        []string{TAG_HAZARD_PLACEMENT},
+		Parameters:  []Parameter{foodSpawnerParameter, hazardDamageParameter},
 	}
 }
 
@@ -440,6 +446,7 @@ func (m ExpandingBoxMap) Meta() Metadata {
 		MaxPlayers:  16,
 		BoardSizes:  OddSizes(rules.BoardSizeSmall, rules.BoardSizeXXLarge),
 		Tags:        []string{TAG_HAZARD_PLACEMENT},
+		Parameters:  []Parameter{foodSpawnerParameter, hazardDamageParameter},
 	}
 }
 
@@ -520,6 +527,7 @@ func (m ExpandingScatterMap) Meta() Metadata {
 		MaxPlayers:  16,
 		BoardSizes:  OddSizes(rules.BoardSizeSmall, rules.BoardSizeXXLarge),
 		Tags:        []string{TAG_HAZARD_PLACEMENT},
+		Parameters:  []Parameter{foodSpawnerParameter, hazardDamageParameter},
 	}
 }
 
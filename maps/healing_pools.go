@@ -26,6 +26,21 @@ func (m HealingPoolsMap) Meta() Metadata {
 		MaxPlayers:  8,
 		BoardSizes:  FixedSizes(Dimensions{7, 7}, Dimensions{11, 11}, Dimensions{19, 19}),
 		Tags:        []string{TAG_HAZARD_PLACEMENT},
+		Parameters: []Parameter{
+			foodSpawnerParameter,
+			{
+				Name:        rules.ParamHazardDamagePerTurn,
+				Type:        ParamTypeInt,
+				Default:     "0",
+				Description: "Health lost by a snake for each turn it ends in a pool, use a negative value to heal",
+			},
+			{
+				Name:        rules.ParamShrinkEveryNTurns,
+				Type:        ParamTypeInt,
+				Default:     "0",
+				Description: "Number of turns between each time a pool is removed, 0 means pools are never removed",
+			},
+		},
 	}
 }
 
@@ -6,18 +6,23 @@ import (
 
 // SetupBoard is a shortcut for looking up a map by ID and initializing a new board state with it.
 func SetupBoard(mapID string, settings rules.Settings, width, height int, snakeIDs []string) (*rules.BoardState, error) {
-	boardState := rules.NewBoardState(width, height)
-
-	rules.InitializeSnakes(boardState, snakeIDs)
-
 	gameMap, err := GetMap(mapID)
 	if err != nil {
 		return nil, err
 	}
 
+	return SetupBoardWithMap(gameMap, settings, width, height, snakeIDs)
+}
+
+// SetupBoardWithMap initializes a new board state with a map, which doesn't need to be registered.
+func SetupBoardWithMap(gameMap GameMap, settings rules.Settings, width, height int, snakeIDs []string) (*rules.BoardState, error) {
+	boardState := rules.NewBoardState(width, height)
+
+	rules.InitializeSnakes(boardState, snakeIDs)
+
 	editor := NewBoardStateEditor(boardState)
 
-	err = gameMap.SetupBoard(boardState, settings, editor)
+	err := gameMap.SetupBoard(boardState, settings, editor)
 	if err != nil {
 		return nil, err
 	}
@@ -25,7 +30,7 @@ func SetupBoard(mapID string, settings rules.Settings, width, height int, snakeI
 	return boardState, nil
 }
 
-// PreUpdateBoard updates a board state with a map.
+// PreUpdateBoard updates a board state with a map, and removes the owners of squares the map left without hazards.
 func PreUpdateBoard(gameMap GameMap, previousBoardState *rules.BoardState, settings rules.Settings) (*rules.BoardState, error) {
 	nextBoardState := previousBoardState.Clone()
 	editor := NewBoardStateEditor(nextBoardState)
@@ -34,10 +39,12 @@ func PreUpdateBoard(gameMap GameMap, previousBoardState *rules.BoardState, setti
 	if err != nil {
 		return nil, err
 	}
+	rules.PruneHazardOwners(nextBoardState)
 
 	return nextBoardState, nil
 }
 
+// PostUpdateBoard updates a board state with a map, and removes the owners of squares the map left without hazards.
 func PostUpdateBoard(gameMap GameMap, previousBoardState *rules.BoardState, settings rules.Settings) (*rules.BoardState, error) {
 	nextBoardState := previousBoardState.Clone()
 	editor := NewBoardStateEditor(nextBoardState)
@@ -46,6 +53,7 @@ func PostUpdateBoard(gameMap GameMap, previousBoardState *rules.BoardState, sett
 	if err != nil {
 		return nil, err
 	}
+	rules.PruneHazardOwners(nextBoardState)
 
 	return nextBoardState, nil
 }
@@ -0,0 +1,383 @@
+package maps
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+
+	"github.com/Pikle2/rules"
+)
+
+// LayerSeparator joins the IDs of registered maps into the ID of a LayeredMap, e.g. "standard+hz_spiral+snail_mode".
+const LayerSeparator = "+"
+
+// layerHazardsKeyPrefix is the MapState key prefix used to remember which hazards each hazard layer placed.
+const layerHazardsKeyPrefix = "hazards."
+
+// LayeredMap combines existing maps into one, each responsible for one part of the board:
+//   - the placement layer places the snakes and the starting food, since it's usually placed relative to the snakes
+//   - the food layer places food after the first turn
+//   - each hazard layer places its own hazards
+//
+// Changes a layer makes to snakes, food or hazards outside of its part of the board are ignored.
+// Hazard layers only see, and can only remove, the hazards that they placed themselves,
+// so that a layer that clears the hazards every turn doesn't remove the hazards of other layers.
+// Hazards that no layer placed, such as those added by the ruleset, are kept.
+// Everything else the Editor changes is shared by all layers and goes straight to the board,
+// including GameState, MapState, PointState, eliminations and hazard owners.
+//
+// Each map gets its own random number generator, seeded from the game seed and the map ID,
+// except for the placement layer which uses the game seed so that snakes start where they would
+// without the other layers.
+type LayeredMap struct {
+	id        string
+	placement GameMap
+	food      GameMap
+	hazards   []GameMap
+	meta      Metadata
+}
+
+// NewLayeredMap creates a map from a placement layer, a food layer and any number of hazard layers.
+// The same map can be used for more than one layer.
+// An error is returned if the maps don't have any board sizes or numbers of players in common.
+func NewLayeredMap(placement, food GameMap, hazards ...GameMap) (*LayeredMap, error) {
+	m := &LayeredMap{
+		placement: placement,
+		food:      food,
+		hazards:   hazards,
+	}
+
+	var ids []string
+	for _, layer := range m.layers() {
+		ids = append(ids, layer.ID())
+	}
+	m.id = strings.Join(ids, LayerSeparator)
+
+	meta, err := m.mergeMeta()
+	if err != nil {
+		return nil, err
+	}
+	m.meta = meta
+	return m, nil
+}
+
+// NewLayeredMapFromID creates a LayeredMap from an ID like "standard+hz_spiral+snail_mode".
+// The first map is the placement and food layer, and every map, including the first, is a hazard layer.
+// Each layer can have its own version, e.g. "standard@2+hz_spiral@1+snail_mode@2", see VersionedID.
+func (registry MapRegistry) NewLayeredMapFromID(id string) (*LayeredMap, error) {
+	var layers []GameMap
+	for _, layerID := range strings.Split(id, LayerSeparator) {
+		layer, err := registry.GetMap(layerID)
+		if err != nil {
+			return nil, fmt.Errorf("layer %#v: %w", layerID, err)
+		}
+		layers = append(layers, layer)
+	}
+	return NewLayeredMap(layers[0], layers[0], layers...)
+}
+
+// VersionedID returns the ID of the map with the version of every layer, e.g. "standard@2+hz_spiral@1+snail_mode@2",
+// which loads the same versions of the layers even after they change.
+// The version of a layered map is the sum of the versions of its layers, so it can't be used to load earlier versions.
+func (m *LayeredMap) VersionedID() string {
+	var ids []string
+	for _, layer := range m.layers() {
+		ids = append(ids, VersionedID(layer.ID(), layer.Meta().Version))
+	}
+	return strings.Join(ids, LayerSeparator)
+}
+
+// layers returns each map used by the layered map once, in order.
+func (m *LayeredMap) layers() []GameMap {
+	var layers []GameMap
+	seen := map[string]bool{}
+	for _, layer := range append([]GameMap{m.placement, m.food}, m.hazards...) {
+		if !seen[layer.ID()] {
+			seen[layer.ID()] = true
+			layers = append(layers, layer)
+		}
+	}
+	return layers
+}
+
+// mergeMeta combines the metadata of all layers. Board sizes and numbers of players are
+// limited to those supported by every layer.
+func (m *LayeredMap) mergeMeta() (Metadata, error) {
+	var names, authors, hazardNames []string
+	meta := Metadata{
+		BoardSizes: AnySize(),
+		Tags:       []string{},
+	}
+	seenAuthors := map[string]bool{}
+	seenTags := map[string]bool{}
+	for _, layer := range m.layers() {
+		layerMeta := layer.Meta()
+		names = append(names, layerMeta.Name)
+		if layerMeta.Author != "" && !seenAuthors[layerMeta.Author] {
+			seenAuthors[layerMeta.Author] = true
+			authors = append(authors, layerMeta.Author)
+		}
+		meta.Version += layerMeta.Version
+		if layerMeta.MinPlayers > meta.MinPlayers {
+			meta.MinPlayers = layerMeta.MinPlayers
+		}
+		if layerMeta.MaxPlayers != 0 && (meta.MaxPlayers == 0 || layerMeta.MaxPlayers < meta.MaxPlayers) {
+			meta.MaxPlayers = layerMeta.MaxPlayers
+		}
+		meta.BoardSizes = intersectSizes(meta.BoardSizes, layerMeta.BoardSizes)
+		for _, tag := range layerMeta.Tags {
+			if !seenTags[tag] {
+				seenTags[tag] = true
+				meta.Tags = append(meta.Tags, tag)
+			}
+		}
+		for _, p := range layerMeta.Parameters {
+			existing, ok := meta.Parameter(p.Name)
+			if !ok {
+				meta.Parameters = append(meta.Parameters, p)
+			} else if existing.Type != p.Type {
+				return Metadata{}, fmt.Errorf("maps %s can't be layered: parameter %s is both %s and %s", m.id, p.Name, existing.Type, p.Type)
+			}
+		}
+	}
+	for _, layer := range m.hazards {
+		hazardNames = append(hazardNames, layer.Meta().Name)
+	}
+
+	if len(meta.BoardSizes) == 0 {
+		return Metadata{}, fmt.Errorf("maps %s can't be layered: they have no board sizes in common", m.id)
+	}
+	if meta.MaxPlayers != 0 && meta.MinPlayers > meta.MaxPlayers {
+		return Metadata{}, fmt.Errorf("maps %s can't be layered: they have no numbers of players in common", m.id)
+	}
+
+	meta.Name = strings.Join(names, " + ")
+	meta.Author = strings.Join(authors, ", ")
+	meta.Description = fmt.Sprintf("Snakes placed by %s, food by %s, and hazards by %s",
+		m.placement.Meta().Name, m.food.Meta().Name, strings.Join(hazardNames, ", "))
+	return meta, nil
+}
+
+// intersectSizes returns the board sizes supported by both a and b.
+func intersectSizes(a, b sizes) sizes {
+	if a.IsUnlimited() {
+		return b
+	}
+	if b.IsUnlimited() {
+		return a
+	}
+	result := sizes{}
+	for _, size := range a {
+		if b.IsAllowable(size.Width, size.Height) {
+			result = append(result, size)
+		}
+	}
+	return result
+}
+
+func (m *LayeredMap) ID() string {
+	return m.id
+}
+
+func (m *LayeredMap) Meta() Metadata {
+	return m.meta
+}
+
+func (m *LayeredMap) SetupBoard(initialBoardState *rules.BoardState, settings rules.Settings, editor Editor) error {
+	if err := m.meta.Validate(initialBoardState); err != nil {
+		return err
+	}
+
+	placementEditor := &layerEditor{Editor: editor, snakes: true, food: true}
+	if err := placementEditor.update(GameMap.SetupBoard, m.placement, initialBoardState, m.layerSettings(m.placement, settings)); err != nil {
+		return err
+	}
+	return m.updateHazardLayers(initialBoardState, settings, editor, GameMap.SetupBoard)
+}
+
+func (m *LayeredMap) PreUpdateBoard(lastBoardState *rules.BoardState, settings rules.Settings, editor Editor) error {
+	return m.updateLayers(lastBoardState, settings, editor, GameMap.PreUpdateBoard)
+}
+
+func (m *LayeredMap) PostUpdateBoard(lastBoardState *rules.BoardState, settings rules.Settings, editor Editor) error {
+	return m.updateLayers(lastBoardState, settings, editor, GameMap.PostUpdateBoard)
+}
+
+type layerUpdateFunc func(layer GameMap, boardState *rules.BoardState, settings rules.Settings, editor Editor) error
+
+func (m *LayeredMap) updateLayers(lastBoardState *rules.BoardState, settings rules.Settings, editor Editor, update layerUpdateFunc) error {
+	foodEditor := &layerEditor{Editor: editor, food: true}
+	if err := foodEditor.update(update, m.food, lastBoardState, m.layerSettings(m.food, settings)); err != nil {
+		return err
+	}
+	return m.updateHazardLayers(lastBoardState, settings, editor, update)
+}
+
+// updateHazardLayers runs each hazard layer with only its own hazards, then puts the hazards of all layers on the board,
+// along with any hazards on the board that the layers didn't place.
+func (m *LayeredMap) updateHazardLayers(lastBoardState *rules.BoardState, settings rules.Settings, editor Editor, update layerUpdateFunc) error {
+	state := EditorMapState(editor, m.id)
+	placed := map[rules.Point]int{}
+	for i, layer := range m.hazards {
+		key := layerHazardsKeyPrefix + strconv.Itoa(i)
+		hazards, err := state.Points(key)
+		if err != nil {
+			return fmt.Errorf("layer %s: %w", layer.ID(), err)
+		}
+		for _, p := range hazards {
+			placed[p]++
+		}
+
+		layerState := *lastBoardState
+		layerState.Hazards = append([]rules.Point{}, hazards...)
+		hazardEditor := &layerEditor{Editor: editor, hazards: &hazards}
+		if err := hazardEditor.update(update, layer, &layerState, m.layerSettings(layer, settings)); err != nil {
+			return err
+		}
+		state.SetPoints(key, hazards)
+	}
+
+	// Hazards from elsewhere, such as a royale ruleset, are whatever is left once the hazards the layers placed
+	// before this update are taken away
+	var others []rules.Point
+	for _, p := range editor.Hazards() {
+		if placed[p] > 0 {
+			placed[p]--
+			continue
+		}
+		others = append(others, p)
+	}
+
+	editor.ClearHazards()
+	for _, p := range others {
+		editor.AddHazard(p)
+	}
+	for i := range m.hazards {
+		hazards, err := state.Points(layerHazardsKeyPrefix + strconv.Itoa(i))
+		if err != nil {
+			return err
+		}
+		for _, p := range hazards {
+			editor.AddHazard(p)
+		}
+	}
+	return nil
+}
+
+// layerSettings gives each map its own random number generator, except for the placement layer.
+// Settings without a seed use the same generator for every layer.
+func (m *LayeredMap) layerSettings(layer GameMap, settings rules.Settings) rules.Settings {
+	if layer.ID() == m.placement.ID() || settings.Seed() == 0 {
+		return settings
+	}
+	hash := fnv.New64a()
+	hash.Write([]byte(layer.ID()))
+	return settings.WithSeed(settings.Seed() ^ int64(hash.Sum64()))
+}
+
+// layerEditor is an Editor for one layer of a LayeredMap, which ignores changes outside of the layer's part of the board.
+// Hazard layers have their own list of hazards, which replaces the hazards on the board.
+// Optional Editor features are passed through to the board's editor, and err is set if it doesn't support one.
+type layerEditor struct {
+	Editor
+	snakes  bool
+	food    bool
+	hazards *[]rules.Point
+	err     error
+}
+
+// update runs a layer with this editor, and returns any error from the layer or its use of the editor.
+func (editor *layerEditor) update(update layerUpdateFunc, layer GameMap, boardState *rules.BoardState, settings rules.Settings) error {
+	if err := update(layer, boardState, settings, editor); err != nil {
+		return err
+	}
+	return editor.err
+}
+
+func (editor *layerEditor) fail(err error) {
+	if editor.err == nil {
+		editor.err = err
+	}
+}
+
+func (editor *layerEditor) ClearFood() {
+	if editor.food {
+		editor.Editor.ClearFood()
+	}
+}
+
+func (editor *layerEditor) AddFood(p rules.Point) {
+	if editor.food {
+		editor.Editor.AddFood(p)
+	}
+}
+
+func (editor *layerEditor) RemoveFood(p rules.Point) {
+	if editor.food {
+		editor.Editor.RemoveFood(p)
+	}
+}
+
+func (editor *layerEditor) ClearHazards() {
+	if editor.hazards != nil {
+		*editor.hazards = []rules.Point{}
+	}
+}
+
+func (editor *layerEditor) AddHazard(p rules.Point) {
+	if editor.hazards != nil {
+		*editor.hazards = append(*editor.hazards, p)
+	}
+}
+
+func (editor *layerEditor) RemoveHazard(p rules.Point) {
+	if editor.hazards == nil {
+		return
+	}
+	remaining := (*editor.hazards)[:0]
+	for _, hazard := range *editor.hazards {
+		if hazard != p {
+			remaining = append(remaining, hazard)
+		}
+	}
+	*editor.hazards = remaining
+}
+
+func (editor *layerEditor) Hazards() []rules.Point {
+	if editor.hazards != nil {
+		return append([]rules.Point(nil), *editor.hazards...)
+	}
+	return editor.Editor.Hazards()
+}
+
+func (editor *layerEditor) PlaceSnake(id string, body []rules.Point, health int) {
+	if editor.snakes {
+		editor.Editor.PlaceSnake(id, body, health)
+	}
+}
+
+func (editor *layerEditor) PlaceBot(id string, body []rules.Point, health int, bot rules.Bot) {
+	if editor.snakes {
+		editor.fail(EditorPlaceBot(editor.Editor, id, body, health, bot))
+	}
+}
+
+func (editor *layerEditor) EliminateSnake(id, cause, by string) {
+	editor.fail(EditorEliminateSnake(editor.Editor, id, cause, by))
+}
+
+func (editor *layerEditor) SetHazardOwner(p rules.Point, id string) {
+	editor.fail(EditorSetHazardOwner(editor.Editor, p, id))
+}
+
+func (editor *layerEditor) MapState(mapID string) MapState {
+	return EditorMapState(editor.Editor, mapID)
+}
+
+func (editor *layerEditor) PlaceSnakesRandomlyAtPositions(rand rules.Rand, snakes []rules.Snake, heads []rules.Point, bodyLength int) error {
+	if !editor.snakes {
+		return nil
+	}
+	return editor.Editor.PlaceSnakesRandomlyAtPositions(rand, snakes, heads, bodyLength)
+}
@@ -0,0 +1,179 @@
+package maps
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/Pikle2/rules"
+	"github.com/stretchr/testify/require"
+)
+
+// layerTestMap is a StubMap that supports any board size, and optionally replaces its hazards every turn
+// or doesn't change the board after setup.
+type layerTestMap struct {
+	StubMap
+	meta          Metadata
+	movingHazards bool
+	static        bool
+}
+
+func (m layerTestMap) Meta() Metadata {
+	meta := m.meta
+	if meta.BoardSizes == nil {
+		meta.BoardSizes = AnySize()
+	}
+	return meta
+}
+
+func (m layerTestMap) PostUpdateBoard(lastBoardState *rules.BoardState, settings rules.Settings, editor Editor) error {
+	if m.movingHazards {
+		// Moves a single hazard one square right each turn
+		editor.ClearHazards()
+		editor.AddHazard(rules.Point{X: lastBoardState.Turn + 1, Y: 0})
+		return nil
+	}
+	if m.static {
+		return nil
+	}
+	return m.StubMap.PostUpdateBoard(lastBoardState, settings, editor)
+}
+
+func TestLayeredMapFromRegistry(t *testing.T) {
+	gameMap, err := GetMap("standard+hz_spiral+snail_mode")
+	require.NoError(t, err)
+	require.Equal(t, "standard+hz_spiral+snail_mode", gameMap.ID())
+
+	meta := gameMap.Meta()
+	require.Equal(t, "Standard + hz_spiral + Snail Mode", meta.Name)
+	require.Equal(t, "Battlesnake, altersaddle, coreyja and jlafayette", meta.Author)
+	require.Equal(t, "Snakes placed by Standard, food by Standard, and hazards by Standard, hz_spiral, Snail Mode", meta.Description)
+	require.Equal(t, StandardMap{}.Meta().Version+SpiralHazardsMap{}.Meta().Version+SnailModeMap{}.Meta().Version, meta.Version)
+	require.Equal(t, []string{TAG_HAZARD_PLACEMENT, TAG_EXPERIMENTAL}, meta.Tags)
+	require.Equal(t, []Parameter{foodSpawnerParameter, snakePlacementParameter, startBodyParameter, hazardDamageParameter}, meta.Parameters)
+	require.Equal(t, SnailModeMap{}.Meta().BoardSizes, meta.BoardSizes)
+
+	// Each layer can have its own version
+	require.Equal(t, fmt.Sprintf("standard@%d+hz_spiral@%d+snail_mode@%d", StandardMap{}.Meta().Version, SpiralHazardsMap{}.Meta().Version, SnailModeMap{}.Meta().Version), gameMap.(*LayeredMap).VersionedID())
+	gameMap, err = GetMap("standard+snail_mode@1")
+	require.NoError(t, err)
+	require.Equal(t, "standard+snail_mode", gameMap.ID())
+	require.Equal(t, StandardMap{}.Meta().Version+1, gameMap.Meta().Version)
+	require.Equal(t, fmt.Sprintf("standard@%d+snail_mode@1", StandardMap{}.Meta().Version), gameMap.(*LayeredMap).VersionedID())
+	_, err = GetMap("standard+snail_mode@9")
+	require.EqualError(t, err, `layer "snail_mode@9": version 9 of map 'snail_mode' is not available, available versions: [1 2 3]`)
+	_, err = GetMapVersion("standard+snail_mode", 1)
+	require.ErrorContains(t, err, "layered map 'standard+snail_mode' can't be given a version, give the version of each layer instead")
+
+	_, err = GetMap("standard+nope")
+	require.True(t, errors.Is(err, rules.ErrorMapNotFound))
+	require.EqualError(t, err, `layer "nope": map not found`)
+}
+
+func TestLayeredMapIncompatible(t *testing.T) {
+	small := layerTestMap{StubMap: StubMap{Id: "small"}, meta: Metadata{
+		MinPlayers: 1, MaxPlayers: 2, BoardSizes: FixedSizes(Dimensions{7, 7}),
+	}}
+	large := layerTestMap{StubMap: StubMap{Id: "large"}, meta: Metadata{
+		MinPlayers: 4, MaxPlayers: 8, BoardSizes: FixedSizes(Dimensions{7, 7}, Dimensions{19, 19}),
+	}}
+	huge := layerTestMap{StubMap: StubMap{Id: "huge"}, meta: Metadata{BoardSizes: FixedSizes(Dimensions{25, 25})}}
+	typed := layerTestMap{StubMap: StubMap{Id: "typed"}, meta: Metadata{
+		Parameters: []Parameter{{Name: rules.ParamHazardDamagePerTurn, Type: ParamTypeString}},
+	}}
+
+	_, err := NewLayeredMap(small, small, large)
+	require.EqualError(t, err, "maps small+large can't be layered: they have no numbers of players in common")
+	_, err = NewLayeredMap(large, large, huge)
+	require.EqualError(t, err, "maps large+huge can't be layered: they have no board sizes in common")
+	_, err = NewLayeredMap(small, small, typed, InnerBorderHazardsMap{})
+	require.EqualError(t, err, "maps small+typed+hz_inner_wall can't be layered: parameter damagePerTurn is both string and int")
+}
+
+func TestLayeredMapLayers(t *testing.T) {
+	placement := layerTestMap{StubMap: StubMap{
+		Id:             "placement",
+		SnakePositions: map[string]rules.Point{"1": {X: 1, Y: 1}},
+		Food:           []rules.Point{{X: 2, Y: 2}},
+		Hazards:        []rules.Point{{X: 3, Y: 3}},
+	}}
+	food := layerTestMap{StubMap: StubMap{
+		Id:             "food",
+		SnakePositions: map[string]rules.Point{"1": {X: 4, Y: 4}},
+		Food:           []rules.Point{{X: 5, Y: 5}},
+	}}
+	walls := layerTestMap{StubMap: StubMap{
+		Id:      "walls",
+		Food:    []rules.Point{{X: 6, Y: 6}},
+		Hazards: []rules.Point{{X: 0, Y: 6}},
+	}, static: true}
+	moving := layerTestMap{StubMap: StubMap{Id: "moving"}, movingHazards: true}
+
+	gameMap, err := NewLayeredMap(placement, food, walls, moving)
+	require.NoError(t, err)
+	require.Equal(t, "placement+food+walls+moving", gameMap.ID())
+
+	// Only the placement layer places snakes and starting food, and the placement layer's hazards are ignored
+	boardState, err := SetupBoardWithMap(gameMap, rules.Settings{}, 7, 7, []string{"1"})
+	require.NoError(t, err)
+	require.Equal(t, []rules.Point{{X: 1, Y: 1}, {X: 1, Y: 1}, {X: 1, Y: 1}}, boardState.Snakes[0].Body)
+	require.Equal(t, []rules.Point{{X: 2, Y: 2}}, boardState.Food)
+	require.Equal(t, []rules.Point{{X: 0, Y: 6}}, boardState.Hazards)
+
+	// The moving layer clears its own hazards each turn without removing the walls
+	for turn := 0; turn < 3; turn++ {
+		boardState.Turn = turn
+		boardState, err = PostUpdateBoard(gameMap, boardState, rules.Settings{})
+		require.NoError(t, err)
+		require.Equal(t, []rules.Point{{X: 1, Y: 1}, {X: 1, Y: 1}, {X: 1, Y: 1}}, boardState.Snakes[0].Body)
+		require.Equal(t, []rules.Point{{X: 0, Y: 6}, {X: turn + 1, Y: 0}}, boardState.Hazards)
+	}
+	require.Equal(t, []rules.Point{{X: 2, Y: 2}, {X: 5, Y: 5}, {X: 5, Y: 5}, {X: 5, Y: 5}}, boardState.Food)
+
+	// Hazards that no layer placed, like those of a royale ruleset, are kept alongside the layers' hazards
+	boardState.Hazards = []rules.Point{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 2, Y: 0}, {X: 3, Y: 0}}
+	boardState.Turn = 3
+	boardState, err = PostUpdateBoard(gameMap, boardState, rules.Settings{})
+	require.NoError(t, err)
+	require.Equal(t, []rules.Point{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 2, Y: 0}, {X: 0, Y: 6}, {X: 4, Y: 0}}, boardState.Hazards)
+}
+
+func TestLayeredMapWithRoyaleRuleset(t *testing.T) {
+	settings := rules.NewSettingsWithParams(rules.ParamShrinkEveryNTurns, "1").WithSeed(3)
+	gameMap, err := GetMap("standard+hz_spiral")
+	require.NoError(t, err)
+	ruleset := rules.NewRulesetBuilder().WithSettings(settings).NamedRuleset(rules.GameTypeRoyale)
+
+	boardState, err := SetupBoardWithMap(gameMap, settings, 11, 11, []string{"1", "2"})
+	require.NoError(t, err)
+	_, boardState, err = ruleset.Execute(boardState, []rules.SnakeMove{{ID: "1", Move: rules.MoveUp}, {ID: "2", Move: rules.MoveUp}})
+	require.NoError(t, err)
+	royaleHazards, _, err := rules.RoyaleHazards(11, 11, 1, settings)
+	require.NoError(t, err)
+	require.ElementsMatch(t, royaleHazards, boardState.Hazards)
+
+	boardState, err = PostUpdateBoard(gameMap, boardState, settings)
+	require.NoError(t, err)
+	for _, p := range royaleHazards {
+		require.Contains(t, boardState.Hazards, p)
+	}
+}
+
+func TestLayeredMapSeeds(t *testing.T) {
+	settings := rules.NewSettingsWithParams(rules.ParamMinimumFood, "1").WithSeed(42)
+	standard, err := SetupBoard("standard", settings, 11, 11, []string{"1", "2", "3", "4"})
+	require.NoError(t, err)
+
+	// The placement layer uses the game seed, so snakes and starting food are where they would be without other layers
+	gameMap, err := GetMap("standard+hz_scatter")
+	require.NoError(t, err)
+	layered, err := SetupBoardWithMap(gameMap, settings, 11, 11, []string{"1", "2", "3", "4"})
+	require.NoError(t, err)
+	require.Equal(t, standard.Snakes, layered.Snakes)
+	require.Equal(t, standard.Food, layered.Food)
+
+	// Other layers get their own seed
+	layerSettings := gameMap.(*LayeredMap).layerSettings(ScatterFillMap{}, settings)
+	require.NotEqual(t, settings.Seed(), layerSettings.Seed())
+	require.Equal(t, layerSettings.Seed(), gameMap.(*LayeredMap).layerSettings(ScatterFillMap{}, settings).Seed())
+}
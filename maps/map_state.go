@@ -0,0 +1,112 @@
+package maps
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Pikle2/rules"
+)
+
+// mapStateKeyPrefix is the GameState key prefix for the private state of maps.
+const mapStateKeyPrefix = "map."
+
+// MapState is a map's private store for state that needs to be kept between turns, such as the current level of a maze.
+// It's kept in the board's GameState under keys prefixed with the map ID, so each map only sees its own values.
+// GameState is never sent to snakes, so unlike hazards placed off the board, this state isn't visible in snake requests or viewers.
+type MapState struct {
+	gameState map[string]string
+	prefix    string
+}
+
+// NewMapState returns the state of the map with the given ID, stored in a GameState.
+func NewMapState(gameState map[string]string, mapID string) MapState {
+	return MapState{
+		gameState: gameState,
+		prefix:    mapStateKeyPrefix + mapID + ".",
+	}
+}
+
+// Has reports whether a value is stored for the key.
+func (s MapState) Has(key string) bool {
+	_, ok := s.gameState[s.prefix+key]
+	return ok
+}
+
+// Delete removes the value stored for the key.
+func (s MapState) Delete(key string) {
+	delete(s.gameState, s.prefix+key)
+}
+
+// Int returns the int stored for the key, or defaultValue if there isn't a valid one.
+func (s MapState) Int(key string, defaultValue int) int {
+	if value, ok := s.gameState[s.prefix+key]; ok {
+		if i, err := strconv.Atoi(value); err == nil {
+			return i
+		}
+	}
+	return defaultValue
+}
+
+// SetInt stores an int for the key.
+func (s MapState) SetInt(key string, value int) {
+	s.gameState[s.prefix+key] = strconv.Itoa(value)
+}
+
+// Points returns the list of points stored for the key, or an empty list if there isn't one.
+// Duplicate points are kept, so the list can be used to stack hazards.
+func (s MapState) Points(key string) ([]rules.Point, error) {
+	points, err := decodePoints(s.gameState[s.prefix+key])
+	if err != nil {
+		return nil, fmt.Errorf("map state %s: %w", key, err)
+	}
+	return points, nil
+}
+
+// SetPoints stores a list of points for the key.
+func (s MapState) SetPoints(key string, points []rules.Point) {
+	s.gameState[s.prefix+key] = encodePoints(points)
+}
+
+// JSON decodes the JSON value stored for the key into v, and reports whether there was a value.
+func (s MapState) JSON(key string, v interface{}) (bool, error) {
+	value, ok := s.gameState[s.prefix+key]
+	if !ok {
+		return false, nil
+	}
+	if err := json.Unmarshal([]byte(value), v); err != nil {
+		return false, fmt.Errorf("map state %s: %w", key, err)
+	}
+	return true, nil
+}
+
+// SetJSON stores v encoded as JSON for the key.
+func (s MapState) SetJSON(key string, v interface{}) error {
+	value, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("map state %s: %w", key, err)
+	}
+	s.gameState[s.prefix+key] = string(value)
+	return nil
+}
+
+func encodePoints(points []rules.Point) string {
+	parts := make([]string, 0, len(points))
+	for _, p := range points {
+		parts = append(parts, fmt.Sprintf("%d,%d", p.X, p.Y))
+	}
+	return strings.Join(parts, " ")
+}
+
+func decodePoints(value string) ([]rules.Point, error) {
+	points := []rules.Point{}
+	for _, part := range strings.Fields(value) {
+		var p rules.Point
+		if n, err := fmt.Sscanf(part, "%d,%d", &p.X, &p.Y); err != nil || n != 2 {
+			return nil, fmt.Errorf("invalid point %q", part)
+		}
+		points = append(points, p)
+	}
+	return points, nil
+}
@@ -0,0 +1,63 @@
+package maps
+
+import (
+	"testing"
+
+	"github.com/Pikle2/rules"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapState(t *testing.T) {
+	gameState := map[string]string{"level": "not a map's"}
+	state := NewMapState(gameState, "maze")
+	other := NewMapState(gameState, "other")
+
+	require.False(t, state.Has("level"))
+	require.Equal(t, 3, state.Int("level", 3))
+	state.SetInt("level", 7)
+	require.True(t, state.Has("level"))
+	require.Equal(t, 7, state.Int("level", 3))
+	require.False(t, other.Has("level"), "maps don't see each other's state")
+	require.Equal(t, "not a map's", gameState["level"])
+
+	points, err := state.Points("tails")
+	require.NoError(t, err)
+	require.Empty(t, points)
+	tails := []rules.Point{{X: 1, Y: 2}, {X: 1, Y: 2}, {X: -1, Y: 10}}
+	state.SetPoints("tails", tails)
+	points, err = state.Points("tails")
+	require.NoError(t, err)
+	require.Equal(t, tails, points)
+	gameState["map.maze.broken"] = "1,2 x"
+	_, err = state.Points("broken")
+	require.EqualError(t, err, `map state broken: invalid point "x"`)
+
+	type room struct {
+		Doors []rules.Point
+		Open  bool
+	}
+	var loaded room
+	ok, err := state.JSON("room", &loaded)
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.NoError(t, state.SetJSON("room", room{Doors: []rules.Point{{X: 3, Y: 4}}, Open: true}))
+	ok, err = state.JSON("room", &loaded)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, room{Doors: []rules.Point{{X: 3, Y: 4}}, Open: true}, loaded)
+	require.Error(t, state.SetJSON("func", func() {}))
+
+	state.Delete("room")
+	require.False(t, state.Has("room"))
+	require.True(t, state.Has("level"))
+}
+
+func TestEditorMapState(t *testing.T) {
+	boardState := rules.NewBoardState(7, 7)
+	boardState.GameState = nil
+	NewBoardStateEditor(boardState).MapState("maze").SetInt("level", 2)
+	require.Equal(t, map[string]string{"map.maze.level": "2"}, boardState.GameState)
+
+	// State is kept in the next turn's board
+	require.Equal(t, 2, NewBoardStateEditor(boardState.Clone()).MapState("maze").Int("level", 0))
+}
@@ -0,0 +1,211 @@
+package maps
+
+import (
+	"fmt"
+
+	"github.com/Pikle2/rules"
+)
+
+// ParamMoveEveryNTurns is how often the hazards of maps with moving hazards move.
+const ParamMoveEveryNTurns = "moveEveryNTurns"
+
+const (
+	defaultConveyorMoveEveryNTurns = 2
+	defaultWindmillMoveEveryNTurns = 5
+
+	// conveyorSegmentLength and conveyorSpacing are the length of each hazard segment on a belt
+	// and the distance between the starts of consecutive segments.
+	conveyorSegmentLength = 3
+	conveyorSpacing       = 6
+
+	// windmillPoolEveryNTurns is how often the pools around the windmill drift one square.
+	windmillPoolEveryNTurns = 2
+)
+
+func init() {
+	globalRegistry.RegisterMap("hz_conveyors", ConveyorBeltsMap{})
+	globalRegistry.RegisterMap("hz_windmill", WindmillMap{})
+}
+
+// moveEveryNTurns reads how often hazards move, which must be at least 1.
+func moveEveryNTurns(settings rules.Settings, defaultValue int) (int, error) {
+	every := settings.Int(ParamMoveEveryNTurns, defaultValue)
+	if every < 1 {
+		return 0, rules.RulesetError(fmt.Sprintf("%s must be at least 1", ParamMoveEveryNTurns))
+	}
+	return every, nil
+}
+
+// ConveyorBeltsMap places rows of hazard segments between the snakes' starting rows, which slide across the board
+// and wrap around the edges. Neighbouring belts move in opposite directions.
+type ConveyorBeltsMap struct{}
+
+func (m ConveyorBeltsMap) ID() string {
+	return "hz_conveyors"
+}
+
+func (m ConveyorBeltsMap) Meta() Metadata {
+	return Metadata{
+		Name:        "Conveyor Belts",
+		Description: "Rows of hazards slide across the board in alternating directions, wrapping around the edges",
+		Author:      "Battlesnake",
+		Version:     1,
+		MinPlayers:  1,
+		MaxPlayers:  16,
+		BoardSizes:  OddSizes(rules.BoardSizeMedium, rules.BoardSizeXXLarge),
+		Tags:        []string{TAG_EXPERIMENTAL, TAG_HAZARD_PLACEMENT, TAG_FOOD_PLACEMENT},
+		Parameters: []Parameter{
+			hazardDamageParameter,
+			{
+				Name:        ParamMoveEveryNTurns,
+				Type:        ParamTypeInt,
+				Default:     fmt.Sprint(defaultConveyorMoveEveryNTurns),
+				Description: "Turns between each move of the belts by one square",
+			},
+		},
+	}
+}
+
+// belts returns one hazard per belt, on every fourth row starting from row 3 and ending at least 3 rows from the top.
+func (m ConveyorBeltsMap) belts(width, height, every int) []MovingHazard {
+	var belts []MovingHazard
+	for i, y := 0, 3; y <= height-4; i, y = i+1, y+4 {
+		belt := TranslatingHazard{Step: rules.Point{X: 1}, Every: every, Wrap: true}
+		if i%2 == 1 {
+			belt.Step.X = -1
+		}
+		for start := 0; start+conveyorSegmentLength <= width; start += conveyorSpacing {
+			for x := start; x < start+conveyorSegmentLength; x++ {
+				belt.Shape = append(belt.Shape, rules.Point{X: x, Y: y})
+			}
+		}
+		belts = append(belts, belt)
+	}
+	return belts
+}
+
+func (m ConveyorBeltsMap) SetupBoard(initialBoardState *rules.BoardState, settings rules.Settings, editor Editor) error {
+	if err := m.Meta().Validate(initialBoardState); err != nil {
+		return err
+	}
+	every, err := moveEveryNTurns(settings, defaultConveyorMoveEveryNTurns)
+	if err != nil {
+		return err
+	}
+	if err := (StandardMap{}).SetupBoard(initialBoardState, settings, editor); err != nil {
+		return err
+	}
+
+	PlaceMovingHazards(editor, 0, initialBoardState.Width, initialBoardState.Height, m.belts(initialBoardState.Width, initialBoardState.Height, every)...)
+	removeFoodUnderHazards(editor)
+	return nil
+}
+
+func (m ConveyorBeltsMap) PreUpdateBoard(lastBoardState *rules.BoardState, settings rules.Settings, editor Editor) error {
+	every, err := moveEveryNTurns(settings, defaultConveyorMoveEveryNTurns)
+	if err != nil {
+		return err
+	}
+	PlaceMovingHazards(editor, lastBoardState.Turn, lastBoardState.Width, lastBoardState.Height, m.belts(lastBoardState.Width, lastBoardState.Height, every)...)
+	return nil
+}
+
+// PostUpdateBoard spawns food like the standard map, but not where the belts will be next turn.
+func (m ConveyorBeltsMap) PostUpdateBoard(lastBoardState *rules.BoardState, settings rules.Settings, editor Editor) error {
+	every, err := moveEveryNTurns(settings, defaultConveyorMoveEveryNTurns)
+	if err != nil {
+		return err
+	}
+	placeFoodAvoidingMovingHazards(lastBoardState, settings, editor, m.belts(lastBoardState.Width, lastBoardState.Height, every)...)
+	return nil
+}
+
+// WindmillMap places two hazard blades at the center of the board which turn a quarter turn clockwise
+// every few turns, and two hazard pools that drift around the windmill in the opposite direction.
+type WindmillMap struct{}
+
+func (m WindmillMap) ID() string {
+	return "hz_windmill"
+}
+
+func (m WindmillMap) Meta() Metadata {
+	return Metadata{
+		Name:        "Windmill",
+		Description: "Hazard blades turn around the center of the board while hazard pools drift around them",
+		Author:      "Battlesnake",
+		Version:     1,
+		MinPlayers:  1,
+		MaxPlayers:  16,
+		BoardSizes:  OddSizes(rules.BoardSizeMedium, rules.BoardSizeXXLarge),
+		Tags:        []string{TAG_EXPERIMENTAL, TAG_HAZARD_PLACEMENT, TAG_FOOD_PLACEMENT},
+		Parameters: []Parameter{
+			hazardDamageParameter,
+			{
+				Name:        ParamMoveEveryNTurns,
+				Type:        ParamTypeInt,
+				Default:     fmt.Sprint(defaultWindmillMoveEveryNTurns),
+				Description: "Turns between each quarter turn of the windmill",
+			},
+		},
+	}
+}
+
+// hazards returns the windmill blades, which leave 2 empty squares before the snakes' starting positions on the edges,
+// and the pools, which follow a square path 2 squares in from the edges of the board.
+func (m WindmillMap) hazards(width, height, every int) []MovingHazard {
+	center := rules.Point{X: (width - 1) / 2, Y: (height - 1) / 2}
+	// The starts on the edges are 1 square in from the edge, so center.X-1 squares from the center
+	length := (center.X - 1) - 3
+	blades := RotatingHazard{Center: center, Every: every, Shape: []rules.Point{{X: 0, Y: 0}}}
+	for i := 1; i <= length; i++ {
+		blades.Shape = append(blades.Shape, rules.Point{X: i, Y: 0}, rules.Point{X: 0, Y: i})
+	}
+
+	// The path is reversed so that the pools drift counterclockwise
+	path := ringPath(2, 2, width-4, height-4)
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	pool := []rules.Point{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 0, Y: 1}, {X: 1, Y: 1}}
+	return []MovingHazard{
+		blades,
+		PathHazard{Shape: pool, Path: path, Every: windmillPoolEveryNTurns},
+		PathHazard{Shape: pool, Path: path, Every: windmillPoolEveryNTurns, Offset: len(path) / 2},
+	}
+}
+
+func (m WindmillMap) SetupBoard(initialBoardState *rules.BoardState, settings rules.Settings, editor Editor) error {
+	if err := m.Meta().Validate(initialBoardState); err != nil {
+		return err
+	}
+	every, err := moveEveryNTurns(settings, defaultWindmillMoveEveryNTurns)
+	if err != nil {
+		return err
+	}
+	if err := (StandardMap{}).SetupBoard(initialBoardState, settings, editor); err != nil {
+		return err
+	}
+
+	PlaceMovingHazards(editor, 0, initialBoardState.Width, initialBoardState.Height, m.hazards(initialBoardState.Width, initialBoardState.Height, every)...)
+	removeFoodUnderHazards(editor)
+	return nil
+}
+
+func (m WindmillMap) PreUpdateBoard(lastBoardState *rules.BoardState, settings rules.Settings, editor Editor) error {
+	every, err := moveEveryNTurns(settings, defaultWindmillMoveEveryNTurns)
+	if err != nil {
+		return err
+	}
+	PlaceMovingHazards(editor, lastBoardState.Turn, lastBoardState.Width, lastBoardState.Height, m.hazards(lastBoardState.Width, lastBoardState.Height, every)...)
+	return nil
+}
+
+// PostUpdateBoard spawns food like the standard map, but not where the windmill and pools will be next turn.
+func (m WindmillMap) PostUpdateBoard(lastBoardState *rules.BoardState, settings rules.Settings, editor Editor) error {
+	every, err := moveEveryNTurns(settings, defaultWindmillMoveEveryNTurns)
+	if err != nil {
+		return err
+	}
+	placeFoodAvoidingMovingHazards(lastBoardState, settings, editor, m.hazards(lastBoardState.Width, lastBoardState.Height, every)...)
+	return nil
+}
@@ -0,0 +1,185 @@
+package maps
+
+import (
+	"github.com/Pikle2/rules"
+)
+
+// MovingHazard is a hazard structure that moves over time, such as a conveyor belt or a rotating wall.
+// Its position only depends on the turn, so maps don't need to store any state to move it.
+type MovingHazard interface {
+	// Points returns the squares covered by the hazard on a turn, on a board of the given size.
+	// Squares off the board are allowed and are ignored by PlaceMovingHazards.
+	Points(turn, width, height int) []rules.Point
+}
+
+// TranslatingHazard moves a shape by Step every Every turns.
+// With Wrap, squares that leave one edge of the board come back on the opposite edge.
+type TranslatingHazard struct {
+	Shape []rules.Point
+	Step  rules.Point
+	Every int
+	Wrap  bool
+}
+
+func (h TranslatingHazard) Points(turn, width, height int) []rules.Point {
+	n := movesByTurn(turn, h.Every)
+	points := make([]rules.Point, 0, len(h.Shape))
+	for _, p := range h.Shape {
+		moved := rules.Point{X: p.X + n*h.Step.X, Y: p.Y + n*h.Step.Y}
+		if h.Wrap {
+			moved.X = wrapCoordinate(moved.X, width)
+			moved.Y = wrapCoordinate(moved.Y, height)
+		}
+		points = append(points, moved)
+	}
+	return points
+}
+
+// RotatingHazard turns a shape a quarter turn around Center every Every turns, clockwise unless Counterclockwise is set.
+// The shape is relative to Center.
+type RotatingHazard struct {
+	Shape            []rules.Point
+	Center           rules.Point
+	Every            int
+	Counterclockwise bool
+}
+
+func (h RotatingHazard) Points(turn, width, height int) []rules.Point {
+	quarters := movesByTurn(turn, h.Every) % 4
+	if h.Counterclockwise {
+		quarters = (4 - quarters) % 4
+	}
+	points := make([]rules.Point, 0, len(h.Shape))
+	for _, p := range h.Shape {
+		for i := 0; i < quarters; i++ {
+			p = rules.Point{X: p.Y, Y: -p.X}
+		}
+		points = append(points, rules.Point{X: h.Center.X + p.X, Y: h.Center.Y + p.Y})
+	}
+	return points
+}
+
+// PathHazard moves a shape along Path, one point every Every turns, starting Offset points along the path.
+// The shape is relative to the current point of the path. After the last point the shape goes back to the
+// first point, or with Bounce, turns around and follows the path backwards.
+type PathHazard struct {
+	Shape  []rules.Point
+	Path   []rules.Point
+	Every  int
+	Offset int
+	Bounce bool
+}
+
+func (h PathHazard) Points(turn, width, height int) []rules.Point {
+	if len(h.Path) == 0 {
+		return nil
+	}
+	index := h.Offset + movesByTurn(turn, h.Every)
+	if h.Bounce && len(h.Path) > 1 {
+		period := 2 * (len(h.Path) - 1)
+		index %= period
+		if index >= len(h.Path) {
+			index = period - index
+		}
+	} else {
+		index %= len(h.Path)
+	}
+	origin := h.Path[index]
+
+	points := make([]rules.Point, 0, len(h.Shape))
+	for _, p := range h.Shape {
+		points = append(points, rules.Point{X: origin.X + p.X, Y: origin.Y + p.Y})
+	}
+	return points
+}
+
+// movesByTurn returns how many times a hazard that moves every n turns has moved by a turn.
+func movesByTurn(turn, every int) int {
+	if every <= 0 {
+		return 0
+	}
+	return turn / every
+}
+
+func wrapCoordinate(value, size int) int {
+	if size <= 0 {
+		return value
+	}
+	return ((value % size) + size) % size
+}
+
+// movingHazardPoints returns the squares on the board covered by any of the hazards on a turn, without duplicates.
+func movingHazardPoints(turn, width, height int, hazards ...MovingHazard) []rules.Point {
+	var points []rules.Point
+	seen := map[rules.Point]bool{}
+	for _, hazard := range hazards {
+		for _, p := range hazard.Points(turn, width, height) {
+			if !seen[p] && isOnBoard(width, height, p.X, p.Y) {
+				seen[p] = true
+				points = append(points, p)
+			}
+		}
+	}
+	return points
+}
+
+// PlaceMovingHazards replaces the hazards on the board with the squares covered by the hazards on a turn.
+// Maps should call it from PreUpdateBoard, so that snakes see where the hazards are before they move.
+func PlaceMovingHazards(editor Editor, turn, width, height int, hazards ...MovingHazard) {
+	editor.ClearHazards()
+	for _, p := range movingHazardPoints(turn, width, height, hazards...) {
+		editor.AddHazard(p)
+	}
+}
+
+// removeFoodUnderHazards removes food that hazards were placed on top of, such as starting food under a moving hazard.
+func removeFoodUnderHazards(editor Editor) {
+	hazards := map[rules.Point]bool{}
+	for _, p := range editor.Hazards() {
+		hazards[p] = true
+	}
+	for _, p := range editor.Food() {
+		if hazards[p] {
+			editor.RemoveFood(p)
+		}
+	}
+}
+
+// placeFoodAvoidingMovingHazards spawns food like the standard map, but not where the hazards will be on the next turn.
+func placeFoodAvoidingMovingHazards(lastBoardState *rules.BoardState, settings rules.Settings, editor Editor, hazards ...MovingHazard) {
+	rand := settings.GetRand(lastBoardState.Turn)
+
+	foodNeeded := FoodNeeded(rand, settings, lastBoardState)
+	if foodNeeded == 0 {
+		return
+	}
+	upcoming := map[rules.Point]bool{}
+	for _, p := range movingHazardPoints(lastBoardState.Turn+1, lastBoardState.Width, lastBoardState.Height, hazards...) {
+		upcoming[p] = true
+	}
+	var positions []rules.Point
+	for _, p := range rules.GetUnoccupiedPoints(lastBoardState, false, true) {
+		if !upcoming[p] {
+			positions = append(positions, p)
+		}
+	}
+	placeFoodRandomlyAtPositions(rand, lastBoardState, editor, foodNeeded, positions)
+}
+
+// ringPath returns the squares around the edge of a rectangle, clockwise from its bottom left corner.
+func ringPath(minX, minY, maxX, maxY int) []rules.Point {
+	var path []rules.Point
+	for y := minY; y < maxY; y++ {
+		path = append(path, rules.Point{X: minX, Y: y})
+	}
+	for x := minX; x < maxX; x++ {
+		path = append(path, rules.Point{X: x, Y: maxY})
+	}
+	for y := maxY; y > minY; y-- {
+		path = append(path, rules.Point{X: maxX, Y: y})
+	}
+	for x := maxX; x > minX; x-- {
+		path = append(path, rules.Point{X: x, Y: minY})
+	}
+	return path
+}
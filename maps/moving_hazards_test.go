@@ -0,0 +1,132 @@
+package maps
+
+import (
+	"testing"
+
+	"github.com/Pikle2/rules"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranslatingHazard(t *testing.T) {
+	hazard := TranslatingHazard{Shape: []rules.Point{{X: 3, Y: 1}, {X: 4, Y: 1}}, Step: rules.Point{X: 1}, Every: 2}
+	require.Equal(t, []rules.Point{{X: 3, Y: 1}, {X: 4, Y: 1}}, hazard.Points(1, 5, 5))
+	require.Equal(t, []rules.Point{{X: 4, Y: 1}, {X: 5, Y: 1}}, hazard.Points(2, 5, 5))
+
+	// Wrapping brings squares back on the other side of the board, in both directions
+	hazard.Wrap = true
+	require.Equal(t, []rules.Point{{X: 4, Y: 1}, {X: 0, Y: 1}}, hazard.Points(2, 5, 5))
+	hazard.Step = rules.Point{X: -1, Y: -1}
+	require.Equal(t, []rules.Point{{X: 0, Y: 3}, {X: 1, Y: 3}}, hazard.Points(6, 5, 5))
+
+	// Hazards that don't move every few turns stay where they are
+	hazard.Every = 0
+	require.Equal(t, []rules.Point{{X: 3, Y: 1}, {X: 4, Y: 1}}, hazard.Points(6, 5, 5))
+}
+
+func TestRotatingHazard(t *testing.T) {
+	hazard := RotatingHazard{Shape: []rules.Point{{X: 0, Y: 0}, {X: 2, Y: 0}}, Center: rules.Point{X: 5, Y: 5}, Every: 3}
+	require.Equal(t, []rules.Point{{X: 5, Y: 5}, {X: 7, Y: 5}}, hazard.Points(2, 11, 11))
+	require.Equal(t, []rules.Point{{X: 5, Y: 5}, {X: 5, Y: 3}}, hazard.Points(3, 11, 11))
+	require.Equal(t, []rules.Point{{X: 5, Y: 5}, {X: 3, Y: 5}}, hazard.Points(6, 11, 11))
+	require.Equal(t, []rules.Point{{X: 5, Y: 5}, {X: 5, Y: 7}}, hazard.Points(9, 11, 11))
+	require.Equal(t, []rules.Point{{X: 5, Y: 5}, {X: 7, Y: 5}}, hazard.Points(12, 11, 11))
+
+	hazard.Counterclockwise = true
+	require.Equal(t, []rules.Point{{X: 5, Y: 5}, {X: 5, Y: 7}}, hazard.Points(3, 11, 11))
+}
+
+func TestPathHazard(t *testing.T) {
+	path := []rules.Point{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 2, Y: 0}}
+	hazard := PathHazard{Shape: []rules.Point{{X: 0, Y: 1}}, Path: path, Every: 1}
+
+	var cycle, bounce []rules.Point
+	for turn := 0; turn < 6; turn++ {
+		cycle = append(cycle, hazard.Points(turn, 5, 5)...)
+		bounce = append(bounce, PathHazard{Shape: hazard.Shape, Path: path, Every: 1, Bounce: true}.Points(turn, 5, 5)...)
+	}
+	require.Equal(t, []rules.Point{{X: 0, Y: 1}, {X: 1, Y: 1}, {X: 2, Y: 1}, {X: 0, Y: 1}, {X: 1, Y: 1}, {X: 2, Y: 1}}, cycle)
+	require.Equal(t, []rules.Point{{X: 0, Y: 1}, {X: 1, Y: 1}, {X: 2, Y: 1}, {X: 1, Y: 1}, {X: 0, Y: 1}, {X: 1, Y: 1}}, bounce)
+
+	hazard.Offset = 2
+	require.Equal(t, []rules.Point{{X: 2, Y: 1}}, hazard.Points(0, 5, 5))
+	require.Empty(t, PathHazard{Shape: hazard.Shape}.Points(0, 5, 5))
+}
+
+func TestPlaceMovingHazards(t *testing.T) {
+	boardState := rules.NewBoardState(5, 5).WithHazards([]rules.Point{{X: 4, Y: 4}})
+	editor := NewBoardStateEditor(boardState)
+
+	// Old hazards are replaced, overlapping squares are only added once, and squares off the board are dropped
+	PlaceMovingHazards(editor, 1, 5, 5,
+		TranslatingHazard{Shape: []rules.Point{{X: 0, Y: 0}, {X: 1, Y: 0}}, Step: rules.Point{X: 1}, Every: 1},
+		PathHazard{Shape: []rules.Point{{X: 0, Y: 0}, {X: 0, Y: -1}}, Path: []rules.Point{{X: 2, Y: 0}}},
+	)
+	require.Equal(t, []rules.Point{{X: 1, Y: 0}, {X: 2, Y: 0}}, boardState.Hazards)
+
+	require.Len(t, ringPath(1, 1, 3, 3), 8)
+	require.Equal(t, []rules.Point{{X: 1, Y: 1}, {X: 1, Y: 2}, {X: 1, Y: 3}, {X: 2, Y: 3}}, ringPath(1, 1, 3, 3)[:4])
+}
+
+func TestMovingHazardMaps(t *testing.T) {
+	for _, id := range []string{"hz_conveyors", "hz_windmill"} {
+		t.Run(id, func(t *testing.T) {
+			gameMap, err := GetMap(id)
+			require.NoError(t, err)
+			settings := rules.NewSettingsWithParams(rules.ParamMinimumFood, "3", ParamMoveEveryNTurns, "1").WithSeed(1)
+			boardState, err := SetupBoardWithMap(gameMap, settings, 11, 11, []string{"1", "2", "3", "4"})
+			require.NoError(t, err)
+			require.NotEmpty(t, boardState.Hazards)
+
+			heads := map[rules.Point]bool{}
+			for _, snake := range boardState.Snakes {
+				heads[snake.Body[0]] = true
+			}
+			for _, p := range boardState.Hazards {
+				require.False(t, heads[p], "snakes don't start on hazards")
+			}
+
+			// The hazards move before snakes make their move, and food never spawns on the hazards
+			previous := boardState.Hazards
+			for turn := 0; turn < 4; turn++ {
+				boardState.Turn = turn
+				boardState, err = PreUpdateBoard(gameMap, boardState, settings)
+				require.NoError(t, err)
+				if turn > 0 {
+					require.NotEqual(t, previous, boardState.Hazards)
+				}
+				previous = boardState.Hazards
+
+				boardState, err = PostUpdateBoard(gameMap, boardState, settings)
+				require.NoError(t, err)
+				hazards := map[rules.Point]bool{}
+				for _, p := range boardState.Hazards {
+					hazards[p] = true
+				}
+				for _, p := range boardState.Food {
+					require.False(t, hazards[p])
+				}
+			}
+
+			_, err = SetupBoardWithMap(gameMap, rules.NewSettingsWithParams(ParamMoveEveryNTurns, "0"), 11, 11, []string{"1"})
+			require.EqualError(t, err, "moveEveryNTurns must be at least 1")
+		})
+	}
+}
+
+func TestWindmillBladesAvoidStarts(t *testing.T) {
+	for _, size := range []int{11, 19} {
+		boardState := rules.NewBoardState(size, size)
+		require.NoError(t, rules.PlaceSnakesFixed(rules.MaxRand, boardState, []string{"1", "2", "3", "4", "5", "6", "7", "8"}))
+
+		blades := WindmillMap{}.hazards(size, size, 1)[0]
+		for turn := 0; turn < 4; turn++ {
+			for _, p := range blades.Points(turn, size, size) {
+				for _, snake := range boardState.Snakes {
+					start := snake.Body[0]
+					distance := maxInt(abs(p.X-start.X), abs(p.Y-start.Y))
+					require.Greater(t, distance, 2, "%dx%d turn %d: blade square %v is within 2 squares of start %v", size, size, turn, p, start)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package maps
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Pikle2/rules"
+)
+
+// Types of map parameters.
+const (
+	ParamTypeInt    = "int"
+	ParamTypeBool   = "bool"
+	ParamTypeString = "string"
+)
+
+// Parameter describes a game setting that a map reads, so that players can discover and set it.
+type Parameter struct {
+	// Name is the settings key, e.g. rules.ParamShrinkEveryNTurns.
+	Name string `json:"name" yaml:"name"`
+	// Type is one of ParamTypeInt, ParamTypeBool or ParamTypeString.
+	Type string `json:"type" yaml:"type"`
+	// Default is the value the map uses when the setting isn't provided.
+	// It is empty when the map has no default and the setting must be provided.
+	Default string `json:"default" yaml:"default"`
+	// Description explains what the parameter does in this map.
+	Description string `json:"description" yaml:"description"`
+}
+
+// Check reports whether the parameter declaration itself is well formed.
+func (p Parameter) Check() error {
+	if p.Name == "" {
+		return rules.RulesetError("map parameter has no name")
+	}
+	switch p.Type {
+	case ParamTypeInt, ParamTypeBool, ParamTypeString:
+	default:
+		return fmt.Errorf("map parameter %s has unknown type %q", p.Name, p.Type)
+	}
+	if p.Default != "" {
+		if err := p.Validate(p.Default); err != nil {
+			return fmt.Errorf("default of %w", err)
+		}
+	}
+	return nil
+}
+
+// Validate checks that a value can be used for this parameter.
+func (p Parameter) Validate(value string) error {
+	switch p.Type {
+	case ParamTypeInt:
+		if _, err := strconv.Atoi(value); err != nil {
+			return fmt.Errorf("map parameter %s must be an int, got %q", p.Name, value)
+		}
+	case ParamTypeBool:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("map parameter %s must be a bool, got %q", p.Name, value)
+		}
+	}
+	return nil
+}
+
+// Parameter returns the declared parameter with the given name.
+func (meta Metadata) Parameter(name string) (Parameter, bool) {
+	for _, p := range meta.Parameters {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Parameter{}, false
+}
+
+// ValidateParams checks that every param is declared by the map and has a valid value.
+func (meta Metadata) ValidateParams(params map[string]string) error {
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		p, ok := meta.Parameter(name)
+		if !ok {
+			return fmt.Errorf("unknown map parameter %s, %s", name, meta.describeParameterNames())
+		}
+		if err := p.Validate(params[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (meta Metadata) describeParameterNames() string {
+	if len(meta.Parameters) == 0 {
+		return "this map has no parameters"
+	}
+	names := make([]string, 0, len(meta.Parameters))
+	for _, p := range meta.Parameters {
+		names = append(names, p.Name)
+	}
+	return "expected one of: " + strings.Join(names, ", ")
+}
+
+// checkParameters checks a list of parameter declarations, and that there are no duplicates.
+func checkParameters(params []Parameter) error {
+	seen := make(map[string]bool, len(params))
+	for _, p := range params {
+		if err := p.Check(); err != nil {
+			return err
+		}
+		if seen[p.Name] {
+			return fmt.Errorf("map parameter %s is declared more than once", p.Name)
+		}
+		seen[p.Name] = true
+	}
+	return nil
+}
+
+// hazardDamageParameter is declared by maps that place hazards, since the hazard damage decides how dangerous they are.
+var hazardDamageParameter = Parameter{
+	Name:        rules.ParamHazardDamagePerTurn,
+	Type:        ParamTypeInt,
+	Default:     "0",
+	Description: "Health lost by a snake for each turn it ends on a hazard square",
+}
+
+var foodSpawnerParameter = Parameter{
+	Name:        ParamFoodSpawner,
+	Type:        ParamTypeString,
+	Default:     FoodSpawnerUniform,
+	Description: fmt.Sprintf("Where new food spawns: %s, %s, %s, %s or %s", FoodSpawnerUniform, FoodSpawnerDistanceFair, FoodSpawnerSymmetric, FoodSpawnerMirrored, FoodSpawnerClustered),
+}
@@ -0,0 +1,71 @@
+package maps
+
+import (
+	"testing"
+
+	"github.com/Pikle2/rules"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParameterCheck(t *testing.T) {
+	require.NoError(t, Parameter{Name: "speed", Type: ParamTypeInt, Default: "3"}.Check())
+	require.NoError(t, Parameter{Name: "label", Type: ParamTypeString}.Check())
+	require.EqualError(t, Parameter{Type: ParamTypeInt}.Check(), "map parameter has no name")
+	require.EqualError(t, Parameter{Name: "speed", Type: "float"}.Check(), `map parameter speed has unknown type "float"`)
+	require.EqualError(t, Parameter{Name: "fast", Type: ParamTypeBool, Default: "yes"}.Check(), `default of map parameter fast must be a bool, got "yes"`)
+
+	require.EqualError(t, checkParameters([]Parameter{
+		{Name: "speed", Type: ParamTypeInt},
+		{Name: "speed", Type: ParamTypeInt},
+	}), "map parameter speed is declared more than once")
+}
+
+func TestMetadataValidateParams(t *testing.T) {
+	meta := Metadata{Parameters: []Parameter{
+		{Name: "speed", Type: ParamTypeInt},
+		{Name: "fast", Type: ParamTypeBool},
+		{Name: "label", Type: ParamTypeString},
+	}}
+
+	require.NoError(t, meta.ValidateParams(nil))
+	require.NoError(t, meta.ValidateParams(map[string]string{"speed": "-2", "fast": "true", "label": ""}))
+	require.EqualError(t, meta.ValidateParams(map[string]string{"speed": "fast"}), `map parameter speed must be an int, got "fast"`)
+	require.EqualError(t, meta.ValidateParams(map[string]string{"fast": "2"}), `map parameter fast must be a bool, got "2"`)
+	require.EqualError(t, meta.ValidateParams(map[string]string{"size": "1"}), "unknown map parameter size, expected one of: speed, fast, label")
+	require.EqualError(t, Metadata{}.ValidateParams(map[string]string{"size": "1"}), "unknown map parameter size, this map has no parameters")
+
+	p, ok := meta.Parameter("fast")
+	require.True(t, ok)
+	require.Equal(t, ParamTypeBool, p.Type)
+	_, ok = meta.Parameter("slow")
+	require.False(t, ok)
+}
+
+func TestFileMapParameters(t *testing.T) {
+	gameMap, err := ParseMapFile([]byte(`
+id: params
+meta:
+  parameters:
+    - {name: pulse, type: int, default: "4", description: Turns between pulses}
+layouts:
+  - size: 7x7
+    walls: [[3, 3]]
+`), "yaml")
+	require.NoError(t, err)
+
+	// Walls add a hazard damage parameter, since they can't be placed without it,
+	// and layouts without snake starts add the snake placement parameters
+	params := gameMap.Meta().Parameters
+	require.Len(t, params, 4)
+	require.Equal(t, Parameter{Name: "pulse", Type: ParamTypeInt, Default: "4", Description: "Turns between pulses"}, params[0])
+	require.Equal(t, rules.ParamHazardDamagePerTurn, params[1].Name)
+	require.Empty(t, params[1].Default)
+	require.Equal(t, []Parameter{snakePlacementParameter, startBodyParameter}, params[2:])
+
+	gameMap, err = ParseMapFile([]byte(`{"id": "params", "layouts": [{"size": "7x7", "snakeStarts": [[1, 1], [5, 5]]}]}`), "json")
+	require.NoError(t, err)
+	require.Empty(t, gameMap.Meta().Parameters)
+
+	_, err = ParseMapFile([]byte(`{"id": "params", "meta": {"parameters": [{"name": "pulse", "type": "float"}]}, "layouts": [{"size": "7x7"}]}`), "json")
+	require.EqualError(t, err, `map params: map parameter pulse has unknown type "float"`)
+}
@@ -0,0 +1,361 @@
+package maps
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Pikle2/rules"
+)
+
+// Map plugins are GameMaps implemented by another process, such as a Python script, so that
+// maps can be changed without rebuilding the CLI.
+//
+// The plugin reads requests from stdin and writes a response to stdout for each one, with one
+// JSON object per line. Anything written to stderr is passed through, so it can be used for logging.
+//
+// The first request is always "meta":
+//
+//	{"method": "meta"}
+//	{"id": "my_plugin", "meta": {"name": "My Plugin", "version": 1, "maxPlayers": 4, "boardSizes": ["11x11"]}}
+//
+// The meta fields are the same as in map files, plus "boardSizes" which lists the supported sizes
+// ("WxH"), or is empty if any size is supported. The following requests mirror the GameMap methods:
+//
+//	{"method": "setup", "boardState": {...}, "settings": {"params": {"minimumFood": "1"}, "seed": 1234}}
+//	{"method": "preUpdate", "boardState": {...}, "settings": {...}}
+//	{"method": "postUpdate", "boardState": {...}, "settings": {...}}
+//
+// where "boardState" uses the BoardState JSON encoding. The plugin responds with the changes
+// to make to the board, as a list of Editor operations:
+//
+//	{"operations": [{"op": "addFood", "point": {"X": 1, "Y": 2}}, {"op": "placeSnake", "id": "snake", "body": [...], "health": 100}]}
+//
+// or with an error, which stops the game:
+//
+//	{"error": "this map can't be played with 9 snakes"}
+//
+// The plugin should exit when stdin is closed. Plugins that don't respond within PluginTimeout are stopped.
+
+const (
+	PluginOpClearFood        = "clearFood"        // remove all food
+	PluginOpAddFood          = "addFood"          // add food at "point"
+	PluginOpRemoveFood       = "removeFood"       // remove all food at "point"
+	PluginOpClearHazards     = "clearHazards"     // remove all hazards
+	PluginOpAddHazard        = "addHazard"        // add a hazard at "point"
+	PluginOpRemoveHazard     = "removeHazard"     // remove all hazards at "point"
+	PluginOpSetHazardOwner   = "setHazardOwner"   // set the owner of the hazards at "point" to "id", or remove it if "id" is empty
+	PluginOpPlaceSnake       = "placeSnake"       // set the "body" and "health" of the snake with "id", health defaults to rules.SnakeMaxHealth
+	PluginOpSetGameState     = "setGameState"     // set GameState "key" to "value"
+	PluginOpDeleteGameState  = "deleteGameState"  // delete GameState "key"
+	PluginOpSetPointState    = "setPointState"    // set PointState at "point" to "state"
+	PluginOpDeletePointState = "deletePointState" // delete PointState at "point"
+)
+
+// PluginRequest is a request sent to a map plugin.
+type PluginRequest struct {
+	Method     string            `json:"method"`
+	BoardState *rules.BoardState `json:"boardState,omitempty"`
+	Settings   *PluginSettings   `json:"settings,omitempty"`
+}
+
+// PluginSettings are the game settings sent to a map plugin.
+// Plugins should seed their random number generator with Seed plus the board's turn, to make games reproducible.
+type PluginSettings struct {
+	Params map[string]string `json:"params"`
+	Seed   int64             `json:"seed"`
+}
+
+// PluginResponse is a response from a map plugin.
+type PluginResponse struct {
+	ID         string            `json:"id,omitempty"`
+	Meta       *PluginMeta       `json:"meta,omitempty"`
+	Operations []PluginOperation `json:"operations,omitempty"`
+	Error      string            `json:"error,omitempty"`
+}
+
+// PluginMeta is the metadata of a map plugin.
+type PluginMeta struct {
+	MapFileMeta
+	BoardSizes []string `json:"boardSizes"`
+}
+
+// PluginOperation is a change to the board made by a map plugin, corresponding to an Editor method.
+type PluginOperation struct {
+	Op     string        `json:"op"`
+	Point  *rules.Point  `json:"point,omitempty"`
+	ID     string        `json:"id,omitempty"`
+	Body   []rules.Point `json:"body,omitempty"`
+	Health *int          `json:"health,omitempty"`
+	Key    string        `json:"key,omitempty"`
+	Value  string        `json:"value,omitempty"`
+	State  int           `json:"state,omitempty"`
+}
+
+// PluginMap is a GameMap that runs a map plugin process.
+// Requests to the plugin are made one at a time. Call Close when the map is no longer needed.
+type PluginMap struct {
+	id   string
+	meta Metadata
+
+	mu     sync.Mutex
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+	err    error
+}
+
+// NewPluginMap starts a map plugin process and asks it for its metadata.
+func NewPluginMap(command string, args ...string) (*PluginMap, error) {
+	cmd := exec.Command(command, args...)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start map plugin: %w", err)
+	}
+
+	m := &PluginMap{
+		id:     command,
+		cmd:    cmd,
+		stdin:  stdin,
+		stdout: bufio.NewReader(stdout),
+	}
+	if err := m.loadMeta(); err != nil {
+		m.Close()
+		return nil, err
+	}
+	return m, nil
+}
+
+func (m *PluginMap) loadMeta() error {
+	response, err := m.call(PluginRequest{Method: "meta"})
+	if err != nil {
+		return err
+	}
+	if response.ID == "" || response.Meta == nil {
+		return fmt.Errorf("map plugin %s: meta response must have an id and meta", m.id)
+	}
+	m.id = response.ID
+
+	meta := response.Meta
+	m.meta = Metadata{
+		Name:        meta.Name,
+		Author:      meta.Author,
+		Description: meta.Description,
+		Version:     meta.Version,
+		MinPlayers:  meta.MinPlayers,
+		MaxPlayers:  meta.MaxPlayers,
+		BoardSizes:  AnySize(),
+		Tags:        append([]string{}, meta.Tags...),
+		Parameters:  append([]Parameter{}, meta.Parameters...),
+	}
+	if err := checkParameters(meta.Parameters); err != nil {
+		return fmt.Errorf("map plugin %s: %w", m.id, err)
+	}
+	if len(meta.BoardSizes) > 0 {
+		m.meta.BoardSizes = nil
+		for _, s := range meta.BoardSizes {
+			size, err := ParseDimensions(s)
+			if err != nil {
+				return fmt.Errorf("map plugin %s: %w", m.id, err)
+			}
+			m.meta.BoardSizes = append(m.meta.BoardSizes, size)
+		}
+	}
+	return nil
+}
+
+// Close stops the plugin process by closing its stdin, and waits for it to exit.
+func (m *PluginMap) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err == errPluginClosed {
+		return nil
+	}
+	m.err = errPluginClosed
+	m.stdin.Close()
+	return m.cmd.Wait()
+}
+
+var errPluginClosed = rules.RulesetError("map plugin has been closed")
+
+// PluginTimeout is how long a map plugin has to respond to each request before it is stopped.
+var PluginTimeout = 10 * time.Second
+
+type pluginReadResult struct {
+	line []byte
+	err  error
+}
+
+// call sends a request to the plugin and waits for its response.
+// If the plugin can't be talked to any more, all later calls return the same error.
+func (m *PluginMap) call(request PluginRequest) (PluginResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var response PluginResponse
+	if m.err != nil {
+		return response, m.err
+	}
+
+	data, err := json.Marshal(request)
+	if err != nil {
+		return response, err
+	}
+	if _, err := m.stdin.Write(append(data, '\n')); err != nil {
+		m.err = fmt.Errorf("map plugin %s: failed to send %s request: %w", m.id, request.Method, err)
+		return response, m.err
+	}
+
+	// Read in the background, so that a plugin that never responds can't block the game
+	results := make(chan pluginReadResult, 1)
+	go func() {
+		line, err := m.stdout.ReadBytes('\n')
+		results <- pluginReadResult{line: line, err: err}
+	}()
+	var line []byte
+	select {
+	case result := <-results:
+		if result.err != nil {
+			m.err = fmt.Errorf("map plugin %s: failed to read %s response: %w", m.id, request.Method, result.err)
+			return response, m.err
+		}
+		line = result.line
+	case <-time.After(PluginTimeout):
+		m.cmd.Process.Kill()
+		m.err = fmt.Errorf("map plugin %s: timed out after %s waiting for %s response", m.id, PluginTimeout, request.Method)
+		return response, m.err
+	}
+	if err := json.Unmarshal(line, &response); err != nil {
+		m.err = fmt.Errorf("map plugin %s: invalid %s response: %w", m.id, request.Method, err)
+		return response, m.err
+	}
+	if response.Error != "" {
+		return response, fmt.Errorf("map plugin %s: %s", m.id, response.Error)
+	}
+	return response, nil
+}
+
+func (m *PluginMap) ID() string {
+	return m.id
+}
+
+func (m *PluginMap) Meta() Metadata {
+	return m.meta
+}
+
+func (m *PluginMap) SetupBoard(initialBoardState *rules.BoardState, settings rules.Settings, editor Editor) error {
+	if err := m.Meta().Validate(initialBoardState); err != nil {
+		return err
+	}
+	return m.update("setup", initialBoardState, settings, editor)
+}
+
+func (m *PluginMap) PreUpdateBoard(lastBoardState *rules.BoardState, settings rules.Settings, editor Editor) error {
+	return m.update("preUpdate", lastBoardState, settings, editor)
+}
+
+func (m *PluginMap) PostUpdateBoard(lastBoardState *rules.BoardState, settings rules.Settings, editor Editor) error {
+	return m.update("postUpdate", lastBoardState, settings, editor)
+}
+
+func (m *PluginMap) update(method string, boardState *rules.BoardState, settings rules.Settings, editor Editor) error {
+	response, err := m.call(PluginRequest{
+		Method:     method,
+		BoardState: boardState,
+		Settings: &PluginSettings{
+			Params: settings.Params(),
+			Seed:   settings.Seed(),
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if err := applyPluginOperations(editor, response.Operations); err != nil {
+		return fmt.Errorf("map plugin %s: %s response: %w", m.id, method, err)
+	}
+	return nil
+}
+
+// applyPluginOperations makes the changes returned by a plugin. All operations are checked before any are applied.
+func applyPluginOperations(editor Editor, operations []PluginOperation) error {
+	for i, op := range operations {
+		var missing []string
+		switch op.Op {
+		case PluginOpClearFood, PluginOpClearHazards:
+		case PluginOpAddFood, PluginOpRemoveFood, PluginOpAddHazard, PluginOpRemoveHazard, PluginOpSetHazardOwner, PluginOpDeletePointState, PluginOpSetPointState:
+			if op.Point == nil {
+				missing = append(missing, "point")
+			}
+			if _, ok := editor.(HazardOwnerEditor); op.Op == PluginOpSetHazardOwner && !ok {
+				return fmt.Errorf("operations[%d]: %w", i, errorEditorUnsupported("hazard owners"))
+			}
+		case PluginOpPlaceSnake:
+			if op.ID == "" {
+				missing = append(missing, "id")
+			}
+			if len(op.Body) == 0 {
+				missing = append(missing, "body")
+			}
+			if op.Health != nil && (*op.Health < 0 || *op.Health > rules.SnakeMaxHealth) {
+				return fmt.Errorf("operations[%d]: %s health must be between 0 and %d, got %d", i, op.Op, rules.SnakeMaxHealth, *op.Health)
+			}
+		case PluginOpSetGameState, PluginOpDeleteGameState:
+			if op.Key == "" {
+				missing = append(missing, "key")
+			}
+		default:
+			return fmt.Errorf("operations[%d]: unknown op %q", i, op.Op)
+		}
+		if len(missing) > 0 {
+			return fmt.Errorf("operations[%d]: %s needs %s", i, op.Op, strings.Join(missing, " and "))
+		}
+	}
+
+	for _, op := range operations {
+		switch op.Op {
+		case PluginOpClearFood:
+			editor.ClearFood()
+		case PluginOpAddFood:
+			editor.AddFood(*op.Point)
+		case PluginOpRemoveFood:
+			editor.RemoveFood(*op.Point)
+		case PluginOpClearHazards:
+			editor.ClearHazards()
+		case PluginOpAddHazard:
+			editor.AddHazard(*op.Point)
+		case PluginOpRemoveHazard:
+			editor.RemoveHazard(*op.Point)
+		case PluginOpSetHazardOwner:
+			editor.(HazardOwnerEditor).SetHazardOwner(*op.Point, op.ID)
+		case PluginOpPlaceSnake:
+			health := rules.SnakeMaxHealth
+			if op.Health != nil {
+				health = *op.Health
+			}
+			editor.PlaceSnake(op.ID, op.Body, health)
+		case PluginOpSetGameState:
+			editor.GameState()[op.Key] = op.Value
+		case PluginOpDeleteGameState:
+			delete(editor.GameState(), op.Key)
+		case PluginOpSetPointState:
+			editor.PointState()[*op.Point] = op.State
+		case PluginOpDeletePointState:
+			delete(editor.PointState(), *op.Point)
+		}
+	}
+	return nil
+}
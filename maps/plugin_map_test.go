@@ -0,0 +1,185 @@
+package maps_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Pikle2/rules"
+	"github.com/Pikle2/rules/maps"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPluginHelperProcess isn't a real test. It's a map plugin run by the other tests,
+// following the approach used by os/exec's tests.
+func TestPluginHelperProcess(t *testing.T) {
+	mode := os.Getenv("MAP_PLUGIN_HELPER")
+	if mode == "" {
+		return
+	}
+	defer os.Exit(0)
+
+	scanner := bufio.NewScanner(os.Stdin)
+	encoder := json.NewEncoder(os.Stdout)
+	for scanner.Scan() {
+		var request maps.PluginRequest
+		if err := json.Unmarshal(scanner.Bytes(), &request); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		var response maps.PluginResponse
+		switch {
+		case mode == "garbage":
+			fmt.Println("not json")
+			continue
+		case mode == "crash":
+			os.Exit(3)
+		case mode == "hang":
+			time.Sleep(time.Minute)
+		case request.Method == "meta":
+			response.ID = "helper"
+			response.Meta = &maps.PluginMeta{BoardSizes: []string{"7x7"}}
+			response.Meta.Name = "Helper"
+			response.Meta.Version = 1
+			response.Meta.MaxPlayers = 2
+		case request.Method == "setup":
+			for i, snake := range request.BoardState.Snakes {
+				head := rules.Point{X: 1 + 4*i, Y: 1 + 4*i}
+				op := maps.PluginOperation{
+					Op:   maps.PluginOpPlaceSnake,
+					ID:   snake.ID,
+					Body: []rules.Point{head, head, head},
+				}
+				// The second snake leaves out its health, which defaults to full health
+				if i == 0 {
+					health := 90
+					op.Health = &health
+				}
+				response.Operations = append(response.Operations, op)
+			}
+			response.Operations = append(response.Operations,
+				maps.PluginOperation{Op: maps.PluginOpAddHazard, Point: &rules.Point{X: 3, Y: 3}},
+				maps.PluginOperation{Op: maps.PluginOpSetGameState, Key: "seed", Value: fmt.Sprint(request.Settings.Seed)},
+				maps.PluginOperation{Op: maps.PluginOpSetPointState, Point: &rules.Point{X: 3, Y: 3}, State: 7},
+			)
+		case request.Method == "preUpdate":
+		case request.Method == "postUpdate":
+			if request.BoardState.Turn == 3 {
+				response.Error = "turn 3 is unlucky"
+				break
+			}
+			if request.Settings.Params[rules.ParamMinimumFood] == "bad" {
+				response.Operations = []maps.PluginOperation{{Op: maps.PluginOpAddFood}}
+				break
+			}
+			if request.Settings.Params[rules.ParamMinimumFood] == "overfed" {
+				health := rules.SnakeMaxHealth + 1
+				response.Operations = []maps.PluginOperation{
+					{Op: maps.PluginOpAddFood, Point: &rules.Point{X: 0, Y: 0}},
+					{Op: maps.PluginOpPlaceSnake, ID: "one", Body: []rules.Point{{X: 1, Y: 1}}, Health: &health},
+				}
+				break
+			}
+			response.Operations = []maps.PluginOperation{
+				{Op: maps.PluginOpClearFood},
+				{Op: maps.PluginOpAddFood, Point: &rules.Point{X: request.BoardState.Turn, Y: 0}},
+				{Op: maps.PluginOpDeletePointState, Point: &rules.Point{X: 3, Y: 3}},
+			}
+		default:
+			response.Error = "unknown method " + request.Method
+		}
+		if err := encoder.Encode(response); err != nil {
+			os.Exit(2)
+		}
+	}
+}
+
+func startHelperPlugin(t *testing.T, mode string) (*maps.PluginMap, error) {
+	t.Setenv("MAP_PLUGIN_HELPER", mode)
+	m, err := maps.NewPluginMap(os.Args[0], "-test.run=TestPluginHelperProcess")
+	if err == nil {
+		t.Cleanup(func() { m.Close() })
+	}
+	return m, err
+}
+
+func TestPluginMap(t *testing.T) {
+	m, err := startHelperPlugin(t, "map")
+	require.NoError(t, err)
+
+	require.Equal(t, "helper", m.ID())
+	meta := m.Meta()
+	require.Equal(t, "Helper", meta.Name)
+	require.Equal(t, 2, meta.MaxPlayers)
+	require.Equal(t, maps.FixedSizes(maps.Dimensions{Width: 7, Height: 7}), meta.BoardSizes)
+
+	settings := rules.NewSettingsWithParams(rules.ParamMinimumFood, "1").WithSeed(42)
+	initialBoardState := rules.NewBoardState(7, 7)
+	initialBoardState.Snakes = []rules.Snake{{ID: "one"}, {ID: "two"}}
+	boardState := initialBoardState.Clone()
+	require.NoError(t, m.SetupBoard(initialBoardState, settings, maps.NewBoardStateEditor(boardState)))
+
+	require.Equal(t, []rules.Point{{X: 1, Y: 1}, {X: 1, Y: 1}, {X: 1, Y: 1}}, boardState.Snakes[0].Body)
+	require.Equal(t, []rules.Point{{X: 5, Y: 5}, {X: 5, Y: 5}, {X: 5, Y: 5}}, boardState.Snakes[1].Body)
+	require.Equal(t, 90, boardState.Snakes[0].Health)
+	require.Equal(t, rules.SnakeMaxHealth, boardState.Snakes[1].Health, "omitted health should default to full health")
+	require.Equal(t, []rules.Point{{X: 3, Y: 3}}, boardState.Hazards)
+	require.Equal(t, map[string]string{"seed": "42"}, boardState.GameState)
+	require.Equal(t, map[rules.Point]int{{X: 3, Y: 3}: 7}, boardState.PointState)
+
+	require.NoError(t, m.PreUpdateBoard(boardState, settings, maps.NewBoardStateEditor(boardState.Clone())))
+
+	boardState.Turn = 2
+	next := boardState.Clone()
+	require.NoError(t, m.PostUpdateBoard(boardState, settings, maps.NewBoardStateEditor(next)))
+	require.Equal(t, []rules.Point{{X: 2, Y: 0}}, next.Food)
+	require.Empty(t, next.PointState)
+
+	// Errors returned by the plugin are passed on, and the plugin can keep going
+	boardState.Turn = 3
+	err = m.PostUpdateBoard(boardState, settings, maps.NewBoardStateEditor(boardState.Clone()))
+	require.EqualError(t, err, "map plugin helper: turn 3 is unlucky")
+
+	// Invalid operations aren't applied
+	next = boardState.Clone()
+	next.Turn = 4
+	err = m.PostUpdateBoard(next, settings.WithParam(rules.ParamMinimumFood, "bad"), maps.NewBoardStateEditor(next))
+	require.EqualError(t, err, "map plugin helper: postUpdate response: operations[0]: addFood needs point")
+	require.Empty(t, next.Food)
+	err = m.PostUpdateBoard(next, settings.WithParam(rules.ParamMinimumFood, "overfed"), maps.NewBoardStateEditor(next))
+	require.EqualError(t, err, "map plugin helper: postUpdate response: operations[1]: placeSnake health must be between 0 and 100, got 101")
+	require.Empty(t, next.Food)
+	require.Equal(t, []rules.Point{{X: 1, Y: 1}, {X: 1, Y: 1}, {X: 1, Y: 1}}, next.Snakes[0].Body)
+
+	// Unsupported sizes are checked before calling the plugin
+	err = m.SetupBoard(rules.NewBoardState(9, 9), settings, maps.NewBoardStateEditor(rules.NewBoardState(9, 9)))
+	require.EqualError(t, err, "This map can only be played on these board sizes: 7x7")
+
+	require.NoError(t, m.Close())
+	require.NoError(t, m.Close())
+	err = m.PreUpdateBoard(boardState, settings, maps.NewBoardStateEditor(boardState.Clone()))
+	require.EqualError(t, err, "map plugin has been closed")
+}
+
+func TestPluginMapErrors(t *testing.T) {
+	_, err := startHelperPlugin(t, "garbage")
+	require.ErrorContains(t, err, "invalid meta response")
+
+	_, err = startHelperPlugin(t, "crash")
+	require.ErrorContains(t, err, "failed to read meta response: EOF")
+
+	timeout := maps.PluginTimeout
+	maps.PluginTimeout = 100 * time.Millisecond
+	defer func() { maps.PluginTimeout = timeout }()
+	start := time.Now()
+	_, err = startHelperPlugin(t, "hang")
+	require.EqualError(t, err, "map plugin "+os.Args[0]+": timed out after 100ms waiting for meta response")
+	require.Less(t, time.Since(start), 10*time.Second)
+
+	_, err = maps.NewPluginMap("./testdata/does-not-exist")
+	require.ErrorContains(t, err, "failed to start map plugin")
+}
@@ -3,58 +3,227 @@ package maps
 import (
 	"fmt"
 	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/Pikle2/rules"
 )
 
 // MapRegistry is a mapping of map names to game maps.
+// Earlier versions of a map are kept under versioned IDs, e.g. "standard@1", so that old games can be reproduced.
 type MapRegistry map[string]GameMap
 
+// VersionSeparator joins a map ID and a map version into a versioned map ID, e.g. "standard@1".
+const VersionSeparator = "@"
+
+// VersionedID returns the ID that the given version of a map is registered under.
+func VersionedID(id string, version int) string {
+	return fmt.Sprintf("%s%s%d", id, VersionSeparator, version)
+}
+
+// ParseVersionedID splits a versioned map ID into the map ID and version.
+// IDs without a version return a version of 0.
+func ParseVersionedID(versionedID string) (string, int, error) {
+	id, versionString, ok := strings.Cut(versionedID, VersionSeparator)
+	if !ok {
+		return versionedID, 0, nil
+	}
+	version, err := strconv.Atoi(versionString)
+	if err != nil || version < 1 {
+		return "", 0, rules.RulesetError(fmt.Sprintf("invalid map version '%s' in map ID '%s'", versionString, versionedID))
+	}
+	return id, version, nil
+}
+
 var globalRegistry = MapRegistry{}
 
 // RegisterMap adds a stage to the registry.
 // If a map has already been registered this will panic.
 func (registry MapRegistry) RegisterMap(id string, m GameMap) {
+	if err := registry.RegisterMapError(id, m); err != nil {
+		panic(err.Error())
+	}
+}
+
+// RegisterMapError adds a map to the registry.
+// If a map has already been registered an error will be returned.
+// This is useful for maps that are loaded at runtime, e.g. from files.
+func (registry MapRegistry) RegisterMapError(id string, m GameMap) error {
 	if _, ok := registry[id]; ok {
-		panic(fmt.Sprintf("map '%s' has already been registered", id))
+		return rules.RulesetError(fmt.Sprintf("map '%s' has already been registered", id))
 	}
 
 	registry[id] = m
+	return nil
+}
+
+// RegisterMapVersion adds an earlier version of a map to the registry, under the versioned ID for its Meta().Version.
+// The latest version of the map should be registered with RegisterMap.
+// If the version has already been registered this will panic.
+func (registry MapRegistry) RegisterMapVersion(id string, m GameMap) {
+	if err := registry.RegisterMapError(VersionedID(id, m.Meta().Version), m); err != nil {
+		panic(err.Error())
+	}
 }
 
-// List returns all registered map IDs in alphabetical order
+// List returns all registered map IDs in alphabetical order.
+// Earlier versions of maps are not included, see Versions.
 func (registry MapRegistry) List() []string {
 	var keys []string
 	for k := range registry {
+		if strings.Contains(k, VersionSeparator) {
+			continue
+		}
 		keys = append(keys, k)
 	}
 	sort.Strings(keys)
 	return keys
 }
 
+// MapQuery selects maps by their metadata, e.g. the maps that can be played by 4 snakes on a 19x19 board.
+// Fields with zero values match every map.
+type MapQuery struct {
+	// Tags lists tags that a map must all have, such as TAG_HAZARD_PLACEMENT.
+	Tags []string
+	// Players is a number of snakes the map must support.
+	Players int
+	// Width and Height are a board size the map must support.
+	Width  int
+	Height int
+	// IncludeExperimental includes maps tagged TAG_EXPERIMENTAL, which are left out unless the tag is queried.
+	IncludeExperimental bool
+}
+
+// Matches reports whether a map with the given metadata is selected by the query.
+func (query MapQuery) Matches(meta Metadata) bool {
+	hasTag := make(map[string]bool, len(meta.Tags))
+	for _, tag := range meta.Tags {
+		hasTag[tag] = true
+	}
+	for _, tag := range query.Tags {
+		if !hasTag[tag] {
+			return false
+		}
+	}
+	if hasTag[TAG_EXPERIMENTAL] && !query.IncludeExperimental && !query.hasTag(TAG_EXPERIMENTAL) {
+		return false
+	}
+	if query.Players > 0 && (query.Players < meta.MinPlayers || (meta.MaxPlayers > 0 && query.Players > meta.MaxPlayers)) {
+		return false
+	}
+	if (query.Width > 0 || query.Height > 0) && !meta.BoardSizes.IsAllowable(query.Width, query.Height) {
+		return false
+	}
+	return true
+}
+
+func (query MapQuery) hasTag(tag string) bool {
+	for _, t := range query.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Query returns the IDs of the maps selected by the query in alphabetical order.
+func (registry MapRegistry) Query(query MapQuery) []string {
+	var ids []string
+	for _, id := range registry.List() {
+		if query.Matches(registry[id].Meta()) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// Versions returns the registered versions of the map with the given ID in ascending order.
+func (registry MapRegistry) Versions(id string) []int {
+	var versions []int
+	for k, m := range registry {
+		if k == id || strings.HasPrefix(k, id+VersionSeparator) {
+			versions = append(versions, m.Meta().Version)
+		}
+	}
+	sort.Ints(versions)
+	return versions
+}
+
 // GetMap returns the map associated with the given ID.
+// IDs that join registered maps with LayerSeparator return a LayeredMap, e.g. "standard+hz_spiral+snail_mode".
+// IDs with a version, e.g. "standard@1", return that version of the map, and the layers of a layered map
+// can each have a version, e.g. "standard@1+snail_mode@1".
 func (registry MapRegistry) GetMap(id string) (GameMap, error) {
 	if m, ok := registry[id]; ok {
 		return m, nil
 	}
+	if strings.Contains(id, LayerSeparator) {
+		return registry.NewLayeredMapFromID(id)
+	}
+	if strings.Contains(id, VersionSeparator) {
+		mapID, version, err := ParseVersionedID(id)
+		if err != nil {
+			return nil, err
+		}
+		return registry.GetMapVersion(mapID, version)
+	}
 	return nil, rules.ErrorMapNotFound
 }
 
+// GetMapVersion returns the given version of the map associated with the given ID.
+// A version of 0 returns the latest version of the map.
+func (registry MapRegistry) GetMapVersion(id string, version int) (GameMap, error) {
+	m, err := registry.GetMap(id)
+	if err != nil {
+		return nil, err
+	}
+	if version == 0 || m.Meta().Version == version {
+		return m, nil
+	}
+	if layered, ok := m.(*LayeredMap); ok {
+		return nil, rules.RulesetError(fmt.Sprintf("layered map '%s' can't be given a version, give the version of each layer instead, e.g. '%s'", id, layered.VersionedID()))
+	}
+	if m, ok := registry[VersionedID(id, version)]; ok {
+		return m, nil
+	}
+	return nil, rules.RulesetError(fmt.Sprintf("version %d of map '%s' is not available, available versions: %v", version, id, registry.Versions(id)))
+}
+
 // GetMap returns the map associated with the given ID from the global registry.
 func GetMap(id string) (GameMap, error) {
 	return globalRegistry.GetMap(id)
 }
 
+// GetMapVersion returns the given version of the map associated with the given ID from the global registry.
+func GetMapVersion(id string, version int) (GameMap, error) {
+	return globalRegistry.GetMapVersion(id, version)
+}
+
 // List returns a list of maps registered to the global registry.
 func List() []string {
 	return globalRegistry.List()
 }
 
+// Query returns the IDs of the maps in the global registry selected by the query.
+func Query(query MapQuery) []string {
+	return globalRegistry.Query(query)
+}
+
+// Versions returns the versions of the map with the given ID in the global registry.
+func Versions(id string) []int {
+	return globalRegistry.Versions(id)
+}
+
 // RegisterMap adds a map to the global registry.
 func RegisterMap(id string, m GameMap) {
 	globalRegistry.RegisterMap(id, m)
 }
 
+// RegisterMapVersion adds an earlier version of a map to the global registry.
+func RegisterMapVersion(id string, m GameMap) {
+	globalRegistry.RegisterMapVersion(id, m)
+}
+
 func TestMap(id string, m GameMap, callback func()) {
 	globalRegistry[id] = m
 	callback()
@@ -2,6 +2,7 @@ package maps
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/Pikle2/rules"
@@ -20,13 +21,19 @@ var testSettings rules.Settings = rules.NewSettings(map[string]string{
 func TestRegisteredMaps(t *testing.T) {
 	for mapName, gameMap := range globalRegistry {
 		t.Run(mapName, func(t *testing.T) {
-			require.Equalf(t, mapName, gameMap.ID(), "%#v game map doesn't return its own ID", mapName)
+			mapID, version, err := ParseVersionedID(mapName)
+			require.NoError(t, err)
+			require.Equalf(t, mapID, gameMap.ID(), "%#v game map doesn't return its own ID", mapName)
 			meta := gameMap.Meta()
+			if version > 0 {
+				require.Equal(t, version, meta.Version, "earlier map versions must be registered under their own version")
+			}
 			require.True(t, meta.Version > 0, fmt.Sprintf("registered maps must have a valid version (>= 1) - '%d' is invalid", meta.Version))
 			require.NotZero(t, meta.MaxPlayers, "registered maps must have maximum players declared")
 			require.LessOrEqual(t, meta.MaxPlayers, meta.MaxPlayers, "max players should always be >= min players")
 			require.NotEmpty(t, meta.BoardSizes, "registered maps must have at least one supported size declared")
 			require.NotNil(t, meta.Tags)
+			require.NoError(t, checkParameters(meta.Parameters), "declared map parameters must be valid")
 			var setupBoardState *rules.BoardState
 
 			// "fuzz test" supported players
@@ -94,7 +101,7 @@ func TestRegisteredMaps(t *testing.T) {
 
 			passedBoardState := previousBoardState.Clone()
 			tempBoardState := previousBoardState.Clone()
-			err := gameMap.PostUpdateBoard(passedBoardState, testSettings, NewBoardStateEditor(tempBoardState))
+			err = gameMap.PostUpdateBoard(passedBoardState, testSettings, NewBoardStateEditor(tempBoardState))
 			require.NoError(t, err, "GameMap.UpdateBoard returned an error")
 			require.Equal(t, previousBoardState, passedBoardState, "BoardState should not be modified directly by GameMap.UpdateBoard")
 		})
@@ -115,6 +122,9 @@ func TestListRegisteredMaps(t *testing.T) {
 	keys := globalRegistry.List()
 	mapCount := 0
 	for k := range globalRegistry {
+		if strings.Contains(k, VersionSeparator) {
+			continue
+		}
 		// every registry key should exist in List results
 		require.Contains(t, keys, k)
 		mapCount++
@@ -122,3 +132,75 @@ func TestListRegisteredMaps(t *testing.T) {
 	// List should equal number of maps in the global registry
 	require.Equal(t, len(keys), mapCount)
 }
+
+func TestRegistryQuery(t *testing.T) {
+	registry := MapRegistry{}
+	registry.RegisterMap("small", layerTestMap{StubMap: StubMap{Id: "small"}, meta: Metadata{
+		MinPlayers: 1, MaxPlayers: 4, BoardSizes: FixedSizes(Dimensions{7, 7}, Dimensions{11, 11}), Tags: []string{TAG_HAZARD_PLACEMENT},
+	}})
+	registry.RegisterMap("large", layerTestMap{StubMap: StubMap{Id: "large"}, meta: Metadata{
+		MinPlayers: 2, MaxPlayers: 8, BoardSizes: OddSizes(11, 19), Tags: []string{TAG_HAZARD_PLACEMENT, TAG_FOOD_PLACEMENT},
+	}})
+	registry.RegisterMap("any", layerTestMap{StubMap: StubMap{Id: "any"}, meta: Metadata{
+		MinPlayers: 1, Tags: []string{},
+	}})
+	registry.RegisterMap("new", layerTestMap{StubMap: StubMap{Id: "new"}, meta: Metadata{
+		MinPlayers: 1, MaxPlayers: 16, Tags: []string{TAG_EXPERIMENTAL},
+	}})
+
+	require.Equal(t, []string{"any", "large", "small"}, registry.Query(MapQuery{}))
+	require.Equal(t, []string{"any", "large", "new", "small"}, registry.Query(MapQuery{IncludeExperimental: true}))
+	require.Equal(t, []string{"new"}, registry.Query(MapQuery{Tags: []string{TAG_EXPERIMENTAL}}))
+
+	// Every tag is required
+	require.Equal(t, []string{"large", "small"}, registry.Query(MapQuery{Tags: []string{TAG_HAZARD_PLACEMENT}}))
+	require.Equal(t, []string{"large"}, registry.Query(MapQuery{Tags: []string{TAG_HAZARD_PLACEMENT, TAG_FOOD_PLACEMENT}}))
+
+	// Player limits are inclusive, and maps without a maximum allow any number of players
+	require.Equal(t, []string{"any", "small"}, registry.Query(MapQuery{Players: 1}))
+	require.Equal(t, []string{"any", "large", "small"}, registry.Query(MapQuery{Players: 4}))
+	require.Equal(t, []string{"any"}, registry.Query(MapQuery{Players: 9}))
+
+	// Board sizes go through the map's supported sizes
+	require.Equal(t, []string{"any", "small"}, registry.Query(MapQuery{Width: 7, Height: 7}))
+	require.Equal(t, []string{"any", "large"}, registry.Query(MapQuery{Width: 19, Height: 19}))
+	require.Equal(t, []string{"large"}, registry.Query(MapQuery{Width: 19, Height: 19, Players: 4, Tags: []string{TAG_HAZARD_PLACEMENT}}))
+	require.Empty(t, registry.Query(MapQuery{Width: 19, Height: 19, Players: 12, Tags: []string{TAG_HAZARD_PLACEMENT}}))
+}
+
+func TestRegistryMapVersions(t *testing.T) {
+	registry := MapRegistry{}
+	registry.RegisterMap("evolving", layerTestMap{StubMap: StubMap{Id: "evolving"}, meta: Metadata{Name: "v3", Version: 3}})
+	registry.RegisterMapVersion("evolving", layerTestMap{StubMap: StubMap{Id: "evolving"}, meta: Metadata{Name: "v1", Version: 1}})
+	registry.RegisterMapVersion("evolving", layerTestMap{StubMap: StubMap{Id: "evolving"}, meta: Metadata{Name: "v2", Version: 2}})
+	registry.RegisterMap("evolving_more", layerTestMap{StubMap: StubMap{Id: "evolving_more"}, meta: Metadata{Version: 1}})
+
+	require.Equal(t, []string{"evolving", "evolving_more"}, registry.List())
+	require.Equal(t, []int{1, 2, 3}, registry.Versions("evolving"))
+	require.Equal(t, []int{1}, registry.Versions("evolving_more"))
+
+	for version, name := range map[int]string{0: "v3", 1: "v1", 2: "v2", 3: "v3"} {
+		m, err := registry.GetMapVersion("evolving", version)
+		require.NoError(t, err)
+		require.Equal(t, name, m.Meta().Name)
+		require.Equal(t, "evolving", m.ID())
+	}
+
+	m, err := registry.GetMap("evolving@2")
+	require.NoError(t, err)
+	require.Equal(t, "v2", m.Meta().Name)
+	m, err = registry.GetMap("evolving@3")
+	require.NoError(t, err)
+	require.Equal(t, "v3", m.Meta().Name)
+
+	_, err = registry.GetMapVersion("evolving", 4)
+	require.EqualError(t, err, "version 4 of map 'evolving' is not available, available versions: [1 2 3]")
+	_, err = registry.GetMap("evolving@latest")
+	require.Error(t, err)
+	_, err = registry.GetMapVersion("missing", 1)
+	require.Equal(t, rules.ErrorMapNotFound, err)
+
+	require.Panics(t, func() {
+		registry.RegisterMapVersion("evolving", layerTestMap{StubMap: StubMap{Id: "evolving"}, meta: Metadata{Version: 1}})
+	})
+}
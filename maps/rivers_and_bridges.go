@@ -33,15 +33,7 @@ func setupRiverAndBridgesBoard(startingPositions [][]rules.Point, hazards []rule
 }
 
 func placeRiverAndBridgesFood(lastBoardState *rules.BoardState, settings rules.Settings, editor Editor) error {
-	rand := settings.GetRand(lastBoardState.Turn)
-
-	foodNeeded := checkFoodNeedingPlacement(rand, settings, lastBoardState)
-	if foodNeeded > 0 {
-		pts := rules.GetUnoccupiedPoints(lastBoardState, false, true)
-		placeFoodRandomlyAtPositions(rand, lastBoardState, editor, foodNeeded, pts)
-	}
-
-	return nil
+	return UniformFoodSpawner{AvoidHazards: true}.SpawnFood(lastBoardState, settings, editor)
 }
 
 type RiverAndBridgesMediumHazardsMap struct{}
@@ -61,6 +53,7 @@ Each river has one or two 1-square "bridges" over them`,
 		MaxPlayers: 8,
 		BoardSizes: FixedSizes(Dimensions{11, 11}),
 		Tags:       []string{TAG_FOOD_PLACEMENT, TAG_HAZARD_PLACEMENT, TAG_SNAKE_PLACEMENT},
+		Parameters: []Parameter{hazardDamageParameter},
 	}
 }
 
@@ -135,6 +128,7 @@ Each river has one or two 1-square "bridges" over them`,
 		MaxPlayers: 12,
 		BoardSizes: FixedSizes(Dimensions{19, 19}),
 		Tags:       []string{TAG_FOOD_PLACEMENT, TAG_HAZARD_PLACEMENT, TAG_SNAKE_PLACEMENT},
+		Parameters: []Parameter{hazardDamageParameter},
 	}
 }
 
@@ -238,6 +232,7 @@ Each river has one or two 1-square "bridges" over them`,
 		MaxPlayers: 12,
 		BoardSizes: FixedSizes(Dimensions{25, 25}),
 		Tags:       []string{TAG_FOOD_PLACEMENT, TAG_HAZARD_PLACEMENT, TAG_SNAKE_PLACEMENT},
+		Parameters: []Parameter{hazardDamageParameter},
 	}
 }
 
@@ -356,6 +351,7 @@ func (m IslandsAndBridgesMediumHazardsMap) Meta() Metadata {
 		MaxPlayers:  4,
 		BoardSizes:  FixedSizes(Dimensions{11, 11}),
 		Tags:        []string{TAG_FOOD_PLACEMENT, TAG_HAZARD_PLACEMENT, TAG_SNAKE_PLACEMENT},
+		Parameters:  []Parameter{hazardDamageParameter},
 	}
 }
 
@@ -446,6 +442,7 @@ func (m IslandsAndBridgesLargeHazardsMap) Meta() Metadata {
 		MaxPlayers:  16,
 		BoardSizes:  FixedSizes(Dimensions{19, 19}),
 		Tags:        []string{TAG_FOOD_PLACEMENT, TAG_HAZARD_PLACEMENT, TAG_SNAKE_PLACEMENT},
+		Parameters:  []Parameter{hazardDamageParameter},
 	}
 }
 
@@ -1,8 +1,6 @@
 package maps
 
 import (
-	"errors"
-
 	"github.com/Pikle2/rules"
 )
 
@@ -26,6 +24,28 @@ func (m RoyaleHazardsMap) Meta() Metadata {
 		MaxPlayers:  16,
 		BoardSizes:  OddSizes(rules.BoardSizeSmall, rules.BoardSizeXXLarge),
 		Tags:        []string{TAG_HAZARD_PLACEMENT},
+		Parameters: []Parameter{
+			foodSpawnerParameter,
+			hazardDamageParameter,
+			{
+				Name:        rules.ParamShrinkEveryNTurns,
+				Type:        ParamTypeInt,
+				Default:     "20",
+				Description: "Number of turns between each time the safe area shrinks",
+			},
+			{
+				Name:        rules.ParamShrinkPattern,
+				Type:        ParamTypeString,
+				Default:     rules.ShrinkPatternRandomSide,
+				Description: "How the safe area shrinks: randomSide, concentric, spiral, shiftingZone or finalCells",
+			},
+			{
+				Name:        rules.ParamShrinkFinalCells,
+				Type:        ParamTypeInt,
+				Default:     "9",
+				Description: "With the finalCells pattern, the fewest squares the safe area shrinks to",
+			},
+		},
 	}
 }
 
@@ -44,45 +64,15 @@ func (m RoyaleHazardsMap) PostUpdateBoard(lastBoardState *rules.BoardState, sett
 	}
 
 	// Royale uses the current turn to generate hazards, not the previous turn that's in the board state
-	turn := lastBoardState.Turn + 1
-
-	shrinkEveryNTurns := settings.Int(rules.ParamShrinkEveryNTurns, 20)
-	if shrinkEveryNTurns < 1 {
-		return errors.New("royale game can't shrink more frequently than every turn")
-	}
-
-	if turn < shrinkEveryNTurns {
-		return nil
+	hazards, shrinking, err := rules.RoyaleHazards(lastBoardState.Width, lastBoardState.Height, lastBoardState.Turn+1, settings)
+	if err != nil || !shrinking {
+		return err
 	}
 
 	// Reset hazards every turn and re-generate them
 	editor.ClearHazards()
-
-	// Get random generator for turn zero, because we're regenerating all hazards every time.
-	randGenerator := settings.GetRand(0)
-
-	numShrinks := turn / shrinkEveryNTurns
-	minX, maxX := 0, lastBoardState.Width-1
-	minY, maxY := 0, lastBoardState.Height-1
-	for i := 0; i < numShrinks; i++ {
-		switch randGenerator.Intn(4) {
-		case 0:
-			minX += 1
-		case 1:
-			maxX -= 1
-		case 2:
-			minY += 1
-		case 3:
-			maxY -= 1
-		}
-	}
-
-	for x := 0; x < lastBoardState.Width; x++ {
-		for y := 0; y < lastBoardState.Height; y++ {
-			if x < minX || x > maxX || y < minY || y > maxY {
-				editor.AddHazard(rules.Point{X: x, Y: y})
-			}
-		}
+	for _, p := range hazards {
+		editor.AddHazard(p)
 	}
 
 	return nil
@@ -26,6 +26,16 @@ func (m SinkholesMap) Meta() Metadata {
 		MaxPlayers:  8,
 		BoardSizes:  FixedSizes(Dimensions{7, 7}, Dimensions{11, 11}, Dimensions{19, 19}),
 		Tags:        []string{TAG_HAZARD_PLACEMENT},
+		Parameters: []Parameter{
+			foodSpawnerParameter,
+			hazardDamageParameter,
+			{
+				Name:        rules.ParamShrinkEveryNTurns,
+				Type:        ParamTypeInt,
+				Default:     "10",
+				Description: "Number of turns between each time the sinkhole grows",
+			},
+		},
 	}
 }
 
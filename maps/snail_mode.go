@@ -4,11 +4,32 @@ import (
 	"github.com/Pikle2/rules"
 )
 
-type SnailModeMap struct{}
+type SnailModeMap struct {
+	// version is the version of the map to play, or 0 for the latest.
+	// Version 1 stored the tails as hazards off the board, and version 2 didn't give trails an owner.
+	version int
+}
+
+const (
+	snailModeVersion = 3
+
+	// snailTailsKey is the MapState key for the tails of the snakes on the last turn, stacked by snake length.
+	snailTailsKey = "tails"
+	// snailTailOwnersKey is the MapState key for the snake that left each of those tails.
+	snailTailOwnersKey = "tailOwners"
+)
+
+// snailTailOwner is the snake that left a tail, which owns the trail of hazards placed there.
+type snailTailOwner struct {
+	Point rules.Point `json:"point"`
+	ID    string      `json:"id"`
+}
 
 // init registers this map in the global registry.
 func init() {
 	globalRegistry.RegisterMap("snail_mode", SnailModeMap{})
+	globalRegistry.RegisterMapVersion("snail_mode", SnailModeMap{version: 1})
+	globalRegistry.RegisterMapVersion("snail_mode", SnailModeMap{version: 2})
 }
 
 // ID returns a unique identifier for this map.
@@ -18,18 +39,33 @@ func (m SnailModeMap) ID() string {
 
 // Meta returns the non-functional metadata about this map.
 func (m SnailModeMap) Meta() Metadata {
+	version := m.version
+	if version == 0 {
+		version = snailModeVersion
+	}
 	return Metadata{
 		Name:        "Snail Mode",
 		Description: "Snakes leave behind a trail of hazards",
 		Author:      "coreyja and jlafayette",
-		Version:     1,
+		Version:     version,
 		MinPlayers:  1,
 		MaxPlayers:  16,
 		BoardSizes:  OddSizes(rules.BoardSizeSmall, rules.BoardSizeXXLarge),
 		Tags:        []string{TAG_EXPERIMENTAL, TAG_HAZARD_PLACEMENT},
+		Parameters:  []Parameter{foodSpawnerParameter, hazardDamageParameter},
 	}
 }
 
+// offBoardTails reports whether the tails are stored as hazards off the board, like version 1 of the map did.
+func (m SnailModeMap) offBoardTails() bool {
+	return m.version == 1
+}
+
+// ownsTrails reports whether trails are owned by the snake that left them, which was added in version 3 of the map.
+func (m SnailModeMap) ownsTrails() bool {
+	return m.version == 0 || m.version >= 3
+}
+
 // SetupBoard here is pretty 'standard' and doesn't do any special setup for this game mode
 func (m SnailModeMap) SetupBoard(initialBoardState *rules.BoardState, settings rules.Settings, editor Editor) error {
 	// Use StandardMap to populate snakes and food
@@ -37,13 +73,13 @@ func (m SnailModeMap) SetupBoard(initialBoardState *rules.BoardState, settings r
 }
 
 // storeTailLocation returns an offboard point that corresponds to the given point.
-// This is useful for storing state that can be accessed next turn.
+// Version 1 of the map used this to store state that can be accessed next turn.
 func storeTailLocation(point rules.Point, height int) rules.Point {
 	return rules.Point{X: point.X, Y: point.Y + height}
 }
 
 // getPrevTailLocation returns the onboard point that corresponds to an offboard point.
-// This is useful for restoring state that was stored last turn.
+// Version 1 of the map used this to restore state that was stored last turn.
 func getPrevTailLocation(point rules.Point, height int) rules.Point {
 	return rules.Point{X: point.X, Y: point.Y - height}
 }
@@ -70,7 +106,7 @@ func (m SnailModeMap) PreUpdateBoard(lastBoardState *rules.BoardState, settings
 }
 
 // PostUpdateBoard does the work of placing the hazards along the 'snail tail' of snakes
-// This is responsible for saving the current tail location off the board
+// This is responsible for saving the current tail location in the map state
 // and restoring the previous tail position. This also handles removing one hazards from
 // the current stacks so the hazards tails fade as the snake moves away.
 func (m SnailModeMap) PostUpdateBoard(lastBoardState *rules.BoardState, settings rules.Settings, editor Editor) error {
@@ -84,10 +120,16 @@ func (m SnailModeMap) PostUpdateBoard(lastBoardState *rules.BoardState, settings
 	editor.ClearHazards()
 
 	// This is a list of all the hazards we want to add for the previous tails
-	// These were stored off board in the previous turn as a way to save state
-	// When we add the locations to this list we have already converted the off-board
-	// points to on-board points
-	tailLocations := make([]rules.Point, 0, len(lastBoardState.Snakes))
+	// These were stored in the map state in the previous turn
+	state := EditorMapState(editor, m.ID())
+	tailLocations, err := state.Points(snailTailsKey)
+	if err != nil {
+		return err
+	}
+	var tailOwners []snailTailOwner
+	if _, err := state.JSON(snailTailOwnersKey, &tailOwners); err != nil {
+		return err
+	}
 
 	// Count the number of hazards for a given position
 	// Add non-double tail locations to a slice
@@ -96,8 +138,10 @@ func (m SnailModeMap) PostUpdateBoard(lastBoardState *rules.BoardState, settings
 
 		// discard out of bound
 		if outOfBounds(hazard, lastBoardState.Width, lastBoardState.Height) {
-			onBoardTail := getPrevTailLocation(hazard, lastBoardState.Height)
-			tailLocations = append(tailLocations, onBoardTail)
+			if m.offBoardTails() {
+				onBoardTail := getPrevTailLocation(hazard, lastBoardState.Height)
+				tailLocations = append(tailLocations, onBoardTail)
+			}
 		} else {
 			hazardCounts[hazard]++
 		}
@@ -112,9 +156,11 @@ func (m SnailModeMap) PostUpdateBoard(lastBoardState *rules.BoardState, settings
 		}
 	}
 
-	// Store a stack of hazards for the tail of each snake.  This is stored out
-	// of bounds and then applied on the next turn.  The stack count is equal
+	// Store a stack of hazards for the tail of each snake.  This is stored in
+	// the map state and then applied on the next turn.  The stack count is equal
 	// the lenght of the snake.
+	nextTailLocations := []rules.Point{}
+	nextTailOwners := []snailTailOwner{}
 	for _, snake := range lastBoardState.Snakes {
 		if isEliminated(&snake) {
 			continue
@@ -127,13 +173,25 @@ func (m SnailModeMap) PostUpdateBoard(lastBoardState *rules.BoardState, settings
 		}
 
 		tail := snake.Body[len(snake.Body)-1]
-		offBoardTail := storeTailLocation(tail, lastBoardState.Height)
 		for i := 0; i < len(snake.Body); i++ {
-			editor.AddHazard(offBoardTail)
+			if m.offBoardTails() {
+				editor.AddHazard(storeTailLocation(tail, lastBoardState.Height))
+			} else {
+				nextTailLocations = append(nextTailLocations, tail)
+			}
+		}
+		nextTailOwners = append(nextTailOwners, snailTailOwner{Point: tail, ID: snake.ID})
+	}
+	if !m.offBoardTails() {
+		state.SetPoints(snailTailsKey, nextTailLocations)
+	}
+	if m.ownsTrails() {
+		if err := state.SetJSON(snailTailOwnersKey, nextTailOwners); err != nil {
+			return err
 		}
 	}
 
-	// Read offboard tails and move them to the board. The offboard tails are
+	// Move the stored tails to the board. The tails are
 	// stacked based on the length of the snake
 	for _, p := range tailLocations {
 
@@ -158,5 +216,15 @@ func (m SnailModeMap) PostUpdateBoard(lastBoardState *rules.BoardState, settings
 		editor.AddHazard(p)
 	}
 
+	// The snake that left a trail is credited with eliminating snakes in it. The owner stays with the
+	// trail as it fades, and is removed once the trail is gone and the square has no hazards left.
+	if m.ownsTrails() {
+		for _, owner := range tailOwners {
+			if err := EditorSetHazardOwner(editor, owner.Point, owner.ID); err != nil {
+				return err
+			}
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,91 @@
+package maps_test
+
+import (
+	"testing"
+
+	"github.com/Pikle2/rules"
+	"github.com/Pikle2/rules/maps"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnailModeMapKeepsTailsInMapState(t *testing.T) {
+	for _, version := range []int{1, 2, 3} {
+		gameMap, err := maps.GetMapVersion("snail_mode", version)
+		require.NoError(t, err)
+
+		boardState := rules.NewBoardState(11, 11)
+		boardState.Snakes = []rules.Snake{{ID: "1", Health: 100, Body: []rules.Point{{X: 5, Y: 3}, {X: 5, Y: 2}, {X: 5, Y: 1}}}}
+		settings := rules.NewSettingsWithParams(rules.ParamMinimumFood, "0", rules.ParamFoodSpawnChance, "0")
+
+		for turn := 1; turn <= 3; turn++ {
+			head := boardState.Snakes[0].Body[0]
+			boardState.Snakes[0].Body = append([]rules.Point{{X: head.X, Y: head.Y + 1}}, boardState.Snakes[0].Body[:2]...)
+			boardState.Turn = turn
+			boardState, err = maps.PostUpdateBoard(gameMap, boardState, settings)
+			require.NoError(t, err)
+		}
+
+		var onBoard, offBoard []rules.Point
+		for _, p := range boardState.Hazards {
+			if p.Y >= boardState.Height {
+				offBoard = append(offBoard, p)
+			} else {
+				onBoard = append(onBoard, p)
+			}
+		}
+		// The tails of the last two turns are on the board, the older one faded by one
+		require.ElementsMatch(t, []rules.Point{{X: 5, Y: 2}, {X: 5, Y: 2}, {X: 5, Y: 3}, {X: 5, Y: 3}, {X: 5, Y: 3}}, onBoard, "version %d", version)
+		if version == 1 {
+			require.Len(t, offBoard, 3)
+		} else {
+			require.Empty(t, offBoard)
+		}
+	}
+}
+
+func TestSnailModeMapOwnsTrails(t *testing.T) {
+	gameMap, err := maps.GetMap("snail_mode")
+	require.NoError(t, err)
+
+	boardState := rules.NewBoardState(11, 11)
+	boardState.Snakes = []rules.Snake{{ID: "1", Health: 100, Body: []rules.Point{{X: 5, Y: 3}, {X: 5, Y: 2}, {X: 5, Y: 1}}}}
+	settings := rules.NewSettingsWithParams(rules.ParamMinimumFood, "0", rules.ParamFoodSpawnChance, "0")
+
+	move := func() {
+		head := boardState.Snakes[0].Body[0]
+		boardState.Snakes[0].Body = append([]rules.Point{{X: head.X, Y: head.Y + 1}}, boardState.Snakes[0].Body[:2]...)
+		boardState.Turn += 1
+		boardState, err = maps.PostUpdateBoard(gameMap, boardState, settings)
+		require.NoError(t, err)
+	}
+
+	move()
+	move()
+	require.Contains(t, boardState.Hazards, rules.Point{X: 5, Y: 2})
+	require.Equal(t, "1", rules.GetHazardOwner(boardState, rules.Point{X: 5, Y: 2}))
+	require.Equal(t, "", rules.GetHazardOwner(boardState, rules.Point{X: 6, Y: 2}))
+
+	// Once the snake stops leaving a trail, the trail fades away and loses its owner
+	boardState.Snakes[0].EliminatedCause = rules.EliminatedByOutOfHealth
+	for turn := 0; turn < 5; turn++ {
+		boardState.Turn += 1
+		boardState, err = maps.PostUpdateBoard(gameMap, boardState, settings)
+		require.NoError(t, err)
+	}
+	require.Empty(t, boardState.Hazards)
+	for key := range boardState.GameState {
+		require.NotContains(t, key, "hazardOwner")
+	}
+
+	// Versions 1 and 2 of the map don't own trails
+	for _, version := range []int{1, 2} {
+		gameMap, err = maps.GetMapVersion("snail_mode", version)
+		require.NoError(t, err)
+		boardState = rules.NewBoardState(11, 11)
+		boardState.Snakes = []rules.Snake{{ID: "1", Health: 100, Body: []rules.Point{{X: 5, Y: 3}, {X: 5, Y: 2}, {X: 5, Y: 1}}}}
+		move()
+		move()
+		require.Contains(t, boardState.Hazards, rules.Point{X: 5, Y: 2}, "version %d", version)
+		require.Equal(t, "", rules.GetHazardOwner(boardState, rules.Point{X: 5, Y: 2}), "version %d", version)
+	}
+}
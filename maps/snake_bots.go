@@ -0,0 +1,163 @@
+package maps
+
+import (
+	"fmt"
+
+	"github.com/Pikle2/rules"
+)
+
+// Parameters of the snake bots map.
+const (
+	ParamBotCount   = "botCount"
+	ParamBotPolicy  = "botPolicy"
+	ParamBotsCanWin = "botsCanWin"
+)
+
+const (
+	defaultBotCount = 2
+	maxBotCount     = 8
+	botBodyLength   = 3
+
+	// minBotDistance is the smallest distance between a bot's start and the start of any other snake.
+	minBotDistance = 3
+)
+
+// SnakeBotsMap places snakes and food like the standard map, and adds bot snakes that are moved by a BotPolicy.
+// Bots never starve, and by default they don't count toward win conditions, so a game ends when
+// all but one of the players have been eliminated.
+type SnakeBotsMap struct{}
+
+func init() {
+	globalRegistry.RegisterMap("snake_bots", SnakeBotsMap{})
+}
+
+func (m SnakeBotsMap) ID() string {
+	return "snake_bots"
+}
+
+func (m SnakeBotsMap) Meta() Metadata {
+	return Metadata{
+		Name:        "Snake Bots",
+		Description: "Standard snake placement and food spawning, with bot snakes controlled by the map",
+		Author:      "Battlesnake",
+		Version:     1,
+		MinPlayers:  1,
+		MaxPlayers:  8,
+		BoardSizes:  OddSizes(rules.BoardSizeMedium, rules.BoardSizeXXLarge),
+		Tags:        []string{TAG_EXPERIMENTAL, TAG_SNAKE_PLACEMENT},
+		Parameters: []Parameter{
+			foodSpawnerParameter,
+			{
+				Name:        ParamBotCount,
+				Type:        ParamTypeInt,
+				Default:     fmt.Sprint(defaultBotCount),
+				Description: fmt.Sprintf("Number of bot snakes, from 0 to %d", maxBotCount),
+			},
+			{
+				Name:        ParamBotPolicy,
+				Type:        ParamTypeString,
+				Default:     BotPolicyChaseFood,
+				Description: fmt.Sprintf("How the bots move: %s, %s or %s", BotPolicyPatrol, BotPolicyChaseFood, BotPolicyChaseHead),
+			},
+			{
+				Name:        ParamBotsCanWin,
+				Type:        ParamTypeBool,
+				Default:     "false",
+				Description: "Whether bots count toward win conditions like any other snake",
+			},
+		},
+	}
+}
+
+func (m SnakeBotsMap) SetupBoard(initialBoardState *rules.BoardState, settings rules.Settings, editor Editor) error {
+	if err := m.Meta().Validate(initialBoardState); err != nil {
+		return err
+	}
+
+	count := settings.Int(ParamBotCount, defaultBotCount)
+	if count < 0 || count > maxBotCount {
+		return rules.RulesetError(fmt.Sprintf("bot count must be between 0 and %d", maxBotCount))
+	}
+	policy := settings.Params()[ParamBotPolicy]
+	if policy == "" {
+		policy = BotPolicyChaseFood
+	}
+	if _, err := GetBotPolicy(policy); err != nil {
+		return err
+	}
+	bot := rules.Bot{Policy: policy, ExcludeFromWin: !settings.Bool(ParamBotsCanWin, false)}
+
+	if err := (StandardMap{}).SetupBoard(initialBoardState, settings, editor); err != nil {
+		return err
+	}
+
+	var heads []rules.Point
+	for _, body := range editor.SnakeBodies() {
+		heads = append(heads, body[0])
+	}
+	rand := settings.GetRand(0)
+	occupied := editor.OccupiedPoints(true, true, true)
+	var candidates []rules.Point
+	for x := 0; x < initialBoardState.Width; x++ {
+		for y := 0; y < initialBoardState.Height; y++ {
+			if p := (rules.Point{X: x, Y: y}); !occupied[p] {
+				candidates = append(candidates, p)
+			}
+		}
+	}
+	editor.ShufflePoints(rand, candidates)
+
+	for i := 0; i < count; i++ {
+		start, ok := farFromHeads(candidates, heads)
+		if !ok {
+			return rules.RulesetError(fmt.Sprintf("no room for %d bots on a %dx%d board", count, initialBoardState.Width, initialBoardState.Height))
+		}
+		heads = append(heads, start)
+
+		id := fmt.Sprintf("bot-%d", i+1)
+		bot.Name = fmt.Sprintf("Bot %d", i+1)
+		body := make([]rules.Point, botBodyLength)
+		for j := range body {
+			body[j] = start
+		}
+		if err := EditorPlaceBot(editor, id, body, rules.SnakeMaxHealth, bot); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// farFromHeads returns the first candidate that is far enough from every head.
+func farFromHeads(candidates, heads []rules.Point) (rules.Point, bool) {
+	for _, p := range candidates {
+		far := true
+		for _, head := range heads {
+			if manhattanDistance(p, head) < minBotDistance {
+				far = false
+				break
+			}
+		}
+		if far {
+			return p, true
+		}
+	}
+	return rules.Point{}, false
+}
+
+func (m SnakeBotsMap) PreUpdateBoard(lastBoardState *rules.BoardState, settings rules.Settings, editor Editor) error {
+	return nil
+}
+
+// PostUpdateBoard spawns food like the standard map, and feeds the bots so that they never starve.
+func (m SnakeBotsMap) PostUpdateBoard(lastBoardState *rules.BoardState, settings rules.Settings, editor Editor) error {
+	if err := (StandardMap{}).PostUpdateBoard(lastBoardState, settings, editor); err != nil {
+		return err
+	}
+	bodies := editor.SnakeBodies()
+	for _, snake := range lastBoardState.Snakes {
+		if _, ok := rules.GetBot(lastBoardState, snake.ID); ok && snake.EliminatedCause == rules.NotEliminated {
+			editor.PlaceSnake(snake.ID, bodies[snake.ID], rules.SnakeMaxHealth)
+		}
+	}
+	return nil
+}
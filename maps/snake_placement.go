@@ -0,0 +1,68 @@
+package maps
+
+import (
+	"fmt"
+
+	"github.com/Pikle2/rules"
+)
+
+// ParamSnakePlacement is the setting that chooses how maps that support it place snakes at the start of a game.
+const ParamSnakePlacement = "snakePlacement"
+
+// ParamStartBody is the setting that chooses the starting body of snakes placed with SnakePlacementSpread.
+const ParamStartBody = "startBody"
+
+// Snake placements that can be chosen with the snakePlacement setting.
+const (
+	SnakePlacementAutomatic = "automatic" // the standard start positions, see rules.PlaceSnakesAutomatically
+	SnakePlacementSpread    = "spread"    // start positions spread as far apart as possible, see rules.PlaceSnakesSpread
+)
+
+// StartBodyStacked stacks the whole starting body on the head, like standard placement.
+// The other startBody values are the rules.BodyShape extensions: up, down, left, right and outward.
+const StartBodyStacked = "stacked"
+
+// PlaceSnakesFromSettings places snakes on a board using the placement chosen by the snakePlacement and startBody settings.
+// Without those settings snakes are placed with rules.PlaceSnakesAutomatically, so maps keep their start positions.
+func PlaceSnakesFromSettings(rand rules.Rand, settings rules.Settings, b *rules.BoardState, snakeIDs []string) error {
+	params := settings.Params()
+	shape, err := startBodyShape(params[ParamStartBody])
+	if err != nil {
+		return err
+	}
+
+	switch params[ParamSnakePlacement] {
+	case "", SnakePlacementAutomatic:
+		if shape != (rules.BodyShape{}) {
+			return rules.RulesetError(fmt.Sprintf("%s can only be used with %s %s", ParamStartBody, ParamSnakePlacement, SnakePlacementSpread))
+		}
+		return rules.PlaceSnakesAutomatically(rand, b, snakeIDs)
+	case SnakePlacementSpread:
+		return rules.PlaceSnakesSpread(rand, b, snakeIDs, shape)
+	}
+	return rules.RulesetError(fmt.Sprintf("unknown snake placement '%s'", params[ParamSnakePlacement]))
+}
+
+func startBodyShape(name string) (rules.BodyShape, error) {
+	switch name {
+	case "", StartBodyStacked:
+		return rules.BodyShape{}, nil
+	case rules.MoveUp, rules.MoveDown, rules.MoveLeft, rules.MoveRight, rules.ExtendOutward:
+		return rules.BodyShape{Extend: name}, nil
+	}
+	return rules.BodyShape{}, rules.RulesetError(fmt.Sprintf("unknown start body '%s'", name))
+}
+
+var snakePlacementParameter = Parameter{
+	Name:        ParamSnakePlacement,
+	Type:        ParamTypeString,
+	Default:     SnakePlacementAutomatic,
+	Description: fmt.Sprintf("How snakes are placed at the start: %s or %s", SnakePlacementAutomatic, SnakePlacementSpread),
+}
+
+var startBodyParameter = Parameter{
+	Name:        ParamStartBody,
+	Type:        ParamTypeString,
+	Default:     StartBodyStacked,
+	Description: fmt.Sprintf("Starting body of snakes placed with %s %s: %s, %s, %s, %s, %s or %s", ParamSnakePlacement, SnakePlacementSpread, StartBodyStacked, rules.MoveUp, rules.MoveDown, rules.MoveLeft, rules.MoveRight, rules.ExtendOutward),
+}
@@ -0,0 +1,55 @@
+package maps_test
+
+import (
+	"testing"
+
+	"github.com/Pikle2/rules"
+	"github.com/Pikle2/rules/maps"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlaceSnakesFromSettings(t *testing.T) {
+	snakeIDs := []string{"1", "2", "3", "4"}
+
+	// Without settings, snakes are placed like before
+	expected := rules.NewBoardState(19, 21)
+	require.NoError(t, rules.PlaceSnakesAutomatically(rules.MaxRand, expected, snakeIDs))
+	boardState := rules.NewBoardState(19, 21)
+	require.NoError(t, maps.PlaceSnakesFromSettings(rules.MaxRand, rules.Settings{}, boardState, snakeIDs))
+	require.Equal(t, expected.Snakes, boardState.Snakes)
+
+	settings := rules.NewSettingsWithParams(maps.ParamSnakePlacement, maps.SnakePlacementSpread, maps.ParamStartBody, rules.ExtendOutward)
+	boardState = rules.NewBoardState(19, 21)
+	require.NoError(t, maps.PlaceSnakesFromSettings(rules.MaxRand, settings, boardState, snakeIDs))
+	require.Len(t, boardState.Snakes, len(snakeIDs))
+	for _, snake := range boardState.Snakes {
+		require.Len(t, snake.Body, rules.SnakeStartSize)
+		require.NotEqual(t, snake.Body[0], snake.Body[1], "body should extend outward from the head")
+	}
+
+	settings = rules.NewSettingsWithParams(maps.ParamStartBody, rules.MoveUp)
+	err := maps.PlaceSnakesFromSettings(rules.MaxRand, settings, rules.NewBoardState(11, 11), snakeIDs)
+	require.EqualError(t, err, "startBody can only be used with snakePlacement spread")
+
+	settings = rules.NewSettingsWithParams(maps.ParamSnakePlacement, "corners")
+	err = maps.PlaceSnakesFromSettings(rules.MaxRand, settings, rules.NewBoardState(11, 11), snakeIDs)
+	require.EqualError(t, err, "unknown snake placement 'corners'")
+
+	settings = rules.NewSettingsWithParams(maps.ParamSnakePlacement, maps.SnakePlacementSpread, maps.ParamStartBody, "sideways")
+	err = maps.PlaceSnakesFromSettings(rules.MaxRand, settings, rules.NewBoardState(11, 11), snakeIDs)
+	require.EqualError(t, err, "unknown start body 'sideways'")
+}
+
+func TestStandardMapSpreadPlacement(t *testing.T) {
+	m := maps.StandardMap{}
+	settings := rules.NewSettingsWithParams(maps.ParamSnakePlacement, maps.SnakePlacementSpread, maps.ParamStartBody, rules.ExtendOutward).WithSeed(3)
+	initialBoardState := rules.NewBoardState(11, 11).WithSnakes(generateSnakes(2))
+	boardState := initialBoardState.Clone()
+	require.NoError(t, m.SetupBoard(initialBoardState, settings, maps.NewBoardStateEditor(boardState)))
+
+	require.Len(t, boardState.Snakes, 2)
+	require.NotEmpty(t, boardState.Food)
+	for _, snake := range boardState.Snakes {
+		require.NotEqual(t, snake.Body[0], snake.Body[1])
+	}
+}
@@ -28,11 +28,18 @@ const EVIL_MODE_DISTANCE_TO_FOOD = 5
 
 const MAX_TRIES = 100
 
-type SoloMazeMap struct{}
+type SoloMazeMap struct {
+	// hazardBitState stores the level as a row of hazards on y=0, like version 1 of the map did.
+	hazardBitState bool
+}
+
+// mazeLevelKey is the MapState key for the current level of the maze.
+const mazeLevelKey = "level"
 
 func init() {
 	mazeMap := SoloMazeMap{}
 	globalRegistry.RegisterMap(mazeMap.ID(), mazeMap)
+	globalRegistry.RegisterMapVersion(mazeMap.ID(), SoloMazeMap{hazardBitState: true})
 }
 
 func (m SoloMazeMap) ID() string {
@@ -40,11 +47,15 @@ func (m SoloMazeMap) ID() string {
 }
 
 func (m SoloMazeMap) Meta() Metadata {
+	version := 2
+	if m.hazardBitState {
+		version = 1
+	}
 	return Metadata{
 		Name:        "Solo Maze",
 		Description: "Solo Maze where you need to find the food",
 		Author:      "coreyja",
-		Version:     1,
+		Version:     version,
 		MinPlayers:  1,
 		MaxPlayers:  1,
 		BoardSizes: FixedSizes(
@@ -54,7 +65,8 @@ func (m SoloMazeMap) Meta() Metadata {
 			Dimensions{19, 21},
 			Dimensions{25, 25},
 		),
-		Tags: []string{TAG_EXPERIMENTAL, TAG_FOOD_PLACEMENT, TAG_HAZARD_PLACEMENT, TAG_SNAKE_PLACEMENT},
+		Tags:       []string{TAG_EXPERIMENTAL, TAG_FOOD_PLACEMENT, TAG_HAZARD_PLACEMENT, TAG_SNAKE_PLACEMENT},
+		Parameters: []Parameter{hazardDamageParameter},
 	}
 }
 
@@ -101,7 +113,7 @@ func (m SoloMazeMap) CreateMaze(initialBoardState *rules.BoardState, settings ru
 
 	editor.ClearHazards()
 
-	m.WriteBitState(initialBoardState, currentLevel, editor)
+	m.writeLevel(initialBoardState, currentLevel, editor)
 
 	m.SubdivideRoom(mazeBoardState, rand, rules.Point{X: 0, Y: 0}, topRightCorner, make([]int, 0), make([]int, 0), 0)
 
@@ -134,11 +146,15 @@ func (m SoloMazeMap) CreateMaze(initialBoardState *rules.BoardState, settings ru
 	/// Pick random food spawn point
 	m.PlaceFood(tempBoardState, settings, editor, currentLevel)
 
-	// Fill outside of the board with walls
+	// Fill outside of the board with walls, except for the row that holds the level in version 1
 	xAdjust := int((initialBoardState.Width - int(actualBoardSize)) / 2)
 	yAdjust := int((initialBoardState.Height - int(actualBoardSize)) / 2)
+	minY := 0
+	if m.hazardBitState {
+		minY = 1
+	}
 	for x := 0; x < initialBoardState.Width; x++ {
-		for y := 1; y < initialBoardState.Height; y++ {
+		for y := minY; y < initialBoardState.Height; y++ {
 			if x < xAdjust || y < yAdjust || x >= xAdjust+int(actualBoardSize) || y >= yAdjust+int(actualBoardSize) {
 				editor.AddHazard(rules.Point{X: x, Y: y})
 			}
@@ -181,14 +197,14 @@ func (m SoloMazeMap) PreUpdateBoard(lastBoardState *rules.BoardState, settings r
 }
 
 func (m SoloMazeMap) PostUpdateBoard(lastBoardState *rules.BoardState, settings rules.Settings, editor Editor) error {
-	currentLevel, e := m.ReadBitState(lastBoardState)
+	currentLevel, e := m.readLevel(lastBoardState, editor)
 	if e != nil {
 		return e
 	}
 
 	if len(lastBoardState.Food) == 0 {
 		currentLevel += 1
-		m.WriteBitState(lastBoardState, currentLevel, editor)
+		m.writeLevel(lastBoardState, currentLevel, editor)
 
 		// This will create a new maze
 		return m.CreateMaze(lastBoardState, settings, editor, currentLevel)
@@ -361,6 +377,24 @@ func (m SoloMazeMap) AdjustPosition(mazePosition rules.Point, actualBoardSize in
 	return rules.Point{X: mazePosition.X + xAdjust, Y: mazePosition.Y + yAdjust}
 }
 
+// readLevel returns the current level of the maze from the map state.
+func (m SoloMazeMap) readLevel(boardState *rules.BoardState, editor Editor) (int64, error) {
+	if m.hazardBitState {
+		return m.ReadBitState(boardState)
+	}
+	return int64(EditorMapState(editor, m.ID()).Int(mazeLevelKey, 0)), nil
+}
+
+// writeLevel stores the current level of the maze in the map state.
+func (m SoloMazeMap) writeLevel(boardState *rules.BoardState, level int64, editor Editor) {
+	if m.hazardBitState {
+		m.WriteBitState(boardState, level, editor)
+		return
+	}
+	EditorMapState(editor, m.ID()).SetInt(mazeLevelKey, int(level))
+}
+
+// ReadBitState reads the level from the row of hazards on y=0 that version 1 of the map stores it in.
 func (m SoloMazeMap) ReadBitState(boardState *rules.BoardState) (int64, error) {
 	row := 0
 	width := boardState.Width
@@ -378,6 +412,7 @@ func (m SoloMazeMap) ReadBitState(boardState *rules.BoardState) (int64, error) {
 	return strconv.ParseInt(stringBits, 2, 64)
 }
 
+// WriteBitState writes the level to the row of hazards on y=0 that version 1 of the map stores it in.
 func (m SoloMazeMap) WriteBitState(boardState *rules.BoardState, state int64, editor Editor) {
 	width := boardState.Width
 
@@ -0,0 +1,44 @@
+package maps_test
+
+import (
+	"testing"
+
+	"github.com/Pikle2/rules"
+	"github.com/Pikle2/rules/maps"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSoloMazeMapKeepsLevelInMapState(t *testing.T) {
+	settings := rules.NewSettingsWithParams(rules.ParamMinimumFood, "0").WithSeed(3)
+
+	gameMap := maps.SoloMazeMap{}
+	boardState, err := maps.SetupBoardWithMap(gameMap, settings, 11, 11, []string{"1"})
+	require.NoError(t, err)
+	require.Equal(t, 0, maps.NewBoardStateEditor(boardState).MapState(gameMap.ID()).Int("level", -1))
+
+	// Eating the food moves to the next level
+	boardState.Food = nil
+	boardState.Turn = 1
+	boardState, err = maps.PostUpdateBoard(gameMap, boardState, settings)
+	require.NoError(t, err)
+	require.Equal(t, 1, maps.NewBoardStateEditor(boardState).MapState(gameMap.ID()).Int("level", -1))
+	require.Len(t, boardState.Food, 1)
+
+	// The bottom row is outside of the maze, so it's all walls
+	for x := 0; x < boardState.Width; x++ {
+		require.Contains(t, boardState.Hazards, rules.Point{X: x, Y: 0})
+	}
+
+	// Version 1 stores the level as hazards on the bottom row
+	v1, err := maps.GetMapVersion(gameMap.ID(), 1)
+	require.NoError(t, err)
+	boardState, err = maps.SetupBoardWithMap(v1, settings, 11, 11, []string{"1"})
+	require.NoError(t, err)
+	boardState.Food = nil
+	boardState, err = maps.PostUpdateBoard(v1, boardState, settings)
+	require.NoError(t, err)
+	level, err := maps.SoloMazeMap{}.ReadBitState(boardState)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), level)
+	require.Empty(t, boardState.GameState)
+}
@@ -24,6 +24,7 @@ func (m StandardMap) Meta() Metadata {
 		MaxPlayers:  16,
 		BoardSizes:  OddSizes(rules.BoardSizeSmall, rules.BoardSizeXXLarge),
 		Tags:        []string{},
+		Parameters:  []Parameter{foodSpawnerParameter, snakePlacementParameter, startBodyParameter},
 	}
 }
 
@@ -39,8 +40,11 @@ func (m StandardMap) SetupBoard(initialBoardState *rules.BoardState, settings ru
 		snakeIDs = append(snakeIDs, snake.ID)
 	}
 
-	tempBoardState, err := rules.CreateDefaultBoardState(rand, initialBoardState.Width, initialBoardState.Height, snakeIDs)
-	if err != nil {
+	tempBoardState := rules.NewBoardState(initialBoardState.Width, initialBoardState.Height)
+	if err := PlaceSnakesFromSettings(rand, settings, tempBoardState, snakeIDs); err != nil {
+		return err
+	}
+	if err := rules.PlaceFoodAutomatically(rand, tempBoardState); err != nil {
 		return err
 	}
 
@@ -61,35 +65,13 @@ func (m StandardMap) PreUpdateBoard(lastBoardState *rules.BoardState, settings r
 	return nil
 }
 
+// PostUpdateBoard spawns food with the spawner chosen by the foodSpawner setting, or on random free squares by default.
 func (m StandardMap) PostUpdateBoard(lastBoardState *rules.BoardState, settings rules.Settings, editor Editor) error {
-	rand := settings.GetRand(lastBoardState.Turn)
-
-	foodNeeded := checkFoodNeedingPlacement(rand, settings, lastBoardState)
-	if foodNeeded > 0 {
-		placeFoodRandomly(rand, lastBoardState, editor, foodNeeded)
-	}
-
-	return nil
-}
-
-func checkFoodNeedingPlacement(rand rules.Rand, settings rules.Settings, state *rules.BoardState) int {
-	minFood := settings.Int(rules.ParamMinimumFood, 0)
-	foodSpawnChance := settings.Int(rules.ParamFoodSpawnChance, 0)
-	numCurrentFood := len(state.Food)
-
-	if numCurrentFood < minFood {
-		return minFood - numCurrentFood
-	}
-	if foodSpawnChance > 0 && (100-rand.Intn(100)) < foodSpawnChance {
-		return 1
+	spawner, err := FoodSpawnerFromSettings(settings, UniformFoodSpawner{})
+	if err != nil {
+		return err
 	}
-
-	return 0
-}
-
-func placeFoodRandomly(rand rules.Rand, b *rules.BoardState, editor Editor, n int) {
-	unoccupiedPoints := rules.GetUnoccupiedPoints(b, false, false)
-	placeFoodRandomlyAtPositions(rand, b, editor, n, unoccupiedPoints)
+	return spawner.SpawnFood(lastBoardState, settings, editor)
 }
 
 func placeFoodRandomlyAtPositions(rand rules.Rand, b *rules.BoardState, editor Editor, n int, positions []rules.Point) {
@@ -0,0 +1,372 @@
+package maps
+
+import (
+	"fmt"
+
+	"github.com/Pikle2/rules"
+)
+
+// Parameters and structure styles of the symmetric arena map.
+const (
+	ParamArenaDensity = "arenaDensity"
+	ParamArenaStyle   = "arenaStyle"
+
+	ArenaStylePillars = "pillars"
+	ArenaStyleWalls   = "walls"
+)
+
+const (
+	defaultArenaDensity = 15
+	maxArenaDensity     = 40
+
+	// minArenaFairness is the smallest ratio between the territories of two players that
+	// is accepted without trying to generate another arena.
+	minArenaFairness = 0.5
+)
+
+// SymmetricArenaMap generates a new arena from the game seed, with hazard structures and start positions
+// that have the same rotational or mirror symmetry as the number of players:
+//   - up to 2 players are placed on opposite sides of the board (180 degree rotation)
+//   - up to 4 players are placed in each quarter of the board (90 degree rotations)
+//   - up to 8 players are placed in each eighth of the board (90 degree rotations and mirrors)
+//
+// Every start position is reachable from every other, and has the same number of squares closer to it than to any other start.
+// When there are fewer players than start positions, the start positions are picked so that each snake has as close
+// to the same number of squares closer to it as possible, which is only exactly the same for 1, 2, 4 and 8 players.
+type SymmetricArenaMap struct{}
+
+func init() {
+	globalRegistry.RegisterMap("hz_symmetric_arena", SymmetricArenaMap{})
+}
+
+func (m SymmetricArenaMap) ID() string {
+	return "hz_symmetric_arena"
+}
+
+func (m SymmetricArenaMap) Meta() Metadata {
+	return Metadata{
+		Name:        "Symmetric Arena",
+		Description: "Generates a new arena every game with hazard structures and start positions that are symmetric for the number of players",
+		Author:      "Battlesnake",
+		Version:     1,
+		MinPlayers:  1,
+		MaxPlayers:  8,
+		BoardSizes:  OddSizes(rules.BoardSizeMedium, rules.BoardSizeXXLarge),
+		Tags:        []string{TAG_FOOD_PLACEMENT, TAG_HAZARD_PLACEMENT, TAG_SNAKE_PLACEMENT},
+		Parameters: []Parameter{
+			hazardDamageParameter,
+			{
+				Name:        ParamArenaDensity,
+				Type:        ParamTypeInt,
+				Default:     fmt.Sprint(defaultArenaDensity),
+				Description: fmt.Sprintf("Percentage of the board covered by hazard structures, from 0 to %d", maxArenaDensity),
+			},
+			{
+				Name:        ParamArenaStyle,
+				Type:        ParamTypeString,
+				Default:     ArenaStyleWalls,
+				Description: "Shape of the hazard structures, either walls or pillars",
+			},
+		},
+	}
+}
+
+func (m SymmetricArenaMap) SetupBoard(initialBoardState *rules.BoardState, settings rules.Settings, editor Editor) error {
+	if err := m.Meta().Validate(initialBoardState); err != nil {
+		return err
+	}
+
+	density := settings.Int(ParamArenaDensity, defaultArenaDensity)
+	if density < 0 || density > maxArenaDensity {
+		return rules.RulesetError(fmt.Sprintf("arena density must be between 0 and %d", maxArenaDensity))
+	}
+	style := settings.Params()[ParamArenaStyle]
+	if style == "" {
+		style = ArenaStyleWalls
+	}
+	if style != ArenaStyleWalls && style != ArenaStylePillars {
+		return rules.RulesetError(fmt.Sprintf("unknown arena style %q, expected walls or pillars", style))
+	}
+
+	arena, err := generateSymmetricArena(settings.GetRand(0), initialBoardState.Width, len(initialBoardState.Snakes), density, style)
+	if err != nil {
+		return err
+	}
+
+	for i, snake := range initialBoardState.Snakes {
+		start := arena.starts[arena.players[i]]
+		editor.PlaceSnake(snake.ID, []rules.Point{start, start, start}, rules.SnakeMaxHealth)
+		editor.AddFood(arena.food[arena.players[i]])
+	}
+	editor.AddFood(arena.center())
+	for _, p := range arena.walls {
+		editor.AddHazard(p)
+	}
+	return nil
+}
+
+func (m SymmetricArenaMap) PreUpdateBoard(lastBoardState *rules.BoardState, settings rules.Settings, editor Editor) error {
+	return nil
+}
+
+// PostUpdateBoard spawns food like the standard map, but never inside the hazard structures.
+func (m SymmetricArenaMap) PostUpdateBoard(lastBoardState *rules.BoardState, settings rules.Settings, editor Editor) error {
+	return UniformFoodSpawner{AvoidHazards: true}.SpawnFood(lastBoardState, settings, editor)
+}
+
+// arenaSymmetry maps a point to its symmetric point on a square board, where c is the largest coordinate.
+type arenaSymmetry func(p rules.Point, c int) rules.Point
+
+var arenaRotations = []arenaSymmetry{
+	func(p rules.Point, c int) rules.Point { return p },
+	func(p rules.Point, c int) rules.Point { return rules.Point{X: c - p.Y, Y: p.X} },
+	func(p rules.Point, c int) rules.Point { return rules.Point{X: c - p.X, Y: c - p.Y} },
+	func(p rules.Point, c int) rules.Point { return rules.Point{X: p.Y, Y: c - p.X} },
+}
+
+var arenaMirrors = []arenaSymmetry{
+	func(p rules.Point, c int) rules.Point { return rules.Point{X: c - p.X, Y: p.Y} },
+	func(p rules.Point, c int) rules.Point { return rules.Point{X: p.X, Y: c - p.Y} },
+	func(p rules.Point, c int) rules.Point { return rules.Point{X: p.Y, Y: p.X} },
+	func(p rules.Point, c int) rules.Point { return rules.Point{X: c - p.Y, Y: c - p.X} },
+}
+
+// arenaSymmetries returns the smallest group of symmetries with at least one start position per player.
+func arenaSymmetries(players int) []arenaSymmetry {
+	switch {
+	case players <= 2:
+		return []arenaSymmetry{arenaRotations[0], arenaRotations[2]}
+	case players <= 4:
+		return arenaRotations
+	default:
+		return append(append([]arenaSymmetry{}, arenaRotations...), arenaMirrors...)
+	}
+}
+
+// symmetricArena is a generated arena. There is a start position and a food for each symmetry,
+// in the same order, and players are the indexes of the start positions used by the snakes.
+type symmetricArena struct {
+	size       int
+	symmetries []arenaSymmetry
+	starts     []rules.Point
+	food       []rules.Point
+	walls      []rules.Point
+	players    []int
+	// fairness is the ratio between the smallest and largest territory of the players.
+	fairness float64
+}
+
+func (arena *symmetricArena) center() rules.Point {
+	return rules.Point{X: arena.size / 2, Y: arena.size / 2}
+}
+
+// orbit returns the symmetric points of p, in the order of the arena's symmetries.
+func (arena *symmetricArena) orbit(p rules.Point) []rules.Point {
+	points := make([]rules.Point, 0, len(arena.symmetries))
+	for _, symmetry := range arena.symmetries {
+		points = append(points, symmetry(p, arena.size-1))
+	}
+	return points
+}
+
+func generateSymmetricArena(rand rules.Rand, size, players, density int, style string) (*symmetricArena, error) {
+	var best *symmetricArena
+	for attempt := 0; attempt < 10; attempt++ {
+		arena := &symmetricArena{
+			size:       size,
+			symmetries: arenaSymmetries(players),
+		}
+		if !arena.pickStarts(rand) {
+			continue
+		}
+		arena.growWalls(rand, density*size*size/100, style)
+		arena.choosePlayers(players)
+
+		if best == nil || arena.fairness > best.fairness {
+			best = arena
+		}
+		if best.fairness >= minArenaFairness {
+			break
+		}
+	}
+	if best == nil || best.fairness == 0 {
+		return nil, rules.RulesetError("unable to generate a fair arena for this board size and number of players")
+	}
+	return best, nil
+}
+
+// pickStarts picks start positions that are spread out and away from the center, with food next to each one towards the center.
+func (arena *symmetricArena) pickStarts(rand rules.Rand) bool {
+	c := arena.size - 1
+	center := arena.center()
+	for try := 0; try < 1000; try++ {
+		p := rules.Point{X: rand.Range(1, c-1), Y: rand.Range(1, c-1)}
+		if maxInt(abs(p.X-center.X), abs(p.Y-center.Y)) < arena.size/4 {
+			continue
+		}
+
+		starts := arena.orbit(p)
+		spread := true
+		for i := range starts {
+			for j := i + 1; j < len(starts); j++ {
+				spread = spread && manhattanDistance(starts[i], starts[j]) >= 4
+			}
+		}
+		if !spread {
+			continue
+		}
+
+		arena.starts = starts
+		arena.food = arena.orbit(rules.Point{X: p.X + sign(center.X-p.X), Y: p.Y + sign(center.Y-p.Y)})
+		return true
+	}
+	return false
+}
+
+// growWalls adds symmetric hazard structures until there are at least target hazards, skipping any that
+// would block a start position, food, or the center, or split the board.
+func (arena *symmetricArena) growWalls(rand rules.Rand, target int, style string) {
+	reserved := map[rules.Point]bool{arena.center(): true}
+	for _, start := range arena.starts {
+		for _, p := range []rules.Point{start, {X: start.X - 1, Y: start.Y}, {X: start.X + 1, Y: start.Y}, {X: start.X, Y: start.Y - 1}, {X: start.X, Y: start.Y + 1}} {
+			reserved[p] = true
+		}
+	}
+	for _, food := range arena.food {
+		reserved[food] = true
+	}
+
+	blocked := map[rules.Point]bool{}
+	directions := []rules.Point{{X: 1, Y: 0}, {X: -1, Y: 0}, {X: 0, Y: 1}, {X: 0, Y: -1}}
+	for try := 0; try < 20*arena.size*arena.size && len(arena.walls) < target; try++ {
+		shape := []rules.Point{{X: rand.Intn(arena.size), Y: rand.Intn(arena.size)}}
+		if style == ArenaStyleWalls {
+			direction := directions[rand.Intn(len(directions))]
+			for i := rand.Range(1, 3); i > 0; i-- {
+				last := shape[len(shape)-1]
+				shape = append(shape, rules.Point{X: last.X + direction.X, Y: last.Y + direction.Y})
+			}
+		}
+
+		var added []rules.Point
+		valid := true
+		for _, p := range shape {
+			if !isOnBoard(arena.size, arena.size, p.X, p.Y) {
+				valid = false
+				break
+			}
+			for _, q := range arena.orbit(p) {
+				if reserved[q] {
+					valid = false
+				}
+				if !blocked[q] && valid {
+					blocked[q] = true
+					added = append(added, q)
+				}
+			}
+		}
+		if valid && arena.connected(blocked) {
+			arena.walls = append(arena.walls, added...)
+			continue
+		}
+		for _, q := range added {
+			delete(blocked, q)
+		}
+	}
+}
+
+// connected reports whether every square that isn't blocked can be reached from the first start position.
+func (arena *symmetricArena) connected(blocked map[rules.Point]bool) bool {
+	distances := arena.distances(arena.starts[0], blocked)
+	return len(distances)+len(blocked) == arena.size*arena.size
+}
+
+// choosePlayers picks the start positions for the given number of players with the fairest territories.
+func (arena *symmetricArena) choosePlayers(players int) {
+	blocked := make(map[rules.Point]bool, len(arena.walls))
+	for _, p := range arena.walls {
+		blocked[p] = true
+	}
+	distances := make([]map[rules.Point]int, len(arena.starts))
+	for i, start := range arena.starts {
+		distances[i] = arena.distances(start, blocked)
+	}
+
+	arena.players, arena.fairness = nil, -1
+	for _, used := range combinations(len(arena.starts), players) {
+		territories := arenaTerritories(distances, used)
+		smallest, largest := territories[0], territories[0]
+		for _, territory := range territories {
+			if territory < smallest {
+				smallest = territory
+			}
+			largest = maxInt(largest, territory)
+		}
+		fairness := float64(smallest) / float64(largest)
+		if fairness > arena.fairness {
+			arena.players, arena.fairness = used, fairness
+		}
+	}
+}
+
+// arenaTerritories counts the squares closer to each used start position than to any other used start position,
+// going around walls, given the distances from every start position.
+func arenaTerritories(distances []map[rules.Point]int, used []int) []int {
+	territories := make([]int, len(used))
+	for p := range distances[used[0]] {
+		closest, tied := 0, false
+		for i := 1; i < len(used); i++ {
+			if distances[used[i]][p] < distances[used[closest]][p] {
+				closest, tied = i, false
+			} else if distances[used[i]][p] == distances[used[closest]][p] {
+				tied = true
+			}
+		}
+		if !tied {
+			territories[closest]++
+		}
+	}
+	return territories
+}
+
+// combinations returns every way to pick k of the numbers 0 to n-1, in increasing order.
+func combinations(n, k int) [][]int {
+	if k == 0 {
+		return [][]int{{}}
+	}
+	var result [][]int
+	for last := k - 1; last < n; last++ {
+		for _, combination := range combinations(last, k-1) {
+			result = append(result, append(combination, last))
+		}
+	}
+	return result
+}
+
+// distances returns the number of moves from start to every reachable square that isn't blocked.
+func (arena *symmetricArena) distances(start rules.Point, blocked map[rules.Point]bool) map[rules.Point]int {
+	distances := map[rules.Point]int{start: 0}
+	queue := []rules.Point{start}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		for _, next := range []rules.Point{{X: p.X - 1, Y: p.Y}, {X: p.X + 1, Y: p.Y}, {X: p.X, Y: p.Y - 1}, {X: p.X, Y: p.Y + 1}} {
+			if _, seen := distances[next]; seen || blocked[next] || !isOnBoard(arena.size, arena.size, next.X, next.Y) {
+				continue
+			}
+			distances[next] = distances[p] + 1
+			queue = append(queue, next)
+		}
+	}
+	return distances
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
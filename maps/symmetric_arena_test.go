@@ -0,0 +1,111 @@
+package maps
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Pikle2/rules"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSymmetricArenaMap(t *testing.T) {
+	for _, size := range []int{rules.BoardSizeMedium, rules.BoardSizeLarge} {
+		for players := 1; players <= 8; players++ {
+			for seed := int64(1); seed <= 3; seed++ {
+				t.Run(fmt.Sprintf("%dx%d %d players seed %d", size, size, players, seed), func(t *testing.T) {
+					snakeIDs := make([]string, players)
+					for i := range snakeIDs {
+						snakeIDs[i] = fmt.Sprint(i)
+					}
+					settings := rules.NewSettingsWithParams(ParamArenaStyle, []string{ArenaStyleWalls, ArenaStylePillars}[seed%2]).WithSeed(seed)
+					boardState, err := SetupBoard("hz_symmetric_arena", settings, size, size, snakeIDs)
+					require.NoError(t, err)
+
+					// The hazards look the same after every symmetry
+					hazards := map[rules.Point]bool{}
+					for _, p := range boardState.Hazards {
+						hazards[p] = true
+					}
+					require.NotEmpty(t, hazards)
+					for _, symmetry := range arenaSymmetries(players) {
+						for p := range hazards {
+							require.True(t, hazards[symmetry(p, size-1)])
+						}
+					}
+
+					// Snakes start on different squares that aren't hazards, with a food each plus the center food
+					heads := map[rules.Point]bool{}
+					for _, snake := range boardState.Snakes {
+						require.False(t, hazards[snake.Body[0]])
+						heads[snake.Body[0]] = true
+					}
+					require.Len(t, heads, players)
+					require.Len(t, boardState.Food, players+1)
+					for _, food := range boardState.Food {
+						require.False(t, hazards[food])
+					}
+
+					// Generating the arena again gives the same board
+					again, err := SetupBoard("hz_symmetric_arena", settings, size, size, snakeIDs)
+					require.NoError(t, err)
+					require.Equal(t, boardState, again)
+				})
+			}
+		}
+	}
+}
+
+func TestSymmetricArenaFairness(t *testing.T) {
+	arena, err := generateSymmetricArena(rules.NewSeedRand(7), 11, 4, 30, ArenaStyleWalls)
+	require.NoError(t, err)
+	require.Len(t, arena.starts, 4)
+
+	blocked := map[rules.Point]bool{}
+	for _, p := range arena.walls {
+		blocked[p] = true
+	}
+	require.True(t, arena.connected(blocked))
+
+	// Every start position is used, and symmetry makes the territories the same size
+	require.Equal(t, []int{0, 1, 2, 3}, arena.players)
+	require.Equal(t, 1.0, arena.fairness)
+
+	// With 3 players, one start position is left empty
+	arena, err = generateSymmetricArena(rules.NewSeedRand(7), 11, 3, 30, ArenaStyleWalls)
+	require.NoError(t, err)
+	require.Len(t, arena.starts, 4)
+	require.Len(t, arena.players, 3)
+	require.GreaterOrEqual(t, arena.fairness, minArenaFairness)
+
+	// Walls that split the board are not connected
+	split := map[rules.Point]bool{}
+	for y := 0; y < 11; y++ {
+		split[rules.Point{X: 5, Y: y}] = true
+	}
+	require.False(t, arena.connected(split))
+}
+
+func TestSymmetricArenaSettings(t *testing.T) {
+	snakeIDs := []string{"1", "2"}
+
+	boardState, err := SetupBoard("hz_symmetric_arena", rules.NewSettingsWithParams(ParamArenaDensity, "0").WithSeed(1), 11, 11, snakeIDs)
+	require.NoError(t, err)
+	require.Empty(t, boardState.Hazards)
+
+	dense, err := SetupBoard("hz_symmetric_arena", rules.NewSettingsWithParams(ParamArenaDensity, "40").WithSeed(1), 11, 11, snakeIDs)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, len(dense.Hazards), 11*11*40/100)
+
+	_, err = SetupBoard("hz_symmetric_arena", rules.NewSettingsWithParams(ParamArenaDensity, "41"), 11, 11, snakeIDs)
+	require.EqualError(t, err, "arena density must be between 0 and 40")
+	_, err = SetupBoard("hz_symmetric_arena", rules.NewSettingsWithParams(ParamArenaStyle, "caves"), 11, 11, snakeIDs)
+	require.EqualError(t, err, `unknown arena style "caves", expected walls or pillars`)
+	_, err = SetupBoard("hz_symmetric_arena", rules.NewSettingsWithParams(), 9, 9, snakeIDs)
+	require.Error(t, err)
+}
+
+func TestCombinations(t *testing.T) {
+	require.Equal(t, [][]int{{}}, combinations(3, 0))
+	require.Equal(t, [][]int{{0, 1}, {0, 2}, {1, 2}, {0, 3}, {1, 3}, {2, 3}}, combinations(4, 2))
+	require.Len(t, combinations(8, 5), 56)
+}
@@ -0,0 +1,495 @@
+package rules
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ExtendOutward extends the starting body of a snake toward the closest edge of the board, so that the snake faces the center.
+// See BodyShape.
+const ExtendOutward = "outward"
+
+// placementAttempts is the number of randomized plans tried for each symmetry by PlanSnakePlacement.
+const placementAttempts = 8
+
+// BodyShape describes how the body of a snake is laid out at the start of a game.
+type BodyShape struct {
+	// Length is the number of body segments, including the head. SnakeStartSize if not set.
+	Length int
+	// Extend is the direction the body extends in from the head, such as MoveDown or ExtendOutward.
+	// If empty, the whole body is stacked on the head.
+	Extend string
+}
+
+// Body returns the body of a snake with this shape, head first, on a board of the given size.
+func (shape BodyShape) Body(head Point, width, height int) ([]Point, error) {
+	length := shape.Length
+	if length < 1 {
+		length = SnakeStartSize
+	}
+	dx, dy, err := shape.direction(head, width, height)
+	if err != nil {
+		return nil, err
+	}
+	body := make([]Point, length)
+	for i := range body {
+		body[i] = Point{X: head.X + i*dx, Y: head.Y + i*dy}
+	}
+	return body, nil
+}
+
+func (shape BodyShape) direction(head Point, width, height int) (int, int, error) {
+	switch shape.Extend {
+	case "":
+		return 0, 0, nil
+	case MoveUp:
+		return 0, 1, nil
+	case MoveDown:
+		return 0, -1, nil
+	case MoveLeft:
+		return -1, 0, nil
+	case MoveRight:
+		return 1, 0, nil
+	case ExtendOutward:
+		left, right, down, up := head.X, width-1-head.X, head.Y, height-1-head.Y
+		horizontal, dx := left, -1
+		if right < left {
+			horizontal, dx = right, 1
+		}
+		vertical, dy := down, -1
+		if up < down {
+			vertical, dy = up, 1
+		}
+		if horizontal <= vertical {
+			return dx, 0, nil
+		}
+		return 0, dy, nil
+	}
+	return 0, 0, RulesetError(fmt.Sprintf("unknown body extension '%s'", shape.Extend))
+}
+
+// SnakePlacementOptions describes the start positions to plan with PlanSnakePlacement.
+type SnakePlacementOptions struct {
+	Width  int
+	Height int
+	// Snakes is the number of snakes to place.
+	Snakes int
+	// Obstacles are squares that snakes can't start on, such as walls.
+	Obstacles []Point
+	// Shape is the starting body of each snake.
+	Shape BodyShape
+}
+
+// SnakePlacement is a set of start positions planned by PlanSnakePlacement.
+type SnakePlacement struct {
+	// Bodies are the starting bodies of the snakes, head first.
+	Bodies [][]Point
+	// Symmetric reports whether the board looks the same to every snake: the heads and obstacles are unchanged
+	// by rotating the board half a turn or mirroring it.
+	Symmetric bool
+	// Spread is the smallest number of moves between two heads, ignoring obstacles.
+	Spread int
+	// Fairness compares the number of squares each snake can reach before any other snake, from 0 to 1.
+	// It is the size of the smallest territory divided by the size of the largest, so 1 is perfectly fair.
+	Fairness float64
+}
+
+// PlanSnakePlacement plans start positions for snakes on a board of any size, spread as far apart as possible.
+// Positions are symmetric where the board and number of snakes allow it, and the fairest plan found is returned.
+// Heads are kept off the center square, which is left for food, and off the edges of boards that are large enough.
+func PlanSnakePlacement(rand Rand, options SnakePlacementOptions) (*SnakePlacement, error) {
+	if options.Snakes < 1 {
+		return &SnakePlacement{Bodies: [][]Point{}, Symmetric: true, Fairness: 1}, nil
+	}
+	planner, err := newPlacementPlanner(options)
+	if err != nil {
+		return nil, err
+	}
+
+	var best *SnakePlacement
+	for _, symmetry := range planner.symmetries() {
+		units := planner.units(symmetry)
+		for attempt := 0; attempt < placementAttempts; attempt++ {
+			placement, ok := planner.plan(rand, units, attempt)
+			if ok && placement.betterThan(best) {
+				best = placement
+			}
+		}
+	}
+	if best == nil {
+		return nil, ErrorNoRoomForSnake
+	}
+
+	rand.Shuffle(len(best.Bodies), func(i, j int) {
+		best.Bodies[i], best.Bodies[j] = best.Bodies[j], best.Bodies[i]
+	})
+	return best, nil
+}
+
+// PlaceSnakesSpread places snakes at start positions planned by PlanSnakePlacement, treating hazards as obstacles.
+// Unlike PlaceSnakesFixed and PlaceManySnakesDistributed it works for any board size and number of snakes.
+func PlaceSnakesSpread(rand Rand, b *BoardState, snakeIDs []string, shape BodyShape) error {
+	placement, err := PlanSnakePlacement(rand, SnakePlacementOptions{
+		Width:     b.Width,
+		Height:    b.Height,
+		Snakes:    len(snakeIDs),
+		Obstacles: b.Hazards,
+		Shape:     shape,
+	})
+	if err != nil {
+		return err
+	}
+	return placement.Place(b, snakeIDs)
+}
+
+// Place puts snakes on the board at the planned start positions, in the order of snakeIDs.
+func (placement *SnakePlacement) Place(b *BoardState, snakeIDs []string) error {
+	if len(snakeIDs) > len(placement.Bodies) {
+		return ErrorTooManySnakes
+	}
+	b.Snakes = make([]Snake, len(snakeIDs))
+	for i, id := range snakeIDs {
+		b.Snakes[i] = Snake{
+			ID:     id,
+			Health: SnakeMaxHealth,
+			Body:   append([]Point{}, placement.Bodies[i]...),
+		}
+	}
+	return nil
+}
+
+func (placement *SnakePlacement) betterThan(other *SnakePlacement) bool {
+	if other == nil {
+		return true
+	}
+	// Ignore tiny differences in fairness, which are usually a square or two of territory
+	fairness, otherFairness := int(placement.Fairness*100), int(other.Fairness*100)
+	if fairness != otherFairness {
+		return fairness > otherFairness
+	}
+	if placement.Symmetric != other.Symmetric {
+		return placement.Symmetric
+	}
+	return placement.Spread > other.Spread
+}
+
+// boardSymmetry maps a square to its symmetric square on a board.
+type boardSymmetry func(p Point) Point
+
+// placementUnit is a group of heads that are placed together so that they stay symmetric.
+type placementUnit struct {
+	heads  []Point
+	bodies [][]Point
+}
+
+type placementPlanner struct {
+	options   SnakePlacementOptions
+	obstacles map[Point]bool
+	// candidates are the squares where a head can be placed, with the body of the snake.
+	candidates map[Point][]Point
+	rotate     boardSymmetry
+	mirrorX    boardSymmetry
+	mirrorY    boardSymmetry
+}
+
+func newPlacementPlanner(options SnakePlacementOptions) (*placementPlanner, error) {
+	w, h := options.Width, options.Height
+	planner := &placementPlanner{
+		options:    options,
+		obstacles:  make(map[Point]bool, len(options.Obstacles)),
+		candidates: map[Point][]Point{},
+		rotate:     func(p Point) Point { return Point{X: w - 1 - p.X, Y: h - 1 - p.Y} },
+		mirrorX:    func(p Point) Point { return Point{X: w - 1 - p.X, Y: p.Y} },
+		mirrorY:    func(p Point) Point { return Point{X: p.X, Y: h - 1 - p.Y} },
+	}
+	for _, p := range options.Obstacles {
+		planner.obstacles[p] = true
+	}
+
+	marginX, marginY := 0, 0
+	if w >= 5 {
+		marginX = 1
+	}
+	if h >= 5 {
+		marginY = 1
+	}
+	center := Point{X: (w - 1) / 2, Y: (h - 1) / 2}
+	for x := marginX; x < w-marginX; x++ {
+		for y := marginY; y < h-marginY; y++ {
+			head := Point{X: x, Y: y}
+			if (w%2 == 1 && h%2 == 1 && head == center) || (x+y)%2 != 0 {
+				continue
+			}
+			body, err := options.Shape.Body(head, w, h)
+			if err != nil {
+				return nil, err
+			}
+			if planner.fits(body) {
+				planner.candidates[head] = body
+			}
+		}
+	}
+	return planner, nil
+}
+
+// fits reports whether a body is on the board and not on an obstacle.
+func (planner *placementPlanner) fits(body []Point) bool {
+	for _, p := range body {
+		if p.X < 0 || p.Y < 0 || p.X >= planner.options.Width || p.Y >= planner.options.Height || planner.obstacles[p] {
+			return false
+		}
+	}
+	return true
+}
+
+// symmetries returns the groups of symmetries to try to keep the heads symmetric with, and no symmetry at all.
+func (planner *placementPlanner) symmetries() [][]boardSymmetry {
+	return [][]boardSymmetry{
+		{planner.rotate, planner.mirrorX, planner.mirrorY},
+		{planner.rotate},
+		{planner.mirrorX},
+		{planner.mirrorY},
+		nil,
+	}
+}
+
+// units groups the candidate squares into symmetric groups of heads.
+func (planner *placementPlanner) units(symmetry []boardSymmetry) []placementUnit {
+	heads := make([]Point, 0, len(planner.candidates))
+	for p := range planner.candidates {
+		heads = append(heads, p)
+	}
+	sort.Slice(heads, func(i, j int) bool { return pointLess(heads[i], heads[j]) })
+
+	seen := map[Point]bool{}
+	var units []placementUnit
+	for _, head := range heads {
+		if seen[head] {
+			continue
+		}
+		orbit := []Point{head}
+		valid := true
+		for i := 0; i < len(orbit); i++ {
+			for _, s := range symmetry {
+				p := s(orbit[i])
+				if containsPoint(orbit, p) {
+					continue
+				}
+				if _, ok := planner.candidates[p]; !ok {
+					valid = false
+				}
+				orbit = append(orbit, p)
+			}
+		}
+		for _, p := range orbit {
+			seen[p] = true
+		}
+		if !valid {
+			continue
+		}
+		unit := placementUnit{heads: orbit}
+		occupied := map[Point]bool{}
+		for _, p := range orbit {
+			body := planner.candidates[p]
+			if overlaps(placementUnit{bodies: [][]Point{body}}, occupied) {
+				valid = false
+			}
+			for _, b := range body {
+				occupied[b] = true
+			}
+			unit.bodies = append(unit.bodies, body)
+		}
+		if valid {
+			units = append(units, unit)
+		}
+	}
+	return units
+}
+
+// plan greedily picks units that are as far as possible from the heads already placed.
+// The first unit is random on every attempt but the first, so that different attempts explore different plans.
+func (planner *placementPlanner) plan(rand Rand, units []placementUnit, attempt int) (*SnakePlacement, bool) {
+	var heads []Point
+	var bodies [][]Point
+	occupied := map[Point]bool{}
+	used := make([]bool, len(units))
+
+	for len(heads) < planner.options.Snakes {
+		remaining := planner.options.Snakes - len(heads)
+		var best []int
+		bestScore := -1
+		for i, unit := range units {
+			if used[i] || len(unit.heads) > remaining || overlaps(unit, occupied) {
+				continue
+			}
+			score := spread(heads, unit.heads)
+			if attempt > 0 && len(heads) == 0 {
+				score = 0
+			}
+			if score > bestScore {
+				best, bestScore = []int{i}, score
+			} else if score == bestScore {
+				best = append(best, i)
+			}
+		}
+		if len(best) == 0 {
+			return nil, false
+		}
+		i := best[rand.Intn(len(best))]
+		used[i] = true
+		heads = append(heads, units[i].heads...)
+		bodies = append(bodies, units[i].bodies...)
+		for _, body := range units[i].bodies {
+			for _, p := range body {
+				occupied[p] = true
+			}
+		}
+	}
+
+	placement := &SnakePlacement{
+		Bodies:    bodies,
+		Spread:    spread(nil, heads),
+		Symmetric: planner.isSymmetric(heads),
+	}
+	placement.Fairness = planner.fairness(bodies)
+	return placement, true
+}
+
+func overlaps(unit placementUnit, occupied map[Point]bool) bool {
+	for _, body := range unit.bodies {
+		for _, p := range body {
+			if occupied[p] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// spread returns the smallest distance between two of the new heads, or a new head and an existing head.
+// It returns a large number when there is only one head.
+func spread(existing, heads []Point) int {
+	smallest := 1 << 30
+	for i, a := range heads {
+		for _, b := range existing {
+			if d := getDistanceBetweenPoints(a, b); d < smallest {
+				smallest = d
+			}
+		}
+		for _, b := range heads[i+1:] {
+			if d := getDistanceBetweenPoints(a, b); d < smallest {
+				smallest = d
+			}
+		}
+	}
+	return smallest
+}
+
+// isSymmetric reports whether the heads and obstacles are unchanged by any of the symmetries of the board.
+func (planner *placementPlanner) isSymmetric(heads []Point) bool {
+	headSet := make(map[Point]bool, len(heads))
+	for _, p := range heads {
+		headSet[p] = true
+	}
+	for _, s := range []boardSymmetry{planner.rotate, planner.mirrorX, planner.mirrorY} {
+		if mapsOnto(headSet, s) && mapsOnto(planner.obstacles, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func mapsOnto(set map[Point]bool, s boardSymmetry) bool {
+	for p := range set {
+		if !set[s(p)] {
+			return false
+		}
+	}
+	return true
+}
+
+// fairness compares the number of squares that each snake can reach before any other snake.
+func (planner *placementPlanner) fairness(bodies [][]Point) float64 {
+	if len(bodies) < 2 {
+		return 1
+	}
+	blocked := make(map[Point]bool, len(planner.obstacles))
+	for p := range planner.obstacles {
+		blocked[p] = true
+	}
+	for _, body := range bodies {
+		for _, p := range body {
+			blocked[p] = true
+		}
+	}
+
+	distances := make([]map[Point]int, len(bodies))
+	for i, body := range bodies {
+		distances[i] = planner.distances(body[0], blocked)
+	}
+	territories := make([]int, len(bodies))
+	for x := 0; x < planner.options.Width; x++ {
+		for y := 0; y < planner.options.Height; y++ {
+			p := Point{X: x, Y: y}
+			closest, closestDistance, tied := -1, 0, false
+			for i := range bodies {
+				d, ok := distances[i][p]
+				if !ok {
+					continue
+				}
+				if closest == -1 || d < closestDistance {
+					closest, closestDistance, tied = i, d, false
+				} else if d == closestDistance {
+					tied = true
+				}
+			}
+			if closest >= 0 && !tied {
+				territories[closest]++
+			}
+		}
+	}
+
+	smallest, largest := territories[0], territories[0]
+	for _, t := range territories[1:] {
+		if t < smallest {
+			smallest = t
+		}
+		if t > largest {
+			largest = t
+		}
+	}
+	if largest == 0 {
+		return 1
+	}
+	return float64(smallest) / float64(largest)
+}
+
+// distances returns the number of moves from a head to every square it can reach without crossing blocked squares.
+func (planner *placementPlanner) distances(head Point, blocked map[Point]bool) map[Point]int {
+	distances := map[Point]int{head: 0}
+	queue := []Point{head}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		for _, next := range []Point{{X: p.X + 1, Y: p.Y}, {X: p.X - 1, Y: p.Y}, {X: p.X, Y: p.Y + 1}, {X: p.X, Y: p.Y - 1}} {
+			if next.X < 0 || next.Y < 0 || next.X >= planner.options.Width || next.Y >= planner.options.Height {
+				continue
+			}
+			if _, seen := distances[next]; seen || blocked[next] {
+				continue
+			}
+			distances[next] = distances[p] + 1
+			queue = append(queue, next)
+		}
+	}
+	return distances
+}
+
+func containsPoint(points []Point, p Point) bool {
+	for _, q := range points {
+		if q == p {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,135 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBodyShape(t *testing.T) {
+	head := Point{X: 2, Y: 5}
+	tests := []struct {
+		Shape    BodyShape
+		Expected []Point
+	}{
+		{BodyShape{}, []Point{{X: 2, Y: 5}, {X: 2, Y: 5}, {X: 2, Y: 5}}},
+		{BodyShape{Length: 1}, []Point{{X: 2, Y: 5}}},
+		{BodyShape{Length: 3, Extend: MoveUp}, []Point{{X: 2, Y: 5}, {X: 2, Y: 6}, {X: 2, Y: 7}}},
+		{BodyShape{Length: 2, Extend: MoveDown}, []Point{{X: 2, Y: 5}, {X: 2, Y: 4}}},
+		{BodyShape{Length: 2, Extend: MoveLeft}, []Point{{X: 2, Y: 5}, {X: 1, Y: 5}}},
+		{BodyShape{Length: 2, Extend: MoveRight}, []Point{{X: 2, Y: 5}, {X: 3, Y: 5}}},
+		// The left edge is the closest
+		{BodyShape{Length: 3, Extend: ExtendOutward}, []Point{{X: 2, Y: 5}, {X: 1, Y: 5}, {X: 0, Y: 5}}},
+	}
+
+	for _, test := range tests {
+		body, err := test.Shape.Body(head, 11, 11)
+		require.NoError(t, err)
+		require.Equal(t, test.Expected, body)
+	}
+
+	// The top edge is the closest
+	body, err := BodyShape{Length: 2, Extend: ExtendOutward}.Body(Point{X: 5, Y: 8}, 11, 11)
+	require.NoError(t, err)
+	require.Equal(t, []Point{{X: 5, Y: 8}, {X: 5, Y: 9}}, body)
+
+	_, err = BodyShape{Extend: "sideways"}.Body(head, 11, 11)
+	require.EqualError(t, err, "unknown body extension 'sideways'")
+}
+
+func TestPlanSnakePlacementRectangular(t *testing.T) {
+	for snakes := 1; snakes <= 16; snakes++ {
+		placement, err := PlanSnakePlacement(MaxRand, SnakePlacementOptions{Width: 19, Height: 21, Snakes: snakes})
+		require.NoError(t, err)
+		require.Len(t, placement.Bodies, snakes)
+		require.True(t, placement.Fairness > 0)
+
+		heads := map[Point]bool{}
+		for _, body := range placement.Bodies {
+			require.Len(t, body, SnakeStartSize)
+			head := body[0]
+			require.False(t, heads[head], "snakes must not share a starting square")
+			heads[head] = true
+			require.True(t, head.X > 0 && head.X < 18 && head.Y > 0 && head.Y < 20, "snakes should start away from the edges")
+			require.NotEqual(t, Point{X: 9, Y: 10}, head, "the center is left for food")
+		}
+	}
+
+	// Small numbers of snakes get symmetric and perfectly fair starts
+	for _, snakes := range []int{2, 4} {
+		placement, err := PlanSnakePlacement(MinRand, SnakePlacementOptions{Width: 19, Height: 21, Snakes: snakes})
+		require.NoError(t, err)
+		require.True(t, placement.Symmetric)
+		require.Equal(t, 1.0, placement.Fairness)
+		require.GreaterOrEqual(t, placement.Spread, 16)
+	}
+}
+
+func TestPlanSnakePlacementObstacles(t *testing.T) {
+	// A wall down the middle of the board, with one gap
+	var walls []Point
+	for y := 0; y < 9; y++ {
+		if y != 4 {
+			walls = append(walls, Point{X: 6, Y: y})
+		}
+	}
+	placement, err := PlanSnakePlacement(MinRand, SnakePlacementOptions{
+		Width:     13,
+		Height:    9,
+		Snakes:    2,
+		Obstacles: walls,
+		Shape:     BodyShape{Extend: ExtendOutward},
+	})
+	require.NoError(t, err)
+	require.True(t, placement.Symmetric)
+	require.Equal(t, 1.0, placement.Fairness)
+	for _, body := range placement.Bodies {
+		require.Len(t, body, SnakeStartSize)
+		for _, p := range body {
+			require.NotContains(t, walls, p)
+			require.True(t, p.X >= 0 && p.X < 13 && p.Y >= 0 && p.Y < 9)
+		}
+	}
+	// One snake on each side of the wall
+	require.NotEqual(t, placement.Bodies[0][0].X < 6, placement.Bodies[1][0].X < 6)
+}
+
+func TestPlanSnakePlacementNoRoom(t *testing.T) {
+	_, err := PlanSnakePlacement(MinRand, SnakePlacementOptions{Width: 3, Height: 3, Snakes: 6})
+	require.Equal(t, ErrorNoRoomForSnake, err)
+
+	_, err = PlanSnakePlacement(MinRand, SnakePlacementOptions{Width: 11, Height: 11, Snakes: 2, Shape: BodyShape{Extend: "sideways"}})
+	require.EqualError(t, err, "unknown body extension 'sideways'")
+
+	placement, err := PlanSnakePlacement(MinRand, SnakePlacementOptions{Width: 11, Height: 11, Snakes: 4})
+	require.NoError(t, err)
+	require.Equal(t, ErrorTooManySnakes, placement.Place(NewBoardState(11, 11), []string{"1", "2", "3", "4", "5"}))
+}
+
+func TestPlaceSnakesSpread(t *testing.T) {
+	boardState := NewBoardState(19, 21).WithHazards([]Point{{X: 9, Y: 10}, {X: 2, Y: 2}})
+	snakeIDs := []string{"1", "2", "3", "4", "5", "6", "7", "8"}
+	require.NoError(t, PlaceSnakesSpread(MaxRand, boardState, snakeIDs, BodyShape{Extend: ExtendOutward}))
+	require.Len(t, boardState.Snakes, len(snakeIDs))
+
+	heads := map[Point]bool{}
+	for i, snake := range boardState.Snakes {
+		require.Equal(t, snakeIDs[i], snake.ID)
+		require.Equal(t, SnakeMaxHealth, snake.Health)
+		require.Len(t, snake.Body, SnakeStartSize)
+		require.NotContains(t, boardState.Hazards, snake.Body[0])
+		require.NotEqual(t, snake.Body[0], snake.Body[1], "body should extend from the head")
+		heads[snake.Body[0]] = true
+	}
+	require.Len(t, heads, len(snakeIDs))
+}
+
+func TestPlaceSnakesAutomaticallyRectangularIsUnchanged(t *testing.T) {
+	// rectangular boards still get random placement, so existing maps keep their start positions
+	random := NewBoardState(19, 21)
+	automatic := NewBoardState(19, 21)
+	snakeIDs := []string{"1", "2", "3", "4"}
+	require.NoError(t, PlaceSnakesRandomly(MaxRand, random, snakeIDs))
+	require.NoError(t, PlaceSnakesAutomatically(MaxRand, automatic, snakeIDs))
+	require.Equal(t, random.Snakes, automatic.Snakes)
+}
@@ -2,6 +2,7 @@ package rules
 
 import (
 	"errors"
+	"fmt"
 )
 
 var royaleRulesetStages = []string{
@@ -14,6 +15,22 @@ var royaleRulesetStages = []string{
 	StageSpawnHazardsShrinkMap,
 }
 
+// Royale shrink patterns, chosen with ParamShrinkPattern.
+const (
+	ShrinkPatternRandomSide   = "randomSide"   // one random side moves in each time
+	ShrinkPatternConcentric   = "concentric"   // every side moves in each time
+	ShrinkPatternSpiral       = "spiral"       // the sides move in one after another, clockwise from a random side
+	ShrinkPatternShiftingZone = "shiftingZone" // the side furthest from a random safe zone moves in, and the zone moves every few shrinks
+	ShrinkPatternFinalCells   = "finalCells"   // a random side along the longest edge moves in, until the next shrink would leave fewer than ParamShrinkFinalCells squares
+)
+
+const (
+	defaultShrinkFinalCells = 9
+
+	// shiftingZoneShrinks is the number of shrinks before the shifting zone moves to a new random square in the safe area.
+	shiftingZoneShrinks = 4
+)
+
 func PopulateHazardsRoyale(b *BoardState, settings Settings, moves []SnakeMove) (bool, error) {
 	if IsInitialization(b, settings, moves) {
 		return false, nil
@@ -21,42 +38,161 @@ func PopulateHazardsRoyale(b *BoardState, settings Settings, moves []SnakeMove)
 	b.Hazards = []Point{}
 
 	// Royale uses the current turn to generate hazards, not the previous turn that's in the board state
-	turn := b.Turn + 1
+	hazards, _, err := RoyaleHazards(b.Width, b.Height, b.Turn+1, settings)
+	if err != nil {
+		return false, err
+	}
+	b.Hazards = append(b.Hazards, hazards...)
 
+	return false, nil
+}
+
+// RoyaleHazards returns the hazards for a turn of a royale game, which cover every square outside a safe area.
+// The safe area shrinks every shrinkEveryNTurns turns, following the pattern chosen with the shrinkPattern setting.
+// Shrinking reports whether the safe area has shrunk yet. Hazards are generated from scratch every turn,
+// using the random generator for turn zero, so the same settings always give the same hazards.
+func RoyaleHazards(width, height, turn int, settings Settings) (hazards []Point, shrinking bool, err error) {
 	shrinkEveryNTurns := settings.Int(ParamShrinkEveryNTurns, 20)
 	if shrinkEveryNTurns < 1 {
-		return false, errors.New("royale game can't shrink more frequently than every turn")
+		return nil, false, errors.New("royale game can't shrink more frequently than every turn")
 	}
 
-	if turn < shrinkEveryNTurns {
-		return false, nil
+	pattern := settings.Params()[ParamShrinkPattern]
+	finalCells := settings.Int(ParamShrinkFinalCells, defaultShrinkFinalCells)
+	switch pattern {
+	case "":
+		pattern = ShrinkPatternRandomSide
+	case ShrinkPatternRandomSide, ShrinkPatternConcentric, ShrinkPatternSpiral, ShrinkPatternShiftingZone:
+	case ShrinkPatternFinalCells:
+		if finalCells < 1 {
+			return nil, false, RulesetError(fmt.Sprintf("%s must be at least 1", ParamShrinkFinalCells))
+		}
+	default:
+		return nil, false, RulesetError(fmt.Sprintf("unknown shrink pattern '%s', expected one of: %s, %s, %s, %s, %s", pattern,
+			ShrinkPatternRandomSide, ShrinkPatternConcentric, ShrinkPatternSpiral, ShrinkPatternShiftingZone, ShrinkPatternFinalCells))
 	}
 
-	randGenerator := settings.GetRand(0)
+	if turn < shrinkEveryNTurns {
+		return nil, false, nil
+	}
 
+	rand := settings.GetRand(0)
 	numShrinks := turn / shrinkEveryNTurns
-	minX, maxX := 0, b.Width-1
-	minY, maxY := 0, b.Height-1
-	for i := 0; i < numShrinks; i++ {
-		switch randGenerator.Intn(4) {
-		case 0:
-			minX += 1
-		case 1:
-			maxX -= 1
-		case 2:
-			minY += 1
-		case 3:
-			maxY -= 1
-		}
-	}
-
-	for x := 0; x < b.Width; x++ {
-		for y := 0; y < b.Height; y++ {
-			if x < minX || x > maxX || y < minY || y > maxY {
-				b.Hazards = append(b.Hazards, Point{X: x, Y: y})
+	area := royaleArea{minX: 0, maxX: width - 1, minY: 0, maxY: height - 1}
+	switch pattern {
+	case ShrinkPatternRandomSide:
+		for i := 0; i < numShrinks; i++ {
+			area = area.shrinkSide(rand.Intn(4))
+		}
+	case ShrinkPatternConcentric:
+		for i := 0; i < numShrinks && !area.empty(); i++ {
+			for side := 0; side < 4; side++ {
+				area = area.shrinkSide(side)
+			}
+		}
+	case ShrinkPatternSpiral:
+		clockwise := []int{sideLeft, sideTop, sideRight, sideBottom}
+		start := rand.Intn(4)
+		for i := 0; i < numShrinks && !area.empty(); i++ {
+			area = area.shrinkSide(clockwise[(start+i)%4])
+		}
+	case ShrinkPatternShiftingZone:
+		var zone Point
+		for i := 0; i < numShrinks && !area.empty(); i++ {
+			if i%shiftingZoneShrinks == 0 {
+				zone = Point{X: area.minX + rand.Intn(area.maxX-area.minX+1), Y: area.minY + rand.Intn(area.maxY-area.minY+1)}
 			}
+			area = area.shrinkSide(area.furthestSide(zone))
+		}
+	case ShrinkPatternFinalCells:
+		for i := 0; i < numShrinks; i++ {
+			sides := []int{sideLeft, sideRight, sideBottom, sideTop}
+			if area.width() > area.height() {
+				sides = sides[:2]
+			} else if area.height() > area.width() {
+				sides = sides[2:]
+			}
+			next := area.shrinkSide(sides[rand.Intn(len(sides))])
+			if next.size() < finalCells {
+				break
+			}
+			area = next
 		}
 	}
 
-	return false, nil
+	hazards = []Point{}
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			if !area.contains(x, y) {
+				hazards = append(hazards, Point{X: x, Y: y})
+			}
+		}
+	}
+	return hazards, true, nil
+}
+
+// Sides of the royale safe area, in the order that ShrinkPatternRandomSide picks them.
+const (
+	sideLeft = iota
+	sideRight
+	sideBottom
+	sideTop
+)
+
+// royaleArea is the safe area of a royale game, from minX to maxX and minY to maxY inclusive.
+type royaleArea struct {
+	minX, maxX, minY, maxY int
+}
+
+func (area royaleArea) width() int {
+	if area.maxX < area.minX {
+		return 0
+	}
+	return area.maxX - area.minX + 1
+}
+
+func (area royaleArea) height() int {
+	if area.maxY < area.minY {
+		return 0
+	}
+	return area.maxY - area.minY + 1
+}
+
+func (area royaleArea) size() int {
+	return area.width() * area.height()
+}
+
+func (area royaleArea) empty() bool {
+	return area.size() == 0
+}
+
+func (area royaleArea) contains(x, y int) bool {
+	return x >= area.minX && x <= area.maxX && y >= area.minY && y <= area.maxY
+}
+
+// shrinkSide moves one side of the area in by one square.
+func (area royaleArea) shrinkSide(side int) royaleArea {
+	switch side {
+	case sideLeft:
+		area.minX += 1
+	case sideRight:
+		area.maxX -= 1
+	case sideBottom:
+		area.minY += 1
+	case sideTop:
+		area.maxY -= 1
+	}
+	return area
+}
+
+// furthestSide returns the side of the area that is furthest from a point, preferring the earliest side on ties.
+func (area royaleArea) furthestSide(p Point) int {
+	distances := []int{p.X - area.minX, area.maxX - p.X, p.Y - area.minY, area.maxY - p.Y}
+	furthest := 0
+	for side, d := range distances {
+		if d > distances[furthest] {
+			furthest = side
+		}
+	}
+	return furthest
 }
@@ -214,3 +214,109 @@ func TestRoyaleCreateNextBoardState(t *testing.T) {
 		gc.requireValidNextState(t, rb.PipelineRuleset(GameTypeRoyale, NewPipeline(royaleRulesetStages...)))
 	}
 }
+
+func TestRoyaleHazardsPatterns(t *testing.T) {
+	// safeArea returns the squares that aren't hazards, as rows from the top of the board
+	safeArea := func(hazards []Point, width, height int) []string {
+		isHazard := map[Point]bool{}
+		for _, p := range hazards {
+			isHazard[p] = true
+		}
+		rows := []string{}
+		for y := height - 1; y >= 0; y-- {
+			row := ""
+			for x := 0; x < width; x++ {
+				if isHazard[Point{X: x, Y: y}] {
+					row += "H"
+				} else {
+					row += "."
+				}
+			}
+			rows = append(rows, row)
+		}
+		return rows
+	}
+	royaleSettings := func(pattern string) Settings {
+		return NewSettingsWithParams(ParamShrinkEveryNTurns, "5", ParamShrinkPattern, pattern).WithSeed(1)
+	}
+
+	// Before the first shrink there are no hazards
+	hazards, shrinking, err := RoyaleHazards(7, 7, 4, royaleSettings(ShrinkPatternConcentric))
+	require.NoError(t, err)
+	require.False(t, shrinking)
+	require.Empty(t, hazards)
+
+	hazards, shrinking, err = RoyaleHazards(7, 7, 10, royaleSettings(ShrinkPatternConcentric))
+	require.NoError(t, err)
+	require.True(t, shrinking)
+	require.Equal(t, []string{
+		"HHHHHHH",
+		"HHHHHHH",
+		"HH...HH",
+		"HH...HH",
+		"HH...HH",
+		"HHHHHHH",
+		"HHHHHHH",
+	}, safeArea(hazards, 7, 7))
+
+	// Spiral shrinks each side in turn, so after 4 shrinks every side has moved in once
+	hazards, _, err = RoyaleHazards(7, 7, 20, royaleSettings(ShrinkPatternSpiral))
+	require.NoError(t, err)
+	require.Equal(t, 7*7-5*5, len(hazards))
+	hazards, _, err = RoyaleHazards(7, 7, 25, royaleSettings(ShrinkPatternSpiral))
+	require.NoError(t, err)
+	require.Equal(t, 7*7-5*4, len(hazards))
+
+	// Final cells stops shrinking before the safe area gets too small
+	settings := royaleSettings(ShrinkPatternFinalCells).WithParam(ParamShrinkFinalCells, "6")
+	for _, turn := range []int{50, 500} {
+		hazards, _, err = RoyaleHazards(7, 7, turn, settings)
+		require.NoError(t, err)
+		require.Equal(t, 7*7-6, len(hazards))
+	}
+
+	// Every pattern is deterministic, and eventually covers the whole board apart from final cells
+	for _, pattern := range []string{ShrinkPatternRandomSide, ShrinkPatternConcentric, ShrinkPatternSpiral, ShrinkPatternShiftingZone, ShrinkPatternFinalCells} {
+		first, _, err := RoyaleHazards(11, 11, 40, royaleSettings(pattern))
+		require.NoError(t, err)
+		second, _, err := RoyaleHazards(11, 11, 40, royaleSettings(pattern))
+		require.NoError(t, err)
+		require.Equal(t, first, second, pattern)
+
+		hazards, _, err = RoyaleHazards(11, 11, 1000, royaleSettings(pattern))
+		require.NoError(t, err)
+		if pattern == ShrinkPatternFinalCells {
+			require.Equal(t, 11*11-9, len(hazards))
+		} else {
+			require.Equal(t, 11*11, len(hazards), pattern)
+		}
+	}
+
+	_, _, err = RoyaleHazards(7, 7, 1, royaleSettings("diagonal"))
+	require.EqualError(t, err, "unknown shrink pattern 'diagonal', expected one of: randomSide, concentric, spiral, shiftingZone, finalCells")
+	_, _, err = RoyaleHazards(7, 7, 1, royaleSettings(ShrinkPatternFinalCells).WithParam(ParamShrinkFinalCells, "0"))
+	require.EqualError(t, err, "shrinkFinalCells must be at least 1")
+}
+
+func TestRoyaleHazardsShiftingZone(t *testing.T) {
+	settings := NewSettingsWithParams(ParamShrinkEveryNTurns, "1", ParamShrinkPattern, ShrinkPatternShiftingZone)
+
+	// Each shrink covers more of the board, and never uncovers squares
+	for seed := int64(1); seed <= 5; seed++ {
+		var previous []Point
+		for turn := 1; turn <= 16; turn++ {
+			hazards, _, err := RoyaleHazards(9, 9, turn, settings.WithSeed(seed))
+			require.NoError(t, err)
+			require.Greater(t, len(hazards), len(previous))
+			isHazard := map[Point]bool{}
+			for _, p := range previous {
+				isHazard[p] = true
+			}
+			for _, p := range hazards {
+				delete(isHazard, p)
+			}
+			require.Empty(t, isHazard, "hazards are never removed")
+			previous = hazards
+		}
+	}
+}
@@ -66,6 +66,27 @@ func (settings Settings) WithSeed(seed int64) Settings {
 	return settings
 }
 
+// WithParam returns a copy of the settings with a parameter set to a new value.
+// The original settings are not modified.
+func (settings Settings) WithParam(paramName string, value string) Settings {
+	rawValues := make(map[string]string, len(settings.rawValues)+1)
+	for key, value := range settings.rawValues {
+		rawValues[key] = value
+	}
+	rawValues[paramName] = value
+	settings.rawValues = rawValues
+	return settings
+}
+
+// Params returns a copy of the raw parameter values, e.g. to pass them to another process.
+func (settings Settings) Params() map[string]string {
+	params := make(map[string]string, len(settings.rawValues))
+	for key, value := range settings.rawValues {
+		params[key] = value
+	}
+	return params
+}
+
 // Bool returns the boolean value for the specified parameter.
 // If the parameter doesn't exist, the default value will be returned.
 // If the parameter does exist, but is not "true", false will be returned.
@@ -28,4 +28,12 @@ func TestSettings(t *testing.T) {
 	assert.Equal(t, 4567, rules.NewSettingsWithParams("newIntSetting").Int("newIntSetting", 4567))
 	assert.Equal(t, 1234, rules.NewSettingsWithParams("newIntSetting", "1234").Int("newIntSetting", 4567))
 	assert.Equal(t, 4567, rules.NewSettingsWithParams("x", "y", "newIntSetting").Int("newIntSetting", 4567))
+
+	assert.Equal(t, params, settings.Params())
+	settings.Params()["intSetting"] = "1"
+	assert.Equal(t, 1234, settings.Int("intSetting", 4567))
+
+	withParam := settings.WithParam("intSetting", "42")
+	assert.Equal(t, 42, withParam.Int("intSetting", 4567))
+	assert.Equal(t, 1234, settings.Int("intSetting", 4567))
 }
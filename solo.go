@@ -11,7 +11,7 @@ var soloRulesetStages = []string{
 
 func GameOverSolo(b *BoardState, settings Settings, moves []SnakeMove) (bool, error) {
 	for i := 0; i < len(b.Snakes); i++ {
-		if b.Snakes[i].EliminatedCause == NotEliminated {
+		if b.Snakes[i].EliminatedCause == NotEliminated && countsForWin(b, b.Snakes[i]) {
 			return false, nil
 		}
 	}
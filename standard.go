@@ -160,7 +160,12 @@ func DamageHazardsStandard(b *BoardState, settings Settings, moves []SnakeMove)
 					snake.Health = SnakeMaxHealth
 				}
 				if snakeIsOutOfHealth(snake) {
-					EliminateSnake(snake, EliminatedByHazard, "", b.Turn+1)
+					// Snakes aren't credited with eliminating themselves in their own hazards
+					owner := GetHazardOwner(b, p)
+					if owner == snake.ID {
+						owner = ""
+					}
+					EliminateSnake(snake, EliminatedByHazard, owner, b.Turn+1)
 				}
 			}
 		}
@@ -384,7 +389,7 @@ func SpawnFoodStandard(b *BoardState, settings Settings, moves []SnakeMove) (boo
 func GameOverStandard(b *BoardState, settings Settings, moves []SnakeMove) (bool, error) {
 	numSnakesRemaining := 0
 	for i := 0; i < len(b.Snakes); i++ {
-		if b.Snakes[i].EliminatedCause == NotEliminated {
+		if b.Snakes[i].EliminatedCause == NotEliminated && countsForWin(b, b.Snakes[i]) {
 			numSnakesRemaining++
 		}
 	}
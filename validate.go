@@ -0,0 +1,114 @@
+package rules
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BoardStateViolation describes a single inconsistency found in a BoardState.
+type BoardStateViolation struct {
+	// Field identifies the part of the BoardState that is inconsistent, e.g. "Food[2]" or "Snakes[1].Body".
+	Field string
+	// Message is a human readable description of the problem.
+	Message string
+}
+
+func (v BoardStateViolation) String() string {
+	return fmt.Sprintf("%s: %s", v.Field, v.Message)
+}
+
+// BoardStateValidationError is returned by Validate and lists every violation that was found.
+type BoardStateValidationError []BoardStateViolation
+
+func (err BoardStateValidationError) Error() string {
+	messages := make([]string, 0, len(err))
+	for _, v := range err {
+		messages = append(messages, v.String())
+	}
+	return fmt.Sprintf("invalid board state (%d violations): %s", len(err), strings.Join(messages, "; "))
+}
+
+// Validate checks that the BoardState is internally consistent.
+// It returns nil if the state is valid, or a BoardStateValidationError listing every violation found.
+//
+// The following is checked:
+//   - the board dimensions and turn are not negative
+//   - all food is on the board
+//   - snake IDs are non-empty and unique
+//   - snake health is between 0 and SnakeMaxHealth
+//   - non-eliminated snakes have a body that is entirely on the board
+//   - eliminated snakes record the turn they were eliminated on, and it is not in the future
+//   - non-eliminated snakes don't have any elimination details set
+func (state *BoardState) Validate() error {
+	var violations BoardStateValidationError
+	addViolation := func(field, format string, args ...interface{}) {
+		violations = append(violations, BoardStateViolation{
+			Field:   field,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	if state.Width < 0 || state.Height < 0 {
+		addViolation("Width/Height", "board dimensions %dx%d are negative", state.Width, state.Height)
+	}
+	if state.Turn < 0 {
+		addViolation("Turn", "turn %d is negative", state.Turn)
+	}
+
+	for i, p := range state.Food {
+		if !state.isOnBoard(p) {
+			addViolation(fmt.Sprintf("Food[%d]", i), "food at %#v is out of bounds for a %dx%d board", p, state.Width, state.Height)
+		}
+	}
+
+	seenIDs := make(map[string]int, len(state.Snakes))
+	for i, snake := range state.Snakes {
+		field := fmt.Sprintf("Snakes[%d]", i)
+
+		if snake.ID == "" {
+			addViolation(field+".ID", "snake ID is empty")
+		} else if first, ok := seenIDs[snake.ID]; ok {
+			addViolation(field+".ID", "snake ID %q is already used by Snakes[%d]", snake.ID, first)
+		} else {
+			seenIDs[snake.ID] = i
+		}
+
+		if snake.Health < 0 || snake.Health > SnakeMaxHealth {
+			addViolation(field+".Health", "health %d is outside the range 0-%d", snake.Health, SnakeMaxHealth)
+		}
+
+		if snake.EliminatedCause == NotEliminated {
+			if len(snake.Body) == 0 {
+				addViolation(field+".Body", "snake %q is not eliminated but has an empty body", snake.ID)
+			}
+			for j, p := range snake.Body {
+				if !state.isOnBoard(p) {
+					addViolation(fmt.Sprintf("%s.Body[%d]", field, j), "snake %q is not eliminated but has a body part at %#v out of bounds", snake.ID, p)
+				}
+			}
+			if snake.EliminatedOnTurn != 0 {
+				addViolation(field+".EliminatedOnTurn", "snake %q is not eliminated but has EliminatedOnTurn set to %d", snake.ID, snake.EliminatedOnTurn)
+			}
+			if snake.EliminatedBy != "" {
+				addViolation(field+".EliminatedBy", "snake %q is not eliminated but has EliminatedBy set to %q", snake.ID, snake.EliminatedBy)
+			}
+			continue
+		}
+
+		// Eliminations are recorded against the turn being processed, which is one ahead of the board's turn.
+		if snake.EliminatedOnTurn <= 0 {
+			addViolation(field+".EliminatedOnTurn", "snake %q was eliminated (%s) but EliminatedOnTurn is %d", snake.ID, snake.EliminatedCause, snake.EliminatedOnTurn)
+		} else if snake.EliminatedOnTurn > state.Turn+1 {
+			addViolation(field+".EliminatedOnTurn", "snake %q was eliminated on turn %d, which is after the current turn %d", snake.ID, snake.EliminatedOnTurn, state.Turn)
+		}
+	}
+
+	if len(violations) > 0 {
+		return violations
+	}
+	return nil
+}
+
+func (state *BoardState) isOnBoard(p Point) bool {
+	return p.X >= 0 && p.X < state.Width && p.Y >= 0 && p.Y < state.Height
+}
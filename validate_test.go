@@ -0,0 +1,116 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBoardStateValidateValid(t *testing.T) {
+	require.NoError(t, NewBoardState(0, 0).Validate())
+	require.NoError(t, NewBoardState(11, 11).Validate())
+
+	state, err := CreateDefaultBoardState(MaxRand, BoardSizeMedium, BoardSizeMedium, []string{"1", "2", "3", "4"})
+	require.NoError(t, err)
+	require.NoError(t, state.Validate())
+
+	eliminated := NewBoardState(11, 11).
+		WithTurn(4).
+		WithSnakes([]Snake{
+			{ID: "1", Body: []Point{{X: 1, Y: 1}}, Health: 100},
+			// Eliminated snakes may have moved off the board
+			{ID: "2", Body: []Point{{X: -1, Y: 1}}, Health: 50, EliminatedCause: EliminatedByOutOfBounds, EliminatedOnTurn: 5},
+			{ID: "3", Body: []Point{}, Health: 0, EliminatedCause: EliminatedByCollision, EliminatedOnTurn: 2, EliminatedBy: "1"},
+		})
+	require.NoError(t, eliminated.Validate())
+}
+
+func TestBoardStateValidateViolations(t *testing.T) {
+	tests := []struct {
+		name   string
+		state  *BoardState
+		fields []string
+	}{
+		{
+			"negative dimensions and turn",
+			NewBoardState(-1, 5).WithTurn(-2),
+			[]string{"Width/Height", "Turn"},
+		},
+		{
+			"food out of bounds",
+			NewBoardState(5, 5).WithFood([]Point{{X: 1, Y: 1}, {X: 5, Y: 0}, {X: 0, Y: -1}}),
+			[]string{"Food[1]", "Food[2]"},
+		},
+		{
+			"empty and duplicate snake IDs",
+			NewBoardState(5, 5).WithSnakes([]Snake{
+				{ID: "a", Body: []Point{{X: 1, Y: 1}}, Health: 100},
+				{ID: "", Body: []Point{{X: 2, Y: 2}}, Health: 100},
+				{ID: "a", Body: []Point{{X: 3, Y: 3}}, Health: 100},
+			}),
+			[]string{"Snakes[1].ID", "Snakes[2].ID"},
+		},
+		{
+			"alive snake with empty body",
+			NewBoardState(5, 5).WithSnakes([]Snake{
+				{ID: "a", Body: []Point{}, Health: 100},
+			}),
+			[]string{"Snakes[0].Body"},
+		},
+		{
+			"alive snake out of bounds",
+			NewBoardState(5, 5).WithSnakes([]Snake{
+				{ID: "a", Body: []Point{{X: 4, Y: 4}, {X: 4, Y: 5}}, Health: 100},
+			}),
+			[]string{"Snakes[0].Body[1]"},
+		},
+		{
+			"health out of range",
+			NewBoardState(5, 5).WithSnakes([]Snake{
+				{ID: "a", Body: []Point{{X: 1, Y: 1}}, Health: 101},
+				{ID: "b", Body: []Point{{X: 2, Y: 2}}, Health: -1},
+			}),
+			[]string{"Snakes[0].Health", "Snakes[1].Health"},
+		},
+		{
+			"eliminated snake missing turn",
+			NewBoardState(5, 5).WithTurn(3).WithSnakes([]Snake{
+				{ID: "a", Body: []Point{{X: 1, Y: 1}}, Health: 0, EliminatedCause: EliminatedByOutOfHealth},
+				{ID: "b", Body: []Point{{X: 2, Y: 2}}, Health: 0, EliminatedCause: EliminatedByOutOfHealth, EliminatedOnTurn: 9},
+			}),
+			[]string{"Snakes[0].EliminatedOnTurn", "Snakes[1].EliminatedOnTurn"},
+		},
+		{
+			"alive snake with elimination details",
+			NewBoardState(5, 5).WithSnakes([]Snake{
+				{ID: "a", Body: []Point{{X: 1, Y: 1}}, Health: 100, EliminatedOnTurn: 2, EliminatedBy: "b"},
+			}),
+			[]string{"Snakes[0].EliminatedOnTurn", "Snakes[0].EliminatedBy"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.state.Validate()
+			require.Error(t, err)
+
+			var validationErr BoardStateValidationError
+			require.ErrorAs(t, err, &validationErr)
+
+			var fields []string
+			for _, v := range validationErr {
+				fields = append(fields, v.Field)
+				require.NotEmpty(t, v.Message)
+			}
+			require.Equal(t, test.fields, fields)
+		})
+	}
+}
+
+func TestBoardStateValidationError(t *testing.T) {
+	err := BoardStateValidationError{
+		{Field: "Food[0]", Message: "out of bounds"},
+		{Field: "Turn", Message: "negative"},
+	}
+	require.Equal(t, "invalid board state (2 violations): Food[0]: out of bounds; Turn: negative", err.Error())
+}